@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	mockUrlFlag = &cli.StringFlag{
+		Name:    "url",
+		Aliases: []string{"u"},
+		Usage:   "The URL of the running mock's admin routes. Use unix:///path/to/socket for a mock serving them on a Unix domain socket.",
+		Value:   "http://127.0.0.1:49537",
+		EnvVars: []string{"NODESET_MOCK_URL"},
+	}
+	clientAdminTokenFlag = &cli.StringFlag{
+		Name:    "admin-token",
+		Usage:   "The bearer token to authenticate with, if the mock requires one",
+		EnvVars: []string{"NODESET_MOCK_ADMIN_TOKEN"},
+	}
+)
+
+// Flags for connecting to a running mock
+var clientFlags []cli.Flag = []cli.Flag{
+	mockUrlFlag,
+	clientAdminTokenFlag,
+}
+
+const (
+	// The URL scheme for mocks listening on a Unix domain socket
+	unixSocketScheme string = "unix://"
+)
+
+// Client for the admin routes of a running mock
+type adminClient struct {
+	baseUrl    string
+	token      string
+	httpClient *http.Client
+}
+
+// Creates an admin client from the connection flags
+func newAdminClient(c *cli.Context) *adminClient {
+	baseUrl, httpClient := getHttpClient(c.String(mockUrlFlag.Name))
+	return &adminClient{
+		baseUrl:    baseUrl,
+		token:      c.String(clientAdminTokenFlag.Name),
+		httpClient: httpClient,
+	}
+}
+
+// Runs a request to an admin route, deserializing the response data into data if it isn't nil
+func (c *adminClient) request(path string, queryParams map[string]string, data any) error {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/admin/%s", c.baseUrl, path), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	setQueryParams(request, queryParams)
+	if c.token != "" {
+		auth.AddAdminAuthorizationHeader(request, c.token)
+	}
+	return sendRequest(c.httpClient, request, data)
+}
+
+// An error response from the mock
+type requestError struct {
+	// The response's status code
+	StatusCode int
+
+	// The response's error key, if it had one
+	Key string
+
+	// The response's message, if it had one
+	Message string
+}
+
+// Describes the error response
+func (e *requestError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("request failed with status %d", e.StatusCode)
+}
+
+// Gets the base URL and HTTP client to use for a mock URL, dialing the socket for Unix socket URLs
+func getHttpClient(mockUrl string) (string, *http.Client) {
+	baseUrl := strings.TrimSuffix(mockUrl, "/")
+	socketPath, isUnixSocket := strings.CutPrefix(baseUrl, unixSocketScheme)
+	if !isUnixSocket {
+		return baseUrl, http.DefaultClient
+	}
+
+	// Unix socket URLs don't have a host
+	return "http://localhost", &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+}
+
+// Sets the query params of a request
+func setQueryParams(request *http.Request, queryParams map[string]string) {
+	query := url.Values{}
+	for name, value := range queryParams {
+		query.Set(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+}
+
+// Sends a request to the mock, deserializing the response data into data if it isn't nil. Responses that aren't a
+// 200 return a *requestError.
+func sendRequest(httpClient *http.Client, request *http.Request, data any) error {
+	// Send the request
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer response.Body.Close()
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("error reading the response body: %w", err)
+	}
+
+	// Parse the response
+	var parsedResponse api.NodeSetResponse[json.RawMessage]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	if response.StatusCode != http.StatusOK {
+		requestErr := &requestError{
+			StatusCode: response.StatusCode,
+		}
+		if err == nil {
+			requestErr.Key = parsedResponse.Error
+			requestErr.Message = parsedResponse.Message
+		}
+		return requestErr
+	}
+	if err != nil {
+		return fmt.Errorf("error deserializing response: %w", err)
+	}
+	if data != nil && len(parsedResponse.Data) > 0 {
+		err = json.Unmarshal(parsedResponse.Data, data)
+		if err != nil {
+			return fmt.Errorf("error deserializing response data: %w", err)
+		}
+	}
+	return nil
+}
+
+// Prints a value as indented JSON
+func printJson(value any) error {
+	bytes, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing output: %w", err)
+	}
+	fmt.Println(string(bytes))
+	return nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/urfave/cli/v2"
+)
+
+// Creates the admin command, which drives a running mock through its admin routes
+func createAdminCommand() *cli.Command {
+	emailFlag := &cli.StringFlag{
+		Name:     "email",
+		Aliases:  []string{"e"},
+		Usage:    "The email address of the user",
+		Required: true,
+	}
+	networkFlag := &cli.StringFlag{
+		Name:     "network",
+		Aliases:  []string{"n"},
+		Usage:    "The name of the network",
+		Required: true,
+	}
+	nameFlag := &cli.StringFlag{
+		Name:     "name",
+		Usage:    "The name of the snapshot",
+		Required: true,
+	}
+	nodeFlag := &cli.StringFlag{
+		Name:     "node",
+		Usage:    "The address of the node",
+		Required: true,
+	}
+	vaultAddressFlag := &cli.StringFlag{
+		Name:     "address",
+		Aliases:  []string{"a"},
+		Usage:    "The address of the vault",
+		Required: true,
+	}
+	vaultFlag := &cli.StringFlag{
+		Name:     "vault",
+		Usage:    "The address of the vault",
+		Required: true,
+	}
+	policyFlag := &cli.StringFlag{
+		Name:  "policy",
+		Usage: "The selection policy to build the set with (default, round-robin, fair-share, max-set-size, or per-node)",
+		Value: db.SetSelectionPolicy_Default,
+	}
+	userLimitFlag := &cli.UintFlag{
+		Name:  "user-limit",
+		Usage: "The max number of validators per user in the set (0 for no limit)",
+		Value: 1,
+	}
+	nodeLimitFlag := &cli.UintFlag{
+		Name:  "node-limit",
+		Usage: "The max number of validators per node in the set (0 for no limit)",
+	}
+	maxSetSizeFlag := &cli.UintFlag{
+		Name:  "max-set-size",
+		Usage: "The max number of validators in the set (0 for no limit)",
+	}
+
+	return &cli.Command{
+		Name:  "admin",
+		Usage: "Manage a running mock through its admin routes",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "add-user",
+				Usage: "Add a user",
+				Flags: append([]cli.Flag{emailFlag}, clientFlags...),
+				Action: func(c *cli.Context) error {
+					email := c.String(emailFlag.Name)
+					err := newAdminClient(c).request(api.AdminAddUserPath, map[string]string{
+						"email": email,
+					}, nil)
+					if err != nil {
+						return fmt.Errorf("error adding user: %w", err)
+					}
+					fmt.Printf("Added user %s\n", email)
+					return nil
+				},
+			},
+			{
+				Name:  "whitelist",
+				Usage: "Whitelist a node with a user",
+				Flags: append([]cli.Flag{emailFlag, nodeFlag}, clientFlags...),
+				Action: func(c *cli.Context) error {
+					email := c.String(emailFlag.Name)
+					nodeAddress, err := parseAddress(c.String(nodeFlag.Name))
+					if err != nil {
+						return err
+					}
+					err = newAdminClient(c).request(api.AdminWhitelistNodePath, map[string]string{
+						"email":   email,
+						"address": nodeAddress.Hex(),
+					}, nil)
+					if err != nil {
+						return fmt.Errorf("error whitelisting node: %w", err)
+					}
+					fmt.Printf("Whitelisted node %s with user %s\n", nodeAddress.Hex(), email)
+					return nil
+				},
+			},
+			{
+				Name:  "add-vault",
+				Usage: "Add a StakeWise vault",
+				Flags: append([]cli.Flag{networkFlag, vaultAddressFlag}, clientFlags...),
+				Action: func(c *cli.Context) error {
+					network := c.String(networkFlag.Name)
+					vaultAddress, err := parseAddress(c.String(vaultAddressFlag.Name))
+					if err != nil {
+						return err
+					}
+					err = newAdminClient(c).request(api.AdminAddVaultPath, map[string]string{
+						"network": network,
+						"address": vaultAddress.Hex(),
+					}, nil)
+					if err != nil {
+						return fmt.Errorf("error adding vault: %w", err)
+					}
+					fmt.Printf("Added vault %s on network %s\n", vaultAddress.Hex(), network)
+					return nil
+				},
+			},
+			{
+				Name:  "cycle-set",
+				Usage: "Upload a new deposit data set to a StakeWise vault and print its version and pubkeys",
+				Flags: append([]cli.Flag{networkFlag, vaultFlag, policyFlag, userLimitFlag, nodeLimitFlag, maxSetSizeFlag}, clientFlags...),
+				Action: func(c *cli.Context) error {
+					vaultAddress, err := parseAddress(c.String(vaultFlag.Name))
+					if err != nil {
+						return err
+					}
+					var data api.CycleSetData
+					err = newAdminClient(c).request(api.AdminCycleSetPath, map[string]string{
+						"network":      c.String(networkFlag.Name),
+						"vault":        vaultAddress.Hex(),
+						"policy":       c.String(policyFlag.Name),
+						"user-limit":   strconv.FormatUint(uint64(c.Uint(userLimitFlag.Name)), 10),
+						"node-limit":   strconv.FormatUint(uint64(c.Uint(nodeLimitFlag.Name)), 10),
+						"max-set-size": strconv.FormatUint(uint64(c.Uint(maxSetSizeFlag.Name)), 10),
+					}, &data)
+					if err != nil {
+						return fmt.Errorf("error cycling set: %w", err)
+					}
+					return printJson(data)
+				},
+			},
+			{
+				Name:  "set-ready",
+				Usage: "Mark seeding as finished so the mock reports that it's ready",
+				Flags: clientFlags,
+				Action: func(c *cli.Context) error {
+					err := newAdminClient(c).request(api.AdminSetReadyPath, nil, nil)
+					if err != nil {
+						return fmt.Errorf("error marking the mock as ready: %w", err)
+					}
+					fmt.Println("Marked the mock as ready")
+					return nil
+				},
+			},
+			{
+				Name:  "snapshot",
+				Usage: "Take a snapshot of the mock's state",
+				Flags: append([]cli.Flag{nameFlag}, clientFlags...),
+				Action: func(c *cli.Context) error {
+					name := c.String(nameFlag.Name)
+					err := newAdminClient(c).request(api.AdminSnapshotPath, map[string]string{
+						"name": name,
+					}, nil)
+					if err != nil {
+						return fmt.Errorf("error taking snapshot: %w", err)
+					}
+					fmt.Printf("Took snapshot %s\n", name)
+					return nil
+				},
+			},
+			{
+				Name:  "revert",
+				Usage: "Revert the mock's state to a snapshot",
+				Flags: append([]cli.Flag{nameFlag}, clientFlags...),
+				Action: func(c *cli.Context) error {
+					name := c.String(nameFlag.Name)
+					err := newAdminClient(c).request(api.AdminRevertPath, map[string]string{
+						"name": name,
+					}, nil)
+					if err != nil {
+						return fmt.Errorf("error reverting to snapshot: %w", err)
+					}
+					fmt.Printf("Reverted to snapshot %s\n", name)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// Parses a hex address from a flag
+func parseAddress(addressString string) (common.Address, error) {
+	if !common.IsHexAddress(addressString) {
+		return common.Address{}, fmt.Errorf("invalid address [%s]", addressString)
+	}
+	return common.HexToAddress(addressString), nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/nodeset-org/nodeset-svc-mock/mocktest"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// The admin token the test mock requires
+	adminTestToken string = "admin-test-token"
+
+	// The email of the user the test adds
+	adminTestEmail string = "admin-cli@nodeset.io"
+)
+
+// Make sure the admin subcommands drive a running mock through its admin routes
+func TestAdminCommands(t *testing.T) {
+	mock := mocktest.Start(t)
+	mock.Server.SetAdminTokens("", adminTestToken)
+	mock.Server.SetReadinessStatus(api.ReadinessStatus_Seeding)
+	nodeAddress := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	vaultAddress := common.HexToAddress("0x0000000000000000000000000000000000000002")
+
+	// Take a snapshot and add a user with a whitelisted node
+	require.NoError(t, runAdminCommand(mock, adminTestToken, "snapshot", "--name", "cli"))
+	require.NoError(t, runAdminCommand(mock, adminTestToken, "add-user", "--email", adminTestEmail))
+	require.NoError(t, runAdminCommand(mock, adminTestToken, "whitelist", "--email", adminTestEmail, "--node", nodeAddress.Hex()))
+	user := getAdminTestUser(mock)
+	require.NotNil(t, user)
+	require.Len(t, user.WhitelistedNodes, 1)
+	require.Equal(t, nodeAddress, user.WhitelistedNodes[0].Address)
+	t.Log("Added user and whitelisted node")
+
+	// Errors from the mock should come back with their key
+	err := runAdminCommand(mock, adminTestToken, "add-user", "--email", adminTestEmail)
+	var requestErr *requestError
+	require.ErrorAs(t, err, &requestErr)
+	require.Equal(t, http.StatusBadRequest, requestErr.StatusCode)
+	require.Equal(t, "user_already_exists", requestErr.Key)
+	require.ErrorContains(t, runAdminCommand(mock, adminTestToken, "whitelist", "--email", adminTestEmail, "--node", "0x1234"), "invalid address")
+	t.Log("Errors were reported")
+
+	// Add a vault and cycle a set on the provisioned one
+	require.NoError(t, runAdminCommand(mock, adminTestToken, "add-vault", "--network", test.Network, "--address", vaultAddress.Hex()))
+	mock.Manager.Lock()
+	require.NotNil(t, mock.Manager.GetStakeWiseVault(vaultAddress, test.Network))
+	version := mock.Manager.GetStakeWiseVault(test.StakeWiseVaultAddress, test.Network).LatestDepositDataSetIndex
+	mock.Manager.Unlock()
+	require.NoError(t, runAdminCommand(mock, adminTestToken, "cycle-set", "--network", test.Network, "--vault", test.StakeWiseVaultAddressHex, "--policy", db.SetSelectionPolicy_Default))
+	mock.Manager.Lock()
+	require.Equal(t, version+1, mock.Manager.GetStakeWiseVault(test.StakeWiseVaultAddress, test.Network).LatestDepositDataSetIndex)
+	mock.Manager.Unlock()
+	t.Log("Added vault and cycled set")
+
+	// Mark seeding as finished
+	require.NoError(t, runAdminCommand(mock, adminTestToken, "set-ready"))
+	require.Equal(t, api.ReadinessStatus_Ready, mock.Server.GetReadinessStatus())
+	t.Log("Marked the mock as ready")
+
+	// Reverting should undo the changes
+	require.NoError(t, runAdminCommand(mock, adminTestToken, "revert", "--name", "cli"))
+	require.Nil(t, getAdminTestUser(mock))
+	mock.Manager.Lock()
+	require.Nil(t, mock.Manager.GetStakeWiseVault(vaultAddress, test.Network))
+	mock.Manager.Unlock()
+	t.Log("Reverted to snapshot")
+
+	// Requests without the token should be rejected
+	err = runAdminCommand(mock, "", "add-user", "--email", adminTestEmail)
+	require.ErrorAs(t, err, &requestErr)
+	require.Equal(t, http.StatusUnauthorized, requestErr.StatusCode)
+	t.Log("Request without the admin token was rejected")
+}
+
+// Runs an admin subcommand against the mock
+func runAdminCommand(mock *mocktest.Mock, token string, args ...string) error {
+	app := cli.NewApp()
+	app.Commands = []*cli.Command{
+		createAdminCommand(),
+	}
+	args = append([]string{"nodeset-svc-mock", "admin"}, args...)
+	args = append(args, "--url", mock.BaseUrl)
+	if token != "" {
+		args = append(args, "--admin-token", token)
+	}
+	return app.Run(args)
+}
+
+// Gets the user the test adds, or nil if it doesn't exist
+func getAdminTestUser(mock *mocktest.Mock) *db.User {
+	mock.Manager.Lock()
+	defer mock.Manager.Unlock()
+	for _, user := range mock.Manager.GetUsers() {
+		if user.Email == adminTestEmail {
+			return user
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"time"
+
+	"github.com/rocket-pool/node-manager-core/beacon"
+)
+
+// The type of a state change event
+type EventType string
+
+const (
+	// A user was added
+	EventType_UserAdded EventType = "user_added"
+
+	// A node was whitelisted with a user
+	EventType_NodeWhitelisted EventType = "node_whitelisted"
+
+	// A node was registered with a user
+	EventType_NodeRegistered EventType = "node_registered"
+
+	// A node logged a session in
+	EventType_SessionLogin EventType = "session_login"
+
+	// A node uploaded deposit data
+	EventType_DepositDataUploaded EventType = "deposit_data_uploaded"
+
+	// A node uploaded signed exits
+	EventType_ExitsUploaded EventType = "exits_uploaded"
+
+	// A new deposit data set was uploaded to a vault
+	EventType_SetCycled EventType = "set_cycled"
+
+	// A validator's StakeWise status changed
+	EventType_ValidatorStatusChanged EventType = "validator_status_changed"
+)
+
+// All of the event types, in the order they were added
+var EventTypes []EventType = []EventType{
+	EventType_UserAdded,
+	EventType_NodeWhitelisted,
+	EventType_NodeRegistered,
+	EventType_SessionLogin,
+	EventType_DepositDataUploaded,
+	EventType_ExitsUploaded,
+	EventType_SetCycled,
+	EventType_ValidatorStatusChanged,
+}
+
+// A state change in the mock. Data holds the payload for the event's type.
+type Event struct {
+	// A sequence number for the event, starting at 1
+	ID uint64 `json:"id"`
+
+	// The type of the event
+	Type EventType `json:"type"`
+
+	// When the event happened
+	Time time.Time `json:"time"`
+
+	// The payload, which depends on the type
+	Data any `json:"data"`
+}
+
+// Payload for EventType_UserAdded
+type UserAddedEventData struct {
+	Email string `json:"email"`
+}
+
+// Payload for EventType_NodeWhitelisted and EventType_NodeRegistered
+type NodeEventData struct {
+	Email       string `json:"email"`
+	NodeAddress string `json:"nodeAddress"`
+}
+
+// Payload for EventType_SessionLogin
+type SessionLoginEventData struct {
+	NodeAddress string `json:"nodeAddress"`
+}
+
+// Payload for EventType_DepositDataUploaded
+type DepositDataUploadedEventData struct {
+	NodeAddress string                   `json:"nodeAddress"`
+	Pubkeys     []beacon.ValidatorPubkey `json:"pubkeys"`
+}
+
+// Payload for EventType_ExitsUploaded
+type ExitsUploadedEventData struct {
+	NodeAddress string                   `json:"nodeAddress"`
+	Network     string                   `json:"network"`
+	Pubkeys     []beacon.ValidatorPubkey `json:"pubkeys"`
+}
+
+// Payload for EventType_SetCycled
+type SetCycledEventData struct {
+	Network string                   `json:"network"`
+	Vault   string                   `json:"vault"`
+	Version int                      `json:"version"`
+	Pubkeys []beacon.ValidatorPubkey `json:"pubkeys"`
+}
+
+// Payload for EventType_ValidatorStatusChanged
+type ValidatorStatusChangedEventData struct {
+	Network        string                 `json:"network"`
+	Pubkey         beacon.ValidatorPubkey `json:"pubkey"`
+	PreviousStatus StakeWiseStatus        `json:"previousStatus"`
+	Status         StakeWiseStatus        `json:"status"`
+}
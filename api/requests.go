@@ -31,3 +31,15 @@ type ExitData struct {
 	Pubkey      string      `json:"pubkey"`
 	ExitMessage ExitMessage `json:"exit_message"`
 }
+
+// Data for a pubkey's voluntary exit message, encrypted to the NodeSet encryption public key
+type EncryptedExitData struct {
+	Pubkey      string `json:"pubkey"`
+	ExitMessage string `json:"exitMessage"` // The JSON-serialized ExitMessage, encrypted with age and 0x-prefixed hex encoded
+}
+
+// Request for a Constellation minipool deposit signature
+type MinipoolDepositSignatureRequest struct {
+	MinipoolAddress string `json:"minipoolAddress"`
+	Salt            string `json:"salt"` // Must be 0x-prefixed hex encoded
+}
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/rocket-pool/node-manager-core/beacon"
 )
 
@@ -27,15 +30,31 @@ type NonceData struct {
 
 // Response to a deposit data meta request
 type DepositDataMetaData struct {
-	Version int `json:"version"`
+	Version    int         `json:"version"`
+	MerkleRoot common.Hash `json:"merkleRoot"`
+	Size       int         `json:"size"`
 }
 
 // Response to a deposit data request
 type DepositDataData struct {
 	Version     int                          `json:"version"`
+	MerkleRoot  common.Hash                  `json:"merkleRoot"`
+	Size        int                          `json:"size"`
 	DepositData []beacon.ExtendedDepositData `json:"depositData"`
 }
 
+// Info about a deposit data set that was uploaded to a StakeWise vault
+type DepositDataSetInfo struct {
+	Version     int                      `json:"version"`
+	CreatedTime time.Time                `json:"createdTime"`
+	Pubkeys     []beacon.ValidatorPubkey `json:"pubkeys"`
+}
+
+// Response to a deposit data versions request
+type DepositDataVersionsData struct {
+	Versions []DepositDataSetInfo `json:"versions"`
+}
+
 // Validator status info
 type ValidatorStatus struct {
 	Pubkey              beacon.ValidatorPubkey `json:"pubkey"`
@@ -48,6 +67,274 @@ type ValidatorsData struct {
 	Validators []ValidatorStatus `json:"validators"`
 }
 
+// Response to a Constellation whitelist request
+type WhitelistData struct {
+	Signature string `json:"signature"`
+}
+
+// Response to a Constellation minipool deposit signature request
+type MinipoolDepositSignatureData struct {
+	Signature string `json:"signature"`
+}
+
+// Response to an encryption identity request
+type EncryptionIdentityData struct {
+	PublicKey string `json:"publicKey"` // The age X25519 public key that signed exits must be encrypted to
+}
+
+// Response to an admin cycle-set request
+type CycleSetData struct {
+	Version int                      `json:"version"`
+	Pubkeys []beacon.ValidatorPubkey `json:"pubkeys"`
+}
+
+// Settings for automatically cycling a StakeWise vault's deposit data sets
+type CycleSchedule struct {
+	Network          string `json:"network,omitempty"`
+	Vault            string `json:"vault,omitempty"`
+	Interval         string `json:"interval"`
+	PendingThreshold int    `json:"pendingThreshold"`
+	Policy           string `json:"policy"`
+	UserLimit        int    `json:"userLimit"`
+	NodeLimit        int    `json:"nodeLimit"`
+	MaxSetSize       int    `json:"maxSetSize"`
+	RegisterDelay    string `json:"registerDelay"`
+}
+
+// Response to an admin cycle schedules request
+type CycleSchedulesData struct {
+	// The schedule used for vaults that don't have their own
+	Default *CycleSchedule `json:"default"`
+
+	// The schedules for specific vaults
+	Schedules []CycleSchedule `json:"schedules"`
+}
+
+// Response to the admin time requests
+type TimeData struct {
+	// The current time of the mock's clock
+	Time time.Time `json:"time"`
+
+	// True if the clock is frozen
+	Frozen bool `json:"frozen"`
+
+	// The current slot and epoch of the simulated Beacon Chain
+	Slot  uint64 `json:"slot"`
+	Epoch uint64 `json:"epoch"`
+}
+
+// A record of a state-changing operation in the audit log
+type AuditEntry struct {
+	// When the operation happened
+	Time time.Time `json:"time"`
+
+	// Who performed the operation (node, admin, or scheduler)
+	Actor string `json:"actor"`
+
+	// The address of the node that performed the operation, if there was one
+	NodeAddress string `json:"nodeAddress,omitempty"`
+
+	// The method and path of the route that was called, or a description of a scheduled operation
+	Route string `json:"route"`
+
+	// The HTTP status code of the response, if the operation came from a route
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Whether or not the operation succeeded
+	Success bool `json:"success"`
+
+	// The error message if the operation failed
+	Error string `json:"error,omitempty"`
+}
+
+// Response to an admin audit log request
+type AuditLogData struct {
+	// The matching entries, oldest first
+	Entries []AuditEntry `json:"entries"`
+}
+
+// A URL that events are POSTed to
+type Webhook struct {
+	ID  uint64 `json:"id"`
+	URL string `json:"url"`
+
+	// True if deliveries are signed
+	Signed bool `json:"signed"`
+
+	// The event types delivered, or empty for all of them
+	Types []EventType `json:"types"`
+
+	MaxAttempts    int    `json:"maxAttempts"`
+	InitialBackoff string `json:"initialBackoff"`
+	MaxBackoff     string `json:"maxBackoff"`
+}
+
+// The result of delivering an event to a webhook
+type WebhookDelivery struct {
+	// When the last attempt finished
+	Time time.Time `json:"time"`
+
+	WebhookID uint64    `json:"webhookId"`
+	URL       string    `json:"url"`
+	EventID   uint64    `json:"eventId"`
+	EventType EventType `json:"eventType"`
+
+	// The number of attempts made
+	Attempts int `json:"attempts"`
+
+	// The status code of the last attempt, or 0 if it didn't get a response
+	StatusCode int `json:"statusCode"`
+
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Response to an admin webhooks request
+type WebhooksData struct {
+	Webhooks []Webhook `json:"webhooks"`
+
+	// The matching deliveries, oldest first
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// Response to an admin state request
+type StateData struct {
+	// The current time of the mock's clock
+	Time time.Time `json:"time"`
+
+	Users     []StateUser  `json:"users"`
+	Vaults    []StateVault `json:"vaults"`
+	Snapshots []string     `json:"snapshots"`
+}
+
+// A user in a state dump
+type StateUser struct {
+	Email         string      `json:"email"`
+	MinipoolLimit int         `json:"minipoolLimit"`
+	CreatedTime   time.Time   `json:"createdTime"`
+	Nodes         []StateNode `json:"nodes"`
+}
+
+// A user's node in a state dump
+type StateNode struct {
+	Address        string           `json:"address"`
+	Registered     bool             `json:"registered"`
+	CreatedTime    time.Time        `json:"createdTime"`
+	RegisteredTime *time.Time       `json:"registeredTime,omitempty"`
+	Validators     []StateValidator `json:"validators"`
+}
+
+// A node's validator in a state dump
+type StateValidator struct {
+	Network         string                 `json:"network"`
+	Pubkey          beacon.ValidatorPubkey `json:"pubkey"`
+	Vault           string                 `json:"vault"`
+	Status          StakeWiseStatus        `json:"status"`
+	DepositDataUsed bool                   `json:"depositDataUsed"`
+	ExitUploaded    bool                   `json:"exitUploaded"`
+	BeaconIndex     *uint64                `json:"beaconIndex,omitempty"`
+}
+
+// A StakeWise vault in a state dump
+type StateVault struct {
+	Network string `json:"network"`
+	Address string `json:"address"`
+
+	// The version of the latest deposit data set
+	Version int `json:"version"`
+
+	// The pubkeys in the latest deposit data set
+	LatestSet []beacon.ValidatorPubkey `json:"latestSet"`
+
+	// The number of pubkeys that have been uploaded to the vault
+	UploadedCount int `json:"uploadedCount"`
+}
+
+// Response to a health request
+type HealthData struct {
+	Status string `json:"status"`
+}
+
+// How far the mock is through getting ready to serve requests
+type ReadinessStatus string
+
+const (
+	// The mock's state is being loaded from persistent storage
+	ReadinessStatus_Loading ReadinessStatus = "loading"
+
+	// The mock is waiting for an orchestrator to finish seeding it
+	ReadinessStatus_Seeding ReadinessStatus = "seeding"
+
+	// The mock is ready
+	ReadinessStatus_Ready ReadinessStatus = "ready"
+)
+
+// Response to a readiness request
+type ReadyData struct {
+	Ready  bool            `json:"ready"`
+	Status ReadinessStatus `json:"status"`
+}
+
+// Response to a version request
+type VersionData struct {
+	// The version of the mock
+	Version string `json:"version"`
+
+	// The commit the mock was built from
+	Commit string `json:"commit"`
+}
+
+// Response to an admin snapshots request
+type SnapshotsData struct {
+	Names []string `json:"names"`
+}
+
+// Response to the admin session policy requests
+type SessionPolicyData struct {
+	// How long a nonce can be used to log in after it's issued, e.g. "5m" ("0s" for no expiration)
+	NonceLifetime string `json:"nonceLifetime"`
+
+	// The max number of unused, unexpired nonces each client can have at once (0 for no limit)
+	MaxNoncesPerClient int `json:"maxNoncesPerClient"`
+
+	// Whether logging a node in revokes all of its other sessions
+	RevokeOldSessions bool `json:"revokeOldSessions"`
+}
+
+// Response to an admin rate limits request
+type RateLimitsData struct {
+	Limits []RateLimit `json:"limits"`
+}
+
+// A simulated rate limit on an API route
+type RateLimit struct {
+	// The name of the route, e.g. "deposit-data"
+	Route string `json:"route"`
+
+	// What requests are grouped by when counting against the limit
+	Key RateLimitKey `json:"key"`
+
+	// The number of requests per second each client can make once its burst has been used
+	Rate float64 `json:"rate"`
+
+	// The number of requests each client can make in a burst
+	Burst int `json:"burst"`
+}
+
+// Ways of grouping requests when applying rate limits
+type RateLimitKey string
+
+const (
+	// Requests are grouped by the address of the logged-in node
+	RateLimitKey_Node RateLimitKey = "node"
+
+	// Requests are grouped by session token
+	RateLimitKey_Session RateLimitKey = "session"
+
+	// Requests are grouped by the client's IP address
+	RateLimitKey_Ip RateLimitKey = "ip"
+)
+
 // Status types for StakeWise validators
 type StakeWiseStatus string
 
@@ -1,21 +1,65 @@
 package api
 
 const (
+	// API versions
+	V1 string = "v1"
+	V2 string = "v2"
+
+	// API v2 path layout
+	V2Path            string = "v2"
+	CorePath          string = "core"
+	ModulesPath       string = "modules"
+	StakeWisePath     string = "stakewise"
+	ConstellationPath string = "constellation"
+
 	// API routes
-	DevPath             string = "dev"
-	DepositDataMetaPath string = "deposit-data/meta"
-	DepositDataPath     string = "deposit-data"
-	ValidatorsPath      string = "validators"
-	NoncePath           string = "nonce"
-	LoginPath           string = "login"
-	RegisterPath        string = "node-address"
+	DevPath                 string = "dev"
+	DepositDataMetaPath     string = "deposit-data/meta"
+	DepositDataPath         string = "deposit-data"
+	DepositDataVersionsPath string = "deposit-data/versions"
+	ValidatorsPath          string = "validators"
+	NoncePath               string = "nonce"
+	LoginPath               string = "login"
+	RegisterPath            string = "node-address"
+
+	// Encryption routes
+	EncryptionIdentityPath string = "secret/encryption-identity"
+
+	// Constellation routes
+	WhitelistPath                string = "whitelist"
+	MinipoolDepositSignaturePath string = "minipool/deposit-signature"
+
+	// Service routes
+	HealthPath  string = "health"
+	ReadyPath   string = "ready"
+	VersionPath string = "version"
 
 	// Admin routes
-	AdminSnapshotPath      string = "snapshot"
-	AdminRevertPath        string = "revert"
-	AdminCycleSetPath      string = "cycle-set"
-	AdminAddUserPath       string = "add-user"
-	AdminWhitelistNodePath string = "whitelist-node"
-	AdminRegisterNodePath  string = "register-node"
-	AdminAddVaultPath      string = "add-vault"
+	AdminSnapshotPath         string = "snapshot"
+	AdminSnapshotsPath        string = "snapshots"
+	AdminRevertPath           string = "revert"
+	AdminCycleSetPath         string = "cycle-set"
+	AdminAddUserPath          string = "add-user"
+	AdminWhitelistNodePath    string = "whitelist-node"
+	AdminRegisterNodePath     string = "register-node"
+	AdminAddVaultPath         string = "add-vault"
+	AdminRateLimitPath        string = "rate-limit"
+	AdminRateLimitsPath       string = "rate-limits"
+	AdminApiVersionPath       string = "api-version"
+	AdminMinipoolLimitPath    string = "minipool-limit"
+	AdminCycleSchedulePath    string = "cycle-schedule"
+	AdminCycleSchedulesPath   string = "cycle-schedules"
+	AdminTimePath             string = "time"
+	AdminAuditLogPath         string = "audit-log"
+	AdminStatePath            string = "state"
+	AdminSetReadyPath         string = "set-ready"
+	AdminEventsPath           string = "events"
+	AdminWebhooksPath         string = "webhooks"
+	AdminAddWebhookPath       string = "add-webhook"
+	AdminRemoveWebhookPath    string = "remove-webhook"
+	AdminSessionPolicyPath    string = "session-policy"
+	AdminSetSessionPolicyPath string = "set-session-policy"
+	AdminFreezeTimePath       string = "freeze-time"
+	AdminSetTimePath          string = "set-time"
+	AdminAdvanceTimePath      string = "advance-time"
 )
@@ -2,8 +2,10 @@ package auth
 
 import (
 	"crypto/ecdsa"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"strings"
 
@@ -30,8 +32,17 @@ const (
 var (
 	ErrAuthHeader        error = errors.New("invalid auth header")
 	ErrMissingAuthHeader error = errors.New("missing auth header")
+	ErrInvalidSignature  error = errors.New("invalid signature")
+
+	ErrInvalidSignatureLength error = fmt.Errorf("signature must be %d bytes", crypto.SignatureLength)
+	ErrInvalidRecoveryId      error = errors.New("signature recovery ID must be 0, 1, 27, or 28")
+	ErrMalleableSignature     error = errors.New("signature's s value must be in the lower half of the curve order")
+	ErrInvalidSignatureValues error = errors.New("signature's r and s values are out of range")
 )
 
+// Half of the secp256k1 curve order; signatures with an s value above this are malleable
+var secp256k1HalfN *big.Int = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
 // Creates a signature for node registration
 func GetSignatureForRegistration(email string, nodeAddress common.Address, privateKey *ecdsa.PrivateKey) ([]byte, error) {
 	message := fmt.Sprintf(nodeRegistrationMessageFormat, email, nodeAddress.Hex())
@@ -44,15 +55,55 @@ func GetSignatureForLogin(nonce string, nodeAddress common.Address, privateKey *
 	return createSignature([]byte(message), privateKey)
 }
 
+// Creates a Constellation whitelist signature for a node. The signed message is
+// keccak256(abi.encodePacked(nodeAddress)), with the Ethereum signed message prefix.
+func GetConstellationWhitelistSignature(nodeAddress common.Address, adminKey *ecdsa.PrivateKey) ([]byte, error) {
+	message := crypto.Keccak256(nodeAddress.Bytes())
+	return createSignature(message, adminKey)
+}
+
+// Creates a Constellation minipool deposit signature. The signed message is
+// keccak256(abi.encodePacked(minipoolAddress, salt, nodeAddress)), with the Ethereum signed message prefix.
+func GetConstellationDepositSignature(minipoolAddress common.Address, salt *big.Int, nodeAddress common.Address, adminKey *ecdsa.PrivateKey) ([]byte, error) {
+	message := crypto.Keccak256(minipoolAddress.Bytes(), common.LeftPadBytes(salt.Bytes(), 32), nodeAddress.Bytes())
+	return createSignature(message, adminKey)
+}
+
+// Verifies a Constellation whitelist signature was made by the admin
+func VerifyConstellationWhitelistSignature(nodeAddress common.Address, signature []byte, adminAddress common.Address) error {
+	message := crypto.Keccak256(nodeAddress.Bytes())
+	address, err := getAddressFromSignature(message, signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if address != adminAddress {
+		return fmt.Errorf("%w: signature does not match admin address", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// Verifies a Constellation minipool deposit signature was made by the admin
+func VerifyConstellationDepositSignature(minipoolAddress common.Address, salt *big.Int, nodeAddress common.Address, signature []byte, adminAddress common.Address) error {
+	message := crypto.Keccak256(minipoolAddress.Bytes(), common.LeftPadBytes(salt.Bytes(), 32), nodeAddress.Bytes())
+	address, err := getAddressFromSignature(message, signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if address != adminAddress {
+		return fmt.Errorf("%w: signature does not match admin address", ErrInvalidSignature)
+	}
+	return nil
+}
+
 // Verifies a signature for node registration
 func VerifyRegistrationSignature(email string, nodeAddress common.Address, signature []byte) error {
 	message := fmt.Sprintf(nodeRegistrationMessageFormat, email, nodeAddress.Hex())
 	address, err := getAddressFromSignature([]byte(message), signature)
 	if err != nil {
-		return fmt.Errorf("error verifying signature: %w", err)
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
 	}
 	if address != nodeAddress {
-		return errors.New("signature does not match node address")
+		return fmt.Errorf("%w: signature does not match node address", ErrInvalidSignature)
 	}
 	return nil
 }
@@ -62,16 +113,55 @@ func VerifyLoginSignature(nonce string, nodeAddress common.Address, signature []
 	message := fmt.Sprintf(loginMessageFormat, nonce, nodeAddress.Hex())
 	address, err := getAddressFromSignature([]byte(message), signature)
 	if err != nil {
-		return fmt.Errorf("error verifying signature: %w", err)
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
 	}
 	if address != nodeAddress {
-		return errors.New("signature does not match node address")
+		return fmt.Errorf("%w: signature does not match node address", ErrInvalidSignature)
 	}
 	return nil
 }
 
+// Decodes a hex-encoded signature, with or without a 0x prefix, and makes sure it has the right length
+func DecodeSignature(signature string) ([]byte, error) {
+	signature = strings.TrimPrefix(strings.TrimPrefix(signature, "0x"), "0X")
+	bytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if len(bytes) != crypto.SignatureLength {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, ErrInvalidSignatureLength)
+	}
+	return bytes, nil
+}
+
 // Gets the session token from a request
 func GetSessionTokenFromRequest(r *http.Request) (string, error) {
+	return getBearerToken(r)
+}
+
+// Gets the admin token from a request
+func GetAdminTokenFromRequest(r *http.Request) (string, error) {
+	return getBearerToken(r)
+}
+
+// Adds an authorization header to an HTTP request
+func AddAuthorizationHeader(request *http.Request, session *db.Session) {
+	AddSessionTokenAuthorizationHeader(request, session.Token)
+}
+
+// Adds an authorization header for a session token to an HTTP request
+func AddSessionTokenAuthorizationHeader(request *http.Request, token string) {
+	request.Header.Set(authHeader, fmt.Sprintf(authHeaderFormat, token))
+	request.Header.Set("Content-Type", "application/json; charset=UTF-8")
+}
+
+// Adds an admin authorization header to an HTTP request
+func AddAdminAuthorizationHeader(request *http.Request, token string) {
+	request.Header.Set(authHeader, fmt.Sprintf(authHeaderFormat, token))
+}
+
+// Gets the bearer token from a request's auth header
+func getBearerToken(r *http.Request) (string, error) {
 	// Get the auth header
 	authHeaderVals, exists := r.Header[authHeader]
 	if !exists || len(authHeaderVals) == 0 {
@@ -90,22 +180,36 @@ func GetSessionTokenFromRequest(r *http.Request) (string, error) {
 	return elements[1], nil
 }
 
-// Adds an authorization header to an HTTP request
-func AddAuthorizationHeader(request *http.Request, session *db.Session) {
-	request.Header.Set(authHeader, fmt.Sprintf(authHeaderFormat, session.Token))
-	request.Header.Set("Content-Type", "application/json; charset=UTF-8")
-}
-
-// Gets the address of the private key used to sign a message from a signature
+// Gets the address of the private key used to sign a message from a signature. The signature isn't modified.
 func getAddressFromSignature(message []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, ErrInvalidSignatureLength
+	}
+
 	// Fix the ECDSA 'v' (see https://medium.com/mycrypto/the-magic-of-digital-signatures-on-ethereum-98fe184dc9c7#:~:text=The%20version%20number,2%E2%80%9D%20was%20introduced)
-	if signature[crypto.RecoveryIDOffset] >= 4 {
-		signature[crypto.RecoveryIDOffset] -= 27
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature)
+	switch sig[crypto.RecoveryIDOffset] {
+	case 0, 1:
+	case 27, 28:
+		sig[crypto.RecoveryIDOffset] -= 27
+	default:
+		return common.Address{}, ErrInvalidRecoveryId
+	}
+
+	// Reject malleable signatures and out-of-range values
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	if s.Cmp(secp256k1HalfN) > 0 {
+		return common.Address{}, ErrMalleableSignature
+	}
+	if !crypto.ValidateSignatureValues(sig[crypto.RecoveryIDOffset], r, s, true) {
+		return common.Address{}, ErrInvalidSignatureValues
 	}
 
 	// Get the address
 	messageHash := accounts.TextHash(message)
-	pubkeyBytes, err := crypto.SigToPub(messageHash, signature)
+	pubkeyBytes, err := crypto.SigToPub(messageHash, sig)
 	if err != nil {
 		return common.Address{}, fmt.Errorf("error recovering pubkey from signature: %w", err)
 	}
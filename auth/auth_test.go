@@ -1,9 +1,12 @@
 package auth
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"net/url"
 	"testing"
@@ -121,6 +124,97 @@ func TestLogin(t *testing.T) {
 	t.Log("Verified login signature")
 }
 
+func TestSignatureParsing(t *testing.T) {
+	// Get a private key
+	privateKey, err := test.GetEthPrivateKey(0)
+	if err != nil {
+		t.Fatalf("error getting private key: %v", err)
+	}
+	pubkey := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	// Sign a message
+	message := []byte("hello world")
+	signature, err := createSignature(message, privateKey)
+	require.NoError(t, err)
+	t.Logf("Signed message, signature = %x", signature)
+
+	// Create the malleable twin of the signature: s' = N - s with the recovery ID flipped
+	s := new(big.Int).SetBytes(signature[32:64])
+	highS := new(big.Int).Sub(crypto.S256().Params().N, s)
+	malleable := bytes.Clone(signature)
+	highS.FillBytes(malleable[32:64])
+	malleable[crypto.RecoveryIDOffset] = 27 + ((malleable[crypto.RecoveryIDOffset] - 27) ^ 1)
+
+	// Build signatures with modified recovery IDs
+	withV := func(v byte) []byte {
+		modified := bytes.Clone(signature)
+		modified[crypto.RecoveryIDOffset] = v
+		return modified
+	}
+	v := signature[crypto.RecoveryIDOffset]
+
+	tests := []struct {
+		name      string
+		signature []byte
+		err       error
+	}{
+		{name: "v = 27 or 28", signature: withV(v)},
+		{name: "v = 0 or 1", signature: withV(v - 27)},
+		{name: "empty", signature: []byte{}, err: ErrInvalidSignatureLength},
+		{name: "too short", signature: signature[:64], err: ErrInvalidSignatureLength},
+		{name: "too long", signature: append(bytes.Clone(signature), 0), err: ErrInvalidSignatureLength},
+		{name: "v = 2", signature: withV(2), err: ErrInvalidRecoveryId},
+		{name: "v = 29", signature: withV(29), err: ErrInvalidRecoveryId},
+		{name: "high s", signature: malleable, err: ErrMalleableSignature},
+		{name: "zero r", signature: append(make([]byte, 32), signature[32:]...), err: ErrInvalidSignatureValues},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := bytes.Clone(tt.signature)
+			address, err := getAddressFromSignature(message, tt.signature)
+			require.Equal(t, original, tt.signature, "signature was modified")
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, pubkey, address)
+		})
+	}
+}
+
+func TestDecodeSignature(t *testing.T) {
+	signature := bytes.Repeat([]byte{0xab}, crypto.SignatureLength)
+	encoded := hex.EncodeToString(signature)
+
+	tests := []struct {
+		name    string
+		encoded string
+		valid   bool
+	}{
+		{name: "with 0x prefix", encoded: "0x" + encoded, valid: true},
+		{name: "without prefix", encoded: encoded, valid: true},
+		{name: "uppercase prefix", encoded: "0X" + encoded, valid: true},
+		{name: "not hex", encoded: "0xzz", valid: false},
+		{name: "odd length", encoded: encoded[1:], valid: false},
+		{name: "too short", encoded: encoded[2:], valid: false},
+		{name: "empty", encoded: "", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeSignature(tt.encoded)
+			if !tt.valid {
+				require.ErrorIs(t, err, ErrInvalidSignature)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, signature, decoded)
+		})
+	}
+}
+
 // ==========================
 // === Internal Functions ===
 // ==========================
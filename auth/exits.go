@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"filippo.io/age"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/rocket-pool/node-manager-core/utils"
+)
+
+const (
+	// The length of a BLS signature on a voluntary exit
+	exitSignatureLength int = 96
+)
+
+var (
+	ErrInvalidExitMessage error = errors.New("invalid exit message")
+)
+
+// Encrypts a signed exit message to an age public key the way node operators do before uploading it. The result is
+// the JSON-serialized message, encrypted and 0x-prefixed hex encoded.
+func EncryptExitMessage(message api.ExitMessage, publicKey string) (string, error) {
+	recipient, err := age.ParseX25519Recipient(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("error parsing encryption public key: %w", err)
+	}
+	plaintext, err := json.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("error serializing exit message: %w", err)
+	}
+
+	var ciphertext bytes.Buffer
+	writer, err := age.Encrypt(&ciphertext, recipient)
+	if err != nil {
+		return "", fmt.Errorf("error creating encryptor: %w", err)
+	}
+	_, err = writer.Write(plaintext)
+	if err != nil {
+		return "", fmt.Errorf("error encrypting exit message: %w", err)
+	}
+	err = writer.Close()
+	if err != nil {
+		return "", fmt.Errorf("error encrypting exit message: %w", err)
+	}
+	return utils.EncodeHexWithPrefix(ciphertext.Bytes()), nil
+}
+
+// Decrypts a signed exit message from EncryptExitMessage with the identity it was encrypted to, and makes sure it's
+// well-formed
+func DecryptExitMessage(encryptedMessage string, identity *age.X25519Identity) (api.ExitMessage, error) {
+	ciphertext, err := utils.DecodeHex(encryptedMessage)
+	if err != nil {
+		return api.ExitMessage{}, fmt.Errorf("%w: not hex encoded: %s", ErrInvalidExitMessage, err.Error())
+	}
+	reader, err := age.Decrypt(bytes.NewReader(ciphertext), identity)
+	if err != nil {
+		return api.ExitMessage{}, fmt.Errorf("%w: can't be decrypted: %s", ErrInvalidExitMessage, err.Error())
+	}
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return api.ExitMessage{}, fmt.Errorf("%w: can't be decrypted: %s", ErrInvalidExitMessage, err.Error())
+	}
+
+	var message api.ExitMessage
+	err = json.Unmarshal(plaintext, &message)
+	if err != nil {
+		return api.ExitMessage{}, fmt.Errorf("%w: not a serialized exit message: %s", ErrInvalidExitMessage, err.Error())
+	}
+	err = ValidateExitMessage(message)
+	if err != nil {
+		return api.ExitMessage{}, err
+	}
+	return message, nil
+}
+
+// Makes sure an exit message's fields are well-formed
+func ValidateExitMessage(message api.ExitMessage) error {
+	_, err := strconv.ParseUint(message.Message.Epoch, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid epoch [%s]", ErrInvalidExitMessage, message.Message.Epoch)
+	}
+	_, err = strconv.ParseUint(message.Message.ValidatorIndex, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid validator index [%s]", ErrInvalidExitMessage, message.Message.ValidatorIndex)
+	}
+	signature, err := utils.DecodeHex(message.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature [%s]", ErrInvalidExitMessage, message.Signature)
+	}
+	if len(signature) != exitSignatureLength {
+		return fmt.Errorf("%w: signature must be %d bytes but was %d", ErrInvalidExitMessage, exitSignatureLength, len(signature))
+	}
+	return nil
+}
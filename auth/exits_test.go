@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure exit messages survive encryption and only decrypt with the right identity
+func TestExitMessageEncryption(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	otherIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	message := api.ExitMessage{
+		Message: api.ExitMessageDetails{
+			Epoch:          "256",
+			ValidatorIndex: "12",
+		},
+		Signature: "0x" + strings.Repeat("ab", 96),
+	}
+
+	// Round trip
+	encrypted, err := EncryptExitMessage(message, identity.Recipient().String())
+	require.NoError(t, err)
+	decrypted, err := DecryptExitMessage(encrypted, identity)
+	require.NoError(t, err)
+	require.Equal(t, message, decrypted)
+
+	// Wrong identity
+	_, err = DecryptExitMessage(encrypted, otherIdentity)
+	require.True(t, errors.Is(err, ErrInvalidExitMessage))
+
+	// Not encrypted
+	_, err = DecryptExitMessage("0x1234", identity)
+	require.True(t, errors.Is(err, ErrInvalidExitMessage))
+
+	// Malformed fields
+	malformed := []api.ExitMessage{message, message, message}
+	malformed[0].Message.Epoch = "soon"
+	malformed[1].Message.ValidatorIndex = "-1"
+	malformed[2].Signature = "0x1234"
+	for _, malformedMessage := range malformed {
+		encrypted, err := EncryptExitMessage(malformedMessage, identity.Recipient().String())
+		require.NoError(t, err)
+		_, err = DecryptExitMessage(encrypted, identity)
+		require.True(t, errors.Is(err, ErrInvalidExitMessage))
+	}
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const (
+	// Header with the HMAC signature of a webhook delivery's body
+	WebhookSignatureHeader string = "X-NodeSet-Signature"
+
+	// Header with the type of event in a webhook delivery
+	WebhookEventHeader string = "X-NodeSet-Event"
+
+	// Header with the ID of the event in a webhook delivery
+	WebhookDeliveryHeader string = "X-NodeSet-Delivery"
+
+	// Header with the secret to sign a new webhook's deliveries with, so it stays out of URLs and request logs
+	WebhookSecretHeader string = "X-NodeSet-Webhook-Secret"
+
+	// Format for webhook signatures
+	webhookSignatureFormat string = "sha256=%s"
+)
+
+var (
+	ErrInvalidWebhookSignature error = errors.New("invalid webhook signature")
+)
+
+// Creates the signature for a webhook delivery's body, which is the hex-encoded HMAC-SHA256 of it
+func GetWebhookSignature(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return fmt.Sprintf(webhookSignatureFormat, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Verifies the signature of a webhook delivery's body
+func VerifyWebhookSignature(body []byte, secret string, signature string) error {
+	expected := GetWebhookSignature(body, secret)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
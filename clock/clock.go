@@ -0,0 +1,109 @@
+package clock
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A virtual clock that the mock uses for all of its timekeeping. It follows the system clock by default, but it can
+// be frozen, set to an arbitrary time, and moved forward so tests can control time deterministically.
+type Clock struct {
+	// The offset from the system time while the clock is running
+	offset time.Duration
+
+	// The time the clock is stopped at while it's frozen
+	frozen     bool
+	frozenTime time.Time
+
+	lock sync.Mutex
+}
+
+// Creates a new clock that follows the system clock
+func NewClock() *Clock {
+	return &Clock{}
+}
+
+// Get the current time
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now()
+}
+
+// Check if the clock is frozen
+func (c *Clock) IsFrozen() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.frozen
+}
+
+// Stop the clock at the current time. It won't move until it's unfrozen, set, or advanced.
+func (c *Clock) Freeze() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.frozen {
+		return
+	}
+	c.frozenTime = c.now()
+	c.frozen = true
+}
+
+// Start the clock again from the time it was frozen at
+func (c *Clock) Unfreeze() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.frozen {
+		return
+	}
+	c.offset = c.frozenTime.Sub(time.Now())
+	c.frozen = false
+}
+
+// Unfreeze the clock and put it back in line with the system clock
+func (c *Clock) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.offset = 0
+	c.frozen = false
+}
+
+// Set the current time. If the clock is running, it keeps running from the new time.
+func (c *Clock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.frozen {
+		c.frozenTime = now
+		return
+	}
+	c.offset = now.Sub(time.Now())
+}
+
+// Move the clock forward by the provided duration
+func (c *Clock) Advance(duration time.Duration) error {
+	if duration < 0 {
+		return fmt.Errorf("can't advance the clock by a negative duration")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.frozen {
+		c.frozenTime = c.frozenTime.Add(duration)
+		return nil
+	}
+	c.offset += duration
+	return nil
+}
+
+// Get the current time without locking
+func (c *Clock) now() time.Time {
+	if c.frozen {
+		return c.frozenTime
+	}
+	return time.Now().Add(c.offset)
+}
@@ -0,0 +1,40 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the clock can be frozen, set, and advanced
+func TestTimeTravel(t *testing.T) {
+	clock := NewClock()
+	require.WithinDuration(t, time.Now(), clock.Now(), time.Second)
+
+	// Freeze it
+	clock.Freeze()
+	require.True(t, clock.IsFrozen())
+	frozenTime := clock.Now()
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, frozenTime, clock.Now())
+
+	// Set it and advance it while frozen
+	target := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock.Set(target)
+	require.True(t, target.Equal(clock.Now()))
+	require.NoError(t, clock.Advance(time.Hour))
+	require.True(t, target.Add(time.Hour).Equal(clock.Now()))
+	require.Error(t, clock.Advance(-time.Hour))
+
+	// Unfreeze it, which should keep running from the frozen time
+	clock.Unfreeze()
+	require.False(t, clock.IsFrozen())
+	require.WithinDuration(t, target.Add(time.Hour), clock.Now(), time.Second)
+	require.NoError(t, clock.Advance(time.Hour))
+	require.WithinDuration(t, target.Add(2*time.Hour), clock.Now(), time.Second)
+
+	// Reset it
+	clock.Reset()
+	require.WithinDuration(t, time.Now(), clock.Now(), time.Second)
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// A node operator calling the NodeSet API
+	AuditActor_Node string = "node"
+
+	// Someone calling the admin API
+	AuditActor_Admin string = "admin"
+
+	// The mock's own cycle scheduler
+	AuditActor_Scheduler string = "scheduler"
+)
+
+// A record of a state-changing operation
+type AuditEntry struct {
+	// When the operation happened
+	Time time.Time
+
+	// Who performed the operation
+	Actor string
+
+	// The address of the node that performed the operation, if there was one
+	NodeAddress *common.Address
+
+	// The method and path of the route that was called, or a description of the operation if it didn't come from a route
+	Route string
+
+	// The HTTP status code of the response, or 0 if the operation didn't come from a route
+	StatusCode int
+
+	// Whether or not the operation succeeded
+	Success bool
+
+	// The error message if the operation failed
+	Error string
+}
+
+func (e *AuditEntry) Clone() *AuditEntry {
+	clone := *e
+	if e.NodeAddress != nil {
+		address := *e.NodeAddress
+		clone.NodeAddress = &address
+	}
+	return &clone
+}
+
+// Adds an entry to the audit log. Its time is set to the current time.
+func (d *Database) AddAuditEntry(entry AuditEntry) {
+	entry.Time = d.clock.Now()
+	d.AuditLog = append(d.AuditLog, &entry)
+	d.changes.markAuditEntry(len(d.AuditLog) - 1)
+}
+
+// Gets the audit log, oldest first
+func (d *Database) GetAuditLog() []*AuditEntry {
+	return d.AuditLog
+}
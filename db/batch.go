@@ -0,0 +1,79 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A problem with one of the entries in a batch
+type BatchEntryError struct {
+	// The entry's index in the batch
+	Index int
+
+	// What's wrong with it
+	Err error
+}
+
+func (e *BatchEntryError) Error() string {
+	return fmt.Sprintf("entry %d: %s", e.Index, e.Err.Error())
+}
+
+func (e *BatchEntryError) Unwrap() error {
+	return e.Err
+}
+
+// The problems with every invalid entry in a batch. Batches are all-or-nothing, so none of a batch's entries are
+// applied if it has any invalid ones. It wraps each entry's error, so errors.Is matches the reasons the entries failed.
+type BatchError struct {
+	// The invalid entries, in order
+	Entries []*BatchEntryError
+}
+
+// Records a problem with an entry. Only the first problem with each entry is kept.
+func (e *BatchError) Add(index int, err error) {
+	if e.HasEntry(index) {
+		return
+	}
+	e.Entries = append(e.Entries, &BatchEntryError{
+		Index: index,
+		Err:   err,
+	})
+	sort.SliceStable(e.Entries, func(i, j int) bool {
+		return e.Entries[i].Index < e.Entries[j].Index
+	})
+}
+
+// Checks if a problem has been recorded for an entry
+func (e *BatchError) HasEntry(index int) bool {
+	for _, entry := range e.Entries {
+		if entry.Index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// Gets the batch error if any problems were recorded, or nil if the batch is valid
+func (e *BatchError) Err() error {
+	if len(e.Entries) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *BatchError) Error() string {
+	messages := make([]string, len(e.Entries))
+	for i, entry := range e.Entries {
+		messages[i] = entry.Error()
+	}
+	return fmt.Sprintf("invalid batch entries: %s", strings.Join(messages, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Entries))
+	for i, entry := range e.Entries {
+		errs[i] = entry
+	}
+	return errs
+}
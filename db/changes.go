@@ -0,0 +1,107 @@
+package db
+
+import "github.com/rocket-pool/node-manager-core/beacon"
+
+// The entities a database has changed since its changes were last taken, so a persistent store only has to write
+// those instead of the whole database. Databases only track changes if a store enables it, and every method on a nil
+// change set is a no-op.
+type changeSet struct {
+	// Users that were added or changed, including their nodes and validators
+	users map[*User]bool
+
+	// Vaults that were added or changed, along with their networks
+	vaults map[*StakeWiseVault]string
+
+	// Sessions that were added or changed (true) or removed (false)
+	sessions map[*Session]bool
+
+	// Indices of the audit entries that were added
+	auditEntries []int
+
+	// True if NextValidatorIndex changed
+	nextValidatorIndex bool
+}
+
+// Creates an empty change set
+func newChangeSet() *changeSet {
+	return &changeSet{
+		users:    map[*User]bool{},
+		vaults:   map[*StakeWiseVault]string{},
+		sessions: map[*Session]bool{},
+	}
+}
+
+// Returns true if nothing has changed
+func (c *changeSet) isEmpty() bool {
+	return len(c.users) == 0 && len(c.vaults) == 0 && len(c.sessions) == 0 && len(c.auditEntries) == 0 && !c.nextValidatorIndex
+}
+
+// Records a change to a user
+func (c *changeSet) markUser(user *User) {
+	if c == nil {
+		return
+	}
+	c.users[user] = true
+}
+
+// Records a change to a vault
+func (c *changeSet) markVault(network string, vault *StakeWiseVault) {
+	if c == nil {
+		return
+	}
+	c.vaults[vault] = network
+}
+
+// Records a new or changed session
+func (c *changeSet) markSession(session *Session) {
+	if c == nil {
+		return
+	}
+	c.sessions[session] = true
+}
+
+// Records a removed session
+func (c *changeSet) markSessionRemoved(session *Session) {
+	if c == nil {
+		return
+	}
+	c.sessions[session] = false
+}
+
+// Records a new audit entry
+func (c *changeSet) markAuditEntry(index int) {
+	if c == nil {
+		return
+	}
+	c.auditEntries = append(c.auditEntries, index)
+}
+
+// Records a change to NextValidatorIndex
+func (c *changeSet) markNextValidatorIndex() {
+	if c == nil {
+		return
+	}
+	c.nextValidatorIndex = true
+}
+
+// Starts tracking the database's changes
+func (d *Database) trackChanges() {
+	d.changes = newChangeSet()
+}
+
+// Gets the changes since the last call and starts a new change set
+func (d *Database) takeChanges() *changeSet {
+	changes := d.changes
+	d.changes = newChangeSet()
+	return changes
+}
+
+// Records a change to the users that own the validators for a pubkey
+func (d *Database) markValidatorChanged(network string, pubkey beacon.ValidatorPubkey) {
+	if d.changes == nil {
+		return
+	}
+	for _, entry := range d.index.getValidators(network, pubkey) {
+		d.changes.markUser(entry.user)
+	}
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrNotConstellationWhitelisted error = errors.New("node hasn't been whitelisted for Constellation on the provided deployment")
+	ErrMinipoolLimitReached        error = errors.New("user has reached their minipool limit")
+	ErrMinipoolAlreadyExists       error = errors.New("a deposit signature has already been issued for the minipool")
+)
+
+// Constellation info for a node on a single deployment
+type ConstellationNodeInfo struct {
+	// The whitelist signature issued to the node
+	WhitelistSignature []byte
+
+	// The minipools the node has been issued deposit signatures for
+	Minipools []*Minipool
+}
+
+// A Constellation minipool that has been issued a deposit signature
+type Minipool struct {
+	// The minipool address
+	Address common.Address
+
+	// The salt used to create the minipool address
+	Salt *big.Int
+
+	// The deposit signature issued for the minipool
+	DepositSignature []byte
+}
+
+func newConstellationNodeInfo(whitelistSignature []byte) *ConstellationNodeInfo {
+	return &ConstellationNodeInfo{
+		WhitelistSignature: whitelistSignature,
+		Minipools:          []*Minipool{},
+	}
+}
+
+func (c *ConstellationNodeInfo) Clone() *ConstellationNodeInfo {
+	clone := newConstellationNodeInfo(common.CopyBytes(c.WhitelistSignature))
+	clone.Minipools = make([]*Minipool, len(c.Minipools))
+	for i, minipool := range c.Minipools {
+		clone.Minipools[i] = minipool.Clone()
+	}
+	return clone
+}
+
+func (m *Minipool) Clone() *Minipool {
+	return &Minipool{
+		Address:          m.Address,
+		Salt:             new(big.Int).Set(m.Salt),
+		DepositSignature: common.CopyBytes(m.DepositSignature),
+	}
+}
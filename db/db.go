@@ -1,14 +1,35 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/big"
+	"sort"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/clock"
 	"github.com/rocket-pool/node-manager-core/beacon"
 )
 
+var (
+	ErrUnknownValidatorIndex  error = errors.New("no validator exists with the provided index")
+	ErrValidatorNotActive     error = errors.New("validator is not active and can't exit")
+	ErrUserNotFound           error = errors.New("user not found")
+	ErrUserAlreadyExists      error = errors.New("user already exists")
+	ErrVaultNotFound          error = errors.New("StakeWise vault not found")
+	ErrVaultAlreadyExists     error = errors.New("StakeWise vault already exists")
+	ErrDepositDataSetNotFound error = errors.New("StakeWise vault doesn't have a deposit data set with the provided version")
+	ErrInvalidNetwork         error = errors.New("unknown network")
+	ErrInvalidPubkey          error = errors.New("invalid validator pubkey")
+	ErrInvalidDepositData     error = errors.New("invalid deposit data")
+	ErrValidatorNotFound      error = errors.New("node doesn't have the validator")
+	ErrSessionNotFound        error = errors.New("no session with the provided nonce")
+	ErrSessionAlreadyLoggedIn error = errors.New("session already logged in")
+)
+
 // Mock database for storing nodeset.io info
 type Database struct {
 	// Collection of StakeWise vaults
@@ -20,19 +41,36 @@ type Database struct {
 	// Collection of sessions
 	Sessions []*Session
 
+	// The index the next validator to join the simulated Beacon Chain will get
+	NextValidatorIndex uint64
+
+	// Record of state-changing operations, oldest first
+	AuditLog []*AuditEntry
+
 	// Internal fields
-	logger *slog.Logger
+	index   *databaseIndex
+	changes *changeSet
+	clock   *clock.Clock
+	logger  *slog.Logger
 }
 
-// Creates a new database
+// Creates a new database that uses the system time for its timestamps
 func NewDatabase(logger *slog.Logger) *Database {
 	return &Database{
 		StakeWiseVaults: map[string][]*StakeWiseVault{},
 		Users:           []*User{},
+		AuditLog:        []*AuditEntry{},
+		index:           newDatabaseIndex(),
+		clock:           clock.NewClock(),
 		logger:          logger,
 	}
 }
 
+// Set the clock used for the database's timestamps
+func (d *Database) SetClock(newClock *clock.Clock) {
+	d.clock = newClock
+}
+
 // Adds a StakeWise vault to the database
 func (d *Database) AddStakeWiseVault(address common.Address, networkName string) error {
 	networkVaults, exists := d.StakeWiseVaults[networkName]
@@ -43,79 +81,220 @@ func (d *Database) AddStakeWiseVault(address common.Address, networkName string)
 
 	for _, vault := range networkVaults {
 		if vault.Address == address {
-			return fmt.Errorf("stakewise vault with address [%s] already exists", address.Hex())
+			return fmt.Errorf("%w: address [%s]", ErrVaultAlreadyExists, address.Hex())
 		}
 	}
 
 	vault := NewStakeWiseVaultInfo(address)
+	vault.CreatedTime = d.clock.Now()
 	networkVaults = append(networkVaults, vault)
 	d.StakeWiseVaults[networkName] = networkVaults
+	d.changes.markVault(networkName, vault)
 	return nil
 }
 
+// Gets all of the StakeWise vaults by network
+func (d *Database) GetStakeWiseVaults() map[string][]*StakeWiseVault {
+	return d.StakeWiseVaults
+}
+
 // Adds a user to the database
 func (d *Database) AddUser(email string) error {
-	for _, user := range d.Users {
-		if user.Email == email {
-			return fmt.Errorf("user with email [%s] already exists", email)
-		}
+	if _, exists := d.index.users[email]; exists {
+		return fmt.Errorf("%w: email [%s]", ErrUserAlreadyExists, email)
 	}
 
-	user := newUser(email)
+	user := newUser(email, d.clock.Now())
 	d.Users = append(d.Users, user)
+	d.index.users[email] = user
+	d.changes.markUser(user)
 	return nil
 }
 
+// Gets all of the users
+func (d *Database) GetUsers() []*User {
+	return d.Users
+}
+
 // Whitelists a node with a user
 func (d *Database) WhitelistNodeAccount(email string, nodeAddress common.Address) error {
-	for _, user := range d.Users {
-		if user.Email != email {
-			continue
-		}
-		user.WhitelistNode(nodeAddress)
-		return nil
+	user, exists := d.index.users[email]
+	if !exists {
+		return fmt.Errorf("%w: email [%s]", ErrUserNotFound, email)
 	}
-
-	return fmt.Errorf("user with email [%s] not found", email)
+	node := user.WhitelistNode(nodeAddress)
+	if node.CreatedTime.IsZero() {
+		node.CreatedTime = d.clock.Now()
+	}
+	d.index.addNode(user, node, !node.RegisteredTime.IsZero())
+	d.changes.markUser(user)
+	return nil
 }
 
 // Registers a node with a user
 func (d *Database) RegisterNodeAccount(email string, nodeAddress common.Address) error {
-	for _, user := range d.Users {
-		if user.Email != email {
-			continue
+	user, exists := d.index.users[email]
+	if !exists {
+		return fmt.Errorf("%w: email [%s]", ErrUserNotFound, email)
+	}
+	err := user.RegisterNode(nodeAddress)
+	if err != nil {
+		return err
+	}
+	node := user.RegisteredNodes[len(user.RegisteredNodes)-1]
+	node.RegisteredTime = d.clock.Now()
+	d.index.addNode(user, node, true)
+	d.changes.markUser(user)
+	return nil
+}
+
+// Sets the max number of Constellation minipools a user's nodes can have on each deployment (0 for no limit)
+func (d *Database) SetMinipoolLimit(email string, limit int) error {
+	user, exists := d.index.users[email]
+	if !exists {
+		return fmt.Errorf("%w: email [%s]", ErrUserNotFound, email)
+	}
+	user.MinipoolLimit = limit
+	d.changes.markUser(user)
+	return nil
+}
+
+// Records a Constellation whitelist signature for a registered node
+func (d *Database) WhitelistNodeForConstellation(nodeAddress common.Address, deployment string, signature []byte) error {
+	// Get the node
+	user, node := d.getUserForRegisteredNode(nodeAddress)
+	if node == nil {
+		return fmt.Errorf("%w: address [%s]", ErrUnregisteredNode, nodeAddress.Hex())
+	}
+
+	d.changes.markUser(user)
+	info, exists := node.Constellation[deployment]
+	if !exists {
+		node.Constellation[deployment] = newConstellationNodeInfo(signature)
+		return nil
+	}
+	info.WhitelistSignature = signature
+	return nil
+}
+
+// Records a Constellation minipool deposit signature for a registered node, enforcing its user's minipool limit
+func (d *Database) AddMinipool(nodeAddress common.Address, deployment string, minipoolAddress common.Address, salt *big.Int, signature []byte) error {
+	// Get the node
+	user, node := d.getUserForRegisteredNode(nodeAddress)
+	if node == nil {
+		return fmt.Errorf("%w: address [%s]", ErrUnregisteredNode, nodeAddress.Hex())
+	}
+	info, exists := node.Constellation[deployment]
+	if !exists {
+		return fmt.Errorf("%w: address [%s]", ErrNotConstellationWhitelisted, nodeAddress.Hex())
+	}
+
+	// Make sure the minipool is new
+	for _, minipool := range info.Minipools {
+		if minipool.Address == minipoolAddress {
+			return fmt.Errorf("%w: minipool [%s]", ErrMinipoolAlreadyExists, minipoolAddress.Hex())
+		}
+	}
+
+	// Check the limit across all of the user's nodes
+	if user.MinipoolLimit > 0 {
+		count := 0
+		for _, candidate := range user.RegisteredNodes {
+			count += candidate.GetMinipoolCount(deployment)
+		}
+		if count >= user.MinipoolLimit {
+			return fmt.Errorf("%w: address [%s]", ErrMinipoolLimitReached, nodeAddress.Hex())
 		}
-		return user.RegisterNode(nodeAddress)
 	}
 
-	return fmt.Errorf("user with email [%s] not found", email)
+	info.Minipools = append(info.Minipools, &Minipool{
+		Address:          minipoolAddress,
+		Salt:             new(big.Int).Set(salt),
+		DepositSignature: signature,
+	})
+	d.changes.markUser(user)
+	return nil
 }
 
 // Creates a new session
 func (d *Database) CreateSession() *Session {
-	session := newSession()
+	session := newSession(d.clock.Now())
 	d.Sessions = append(d.Sessions, session)
+	d.index.addSession(session)
+	d.changes.markSession(session)
 	return session
 }
 
-// Gets a session by its nonce
-func (d *Database) GetSessionByNonce(nonce string) *Session {
+// Creates a new session for a client, optionally bound to a node address and expiring at the provided time.
+// Expired nonces are pruned first, and the request fails if the client already has maxOutstanding nonces that haven't
+// been used yet (0 for no limit).
+func (d *Database) CreateClientSession(clientId string, boundAddress common.Address, expiresTime time.Time, maxOutstanding int) (*Session, error) {
+	d.pruneExpiredSessions()
+	if maxOutstanding > 0 && d.GetOutstandingNonceCount(clientId) >= maxOutstanding {
+		return nil, fmt.Errorf("%w: client [%s] has %d", ErrTooManyNonces, clientId, maxOutstanding)
+	}
+
+	session := newSession(d.clock.Now())
+	session.ClientId = clientId
+	session.BoundAddress = boundAddress
+	session.ExpiresTime = expiresTime
+	d.Sessions = append(d.Sessions, session)
+	d.index.addSession(session)
+	d.changes.markSession(session)
+	return session, nil
+}
+
+// Gets the number of nonces issued to a client that haven't been used to log in or expired yet
+func (d *Database) GetOutstandingNonceCount(clientId string) int {
+	now := d.clock.Now()
+	count := 0
 	for _, session := range d.Sessions {
-		if session.Nonce == nonce {
-			return session
+		if session.ClientId == clientId && !session.IsLoggedIn && !session.IsExpired(now) {
+			count++
 		}
 	}
-	return nil
+	return count
 }
 
-// Gets a session by its token
-func (d *Database) GetSessionByToken(token string) *Session {
+// Revokes all of a node's logged in sessions other than the one with the provided token, returning how many were revoked
+func (d *Database) RevokeSessions(nodeAddress common.Address, keepToken string) int {
+	sessions := make([]*Session, 0, len(d.Sessions))
 	for _, session := range d.Sessions {
-		if session.Token == token {
-			return session
+		if session.IsLoggedIn && session.NodeAddress == nodeAddress && session.Token != keepToken {
+			d.index.removeSession(session)
+			d.changes.markSessionRemoved(session)
+			continue
 		}
+		sessions = append(sessions, session)
 	}
-	return nil
+	revoked := len(d.Sessions) - len(sessions)
+	d.Sessions = sessions
+	return revoked
+}
+
+// Removes sessions whose nonces expired before they were used
+func (d *Database) pruneExpiredSessions() {
+	now := d.clock.Now()
+	sessions := make([]*Session, 0, len(d.Sessions))
+	for _, session := range d.Sessions {
+		if session.IsExpired(now) {
+			d.index.removeSession(session)
+			d.changes.markSessionRemoved(session)
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	d.Sessions = sessions
+}
+
+// Gets a session by its nonce
+func (d *Database) GetSessionByNonce(nonce string) *Session {
+	return d.index.sessionsByNonce[nonce]
+}
+
+// Gets a session by its token
+func (d *Database) GetSessionByToken(token string) *Session {
+	return d.index.sessionsByToken[token]
 }
 
 // Attempts to log an existing session in with the provided node address and nonce
@@ -123,24 +302,27 @@ func (d *Database) Login(nodeAddress common.Address, nonce string) error {
 	// Get the session
 	session := d.GetSessionByNonce(nonce)
 	if session == nil {
-		return fmt.Errorf("no session with provided nonce")
+		return ErrSessionNotFound
 	}
 
 	if session.IsLoggedIn {
-		return fmt.Errorf("session already logged in")
+		return ErrSessionAlreadyLoggedIn
 	}
-
-	// Find the user account for the node
-	for _, user := range d.Users {
-		for _, candidate := range user.RegisteredNodes {
-			if candidate.Address == nodeAddress {
-				session.login(nodeAddress)
-				return nil
-			}
-		}
+	if session.IsExpired(d.clock.Now()) {
+		return ErrSessionExpired
+	}
+	if session.BoundAddress != (common.Address{}) && session.BoundAddress != nodeAddress {
+		return fmt.Errorf("%w: expected [%s]", ErrSessionAddressMismatch, session.BoundAddress.Hex())
 	}
 
-	return ErrUnregisteredNode
+	// Make sure the node is registered
+	_, node := d.getUserForRegisteredNode(nodeAddress)
+	if node == nil {
+		return fmt.Errorf("%w: address [%s]", ErrUnregisteredNode, nodeAddress.Hex())
+	}
+	session.login(nodeAddress, d.clock.Now())
+	d.changes.markSession(session)
+	return nil
 }
 
 // Clones the database
@@ -165,6 +347,15 @@ func (d *Database) Clone() *Database {
 	for _, session := range d.Sessions {
 		clone.Sessions = append(clone.Sessions, session.Clone())
 	}
+
+	clone.NextValidatorIndex = d.NextValidatorIndex
+
+	// Copy the audit log
+	for _, entry := range d.AuditLog {
+		clone.AuditLog = append(clone.AuditLog, entry.Clone())
+	}
+	clone.clock = d.clock
+	clone.rebuildIndex()
 	return clone
 }
 
@@ -174,19 +365,30 @@ func (d *Database) Clone() *Database {
 
 // Get a node by address - returns true if registered, false if not registered and just whitelisted
 func (d *Database) GetNode(address common.Address) (*Node, bool) {
-	for _, user := range d.Users {
-		for _, candidate := range user.RegisteredNodes {
-			if candidate.Address == address {
-				return candidate, true
-			}
-		}
-		for _, candidate := range user.WhitelistedNodes {
-			if candidate.Address == address {
-				return candidate, false
-			}
-		}
+	entry, exists := d.index.nodes[address]
+	if !exists {
+		return nil, false
+	}
+	return entry.node, entry.registered
+}
+
+// Get a registered node and the user it belongs to by the node's address
+func (d *Database) getUserForRegisteredNode(address common.Address) (*User, *Node) {
+	entry, exists := d.index.nodes[address]
+	if !exists || !entry.registered {
+		return nil, nil
 	}
-	return nil, false
+	return entry.user, entry.node
+}
+
+// Get a registered node's validator by its network and pubkey, or nil if no registered node has uploaded it.
+// If more than one node uploaded the pubkey, this returns the validator of the first one.
+func (d *Database) GetValidator(network string, pubkey beacon.ValidatorPubkey) *Validator {
+	entries := d.index.getValidators(network, pubkey)
+	if len(entries) == 0 {
+		return nil
+	}
+	return entries[0].validator
 }
 
 // Get the StakeWise status of a validator
@@ -203,131 +405,152 @@ func (d *Database) GetStakeWiseVault(address common.Address, networkName string)
 	return nil
 }
 
-// Handle a new collection of deposit data uploads from a node
+// Handle a new collection of deposit data uploads from a node. The whole upload is validated first, so nothing is
+// added if any of it is invalid.
 func (d *Database) HandleDepositDataUpload(nodeAddress common.Address, data []beacon.ExtendedDepositData) error {
-	// Get the node
-	var node *Node
-	for _, user := range d.Users {
-		for _, candidate := range user.RegisteredNodes {
-			if candidate.Address == nodeAddress {
-				node = candidate
-				break
-			}
-		}
-		if node != nil {
-			break
-		}
-	}
-	if node == nil {
-		return fmt.Errorf("registered node with address [%s] not found", nodeAddress.Hex())
+	err := d.ValidateDepositDataUpload(nodeAddress, data)
+	if err != nil {
+		return err
 	}
 
 	// Add the deposit data
+	user, node := d.getUserForRegisteredNode(nodeAddress)
 	for _, depositData := range data {
 		vaultAddress := common.BytesToAddress(depositData.WithdrawalCredentials)
-		vaults, exists := d.StakeWiseVaults[depositData.NetworkName]
-		if !exists {
-			return fmt.Errorf("network [%s] not found in StakeWise vaults", depositData.NetworkName)
-		}
-		found := false
-		for _, vault := range vaults {
-			if vault.Address == vaultAddress {
-				found = true
-				node.AddDepositData(depositData, vaultAddress)
-				break
-			}
-		}
-		if !found {
-			return fmt.Errorf("vault with address [%s] not found", vaultAddress.Hex())
+		validator := node.AddDepositData(depositData, vaultAddress)
+		if validator.DepositDataUploadedTime.IsZero() {
+			validator.DepositDataUploadedTime = d.clock.Now()
 		}
+		d.index.addValidator(depositData.NetworkName, user, node, validator)
+		d.changes.markUser(user)
 	}
-
 	return nil
 }
 
-// Handle a new collection of signed exits from a node
-func (d *Database) HandleSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData) error {
+// Make sure a collection of deposit data uploads from a node can be handled, without changing anything. Problems with
+// individual deposit data are reported together in a BatchError.
+func (d *Database) ValidateDepositDataUpload(nodeAddress common.Address, data []beacon.ExtendedDepositData) error {
 	// Get the node
-	var node *Node
-	for _, user := range d.Users {
-		for _, candidate := range user.RegisteredNodes {
-			if candidate.Address == nodeAddress {
-				node = candidate
-				break
-			}
+	_, node := d.getUserForRegisteredNode(nodeAddress)
+	if node == nil {
+		return fmt.Errorf("%w: address [%s]", ErrUnregisteredNode, nodeAddress.Hex())
+	}
+
+	// Check each deposit data's fields and vault
+	var batchErr BatchError
+	for i, depositData := range data {
+		if len(depositData.PublicKey) != beacon.ValidatorPubkeyLength {
+			batchErr.Add(i, fmt.Errorf("%w: pubkey must be %d bytes but was %d", ErrInvalidPubkey, beacon.ValidatorPubkeyLength, len(depositData.PublicKey)))
+			continue
 		}
-		if node != nil {
-			break
+		if len(depositData.Signature) != beacon.ValidatorSignatureLength {
+			batchErr.Add(i, fmt.Errorf("%w: signature must be %d bytes but was %d", ErrInvalidDepositData, beacon.ValidatorSignatureLength, len(depositData.Signature)))
+			continue
+		}
+		if len(depositData.WithdrawalCredentials) != common.HashLength {
+			batchErr.Add(i, fmt.Errorf("%w: withdrawal credentials must be %d bytes but were %d", ErrInvalidDepositData, common.HashLength, len(depositData.WithdrawalCredentials)))
+			continue
+		}
+		vaultAddress := common.BytesToAddress(depositData.WithdrawalCredentials)
+		_, exists := d.StakeWiseVaults[depositData.NetworkName]
+		if !exists {
+			batchErr.Add(i, fmt.Errorf("%w: [%s]", ErrInvalidNetwork, depositData.NetworkName))
+			continue
+		}
+		if d.GetStakeWiseVault(vaultAddress, depositData.NetworkName) == nil {
+			batchErr.Add(i, fmt.Errorf("%w: address [%s]", ErrVaultNotFound, vaultAddress.Hex()))
 		}
 	}
-	if node == nil {
-		return fmt.Errorf("registered node with address [%s] not found", nodeAddress.Hex())
+	return batchErr.Err()
+}
+
+// Handle a new collection of signed exits from a node. The whole upload is validated first, so nothing is stored if
+// any of it is invalid.
+func (d *Database) HandleSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData) error {
+	err := d.ValidateSignedExitUpload(nodeAddress, network, data)
+	if err != nil {
+		return err
 	}
 
 	// Add the signed exits
+	user, node := d.getUserForRegisteredNode(nodeAddress)
 	for _, signedExit := range data {
+		pubkey, _ := beacon.HexToValidatorPubkey(signedExit.Pubkey)
+		validator := d.index.getNodeValidator(network, node, pubkey)
+		validator.SetExitMessage(signedExit.ExitMessage)
+		validator.ExitUploadedTime = d.clock.Now()
+		d.changes.markUser(user)
+	}
+	return nil
+}
+
+// Make sure a collection of signed exits from a node can be handled, without changing anything. Problems with
+// individual signed exits are reported together in a BatchError.
+func (d *Database) ValidateSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData) error {
+	// Get the node
+	_, node := d.getUserForRegisteredNode(nodeAddress)
+	if node == nil {
+		return fmt.Errorf("%w: address [%s]", ErrUnregisteredNode, nodeAddress.Hex())
+	}
+
+	// Check each signed exit's validator
+	var batchErr BatchError
+	_, networkUsed := node.Validators[network]
+	for i, signedExit := range data {
 		pubkey, err := beacon.HexToValidatorPubkey(signedExit.Pubkey)
 		if err != nil {
-			return fmt.Errorf("error parsing validator pubkey [%s]: %w", signedExit.Pubkey, err)
-		}
-
-		// Get the validator
-		validators, exists := node.Validators[network]
-		if !exists {
-			return fmt.Errorf("network [%s] is not used by node [%s]", network, nodeAddress.Hex())
+			batchErr.Add(i, fmt.Errorf("%w [%s]: %s", ErrInvalidPubkey, signedExit.Pubkey, err.Error()))
+			continue
 		}
-		found := false
-		for _, validator := range validators {
-			if validator.Pubkey == pubkey {
-				validator.SetExitMessage(signedExit.ExitMessage)
-				found = true
-				break
-			}
+		if !networkUsed {
+			batchErr.Add(i, fmt.Errorf("%w: [%s] is not used by node [%s]", ErrInvalidNetwork, network, nodeAddress.Hex()))
+			continue
 		}
-		if !found {
-			return fmt.Errorf("node [%s] doesn't have validator [%s]", nodeAddress.Hex(), pubkey.Hex())
+		if d.index.getNodeValidator(network, node, pubkey) == nil {
+			batchErr.Add(i, fmt.Errorf("%w: node [%s], validator [%s]", ErrValidatorNotFound, nodeAddress.Hex(), pubkey.Hex()))
 		}
-
 	}
-	return nil
+	return batchErr.Err()
 }
 
-// Create a new deposit data set
+// Create a new deposit data set using the default selection policy. At least one validator is taken from each user,
+// so a limit of 0 takes one per user like it always has.
 func (d *Database) CreateNewDepositDataSet(network string, validatorsPerUser int) []beacon.ExtendedDepositData {
-	depositData := []beacon.ExtendedDepositData{}
+	if validatorsPerUser < 1 {
+		validatorsPerUser = 1
+	}
+	params := SetSelectionParams{
+		ValidatorsPerUser: validatorsPerUser,
+	}
+	return d.CreateNewDepositDataSetWithPolicy(network, DefaultSetSelectionPolicy{}, params)
+}
 
-	// Iterate the users
+// Create a new deposit data set, using the policy to choose which validators go into it
+func (d *Database) CreateNewDepositDataSetWithPolicy(network string, policy SetSelectionPolicy, params SetSelectionParams) []beacon.ExtendedDepositData {
+	validators := policy.Select(d.Users, network, params)
+	depositData := make([]beacon.ExtendedDepositData, len(validators))
+	for i, validator := range validators {
+		depositData[i] = validator.DepositData
+	}
+	return depositData
+}
+
+// Get the number of validators on the network that haven't been used in a deposit data set yet
+func (d *Database) GetPendingValidatorCount(network string) int {
+	count := 0
 	for _, user := range d.Users {
-		userCount := 0
 		for _, node := range user.RegisteredNodes {
-			validatorsForNetwork, exists := node.Validators[network]
-			if !exists {
-				continue
-			}
-			for _, validator := range validatorsForNetwork {
-				// Add this deposit data if it hasn't been used
-				if !validator.DepositDataUsed {
-					depositData = append(depositData, validator.DepositData)
-					userCount++
-					if userCount >= validatorsPerUser {
-						break
-					}
-				}
-			}
-			if userCount >= validatorsPerUser {
-				break
-			}
+			count += len(getUnusedValidators(node, network))
 		}
 	}
-
-	return depositData
+	return count
 }
 
 // Call this to "upload" a deposit data set to StakeWise
 func (d *Database) UploadDepositDataToStakeWise(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error {
 	vaults, exists := d.StakeWiseVaults[network]
 	if !exists {
-		return fmt.Errorf("network [%s] not found in StakeWise vaults", network)
+		return fmt.Errorf("%w: [%s]", ErrInvalidNetwork, network)
 	}
 	var vault *StakeWiseVault
 	for _, candidate := range vaults {
@@ -337,13 +560,14 @@ func (d *Database) UploadDepositDataToStakeWise(vaultAddress common.Address, net
 		}
 	}
 	if vault == nil {
-		return fmt.Errorf("vault with address [%s] not found", vaultAddress.Hex())
+		return fmt.Errorf("%w: address [%s]", ErrVaultNotFound, vaultAddress.Hex())
 	}
 
 	for _, depositData := range data {
 		pubkey := beacon.ValidatorPubkey(depositData.PublicKey)
 		vault.MarkDepositDataUploaded(pubkey)
 	}
+	d.changes.markVault(network, vault)
 	return nil
 }
 
@@ -351,7 +575,7 @@ func (d *Database) UploadDepositDataToStakeWise(vaultAddress common.Address, net
 func (d *Database) MarkDepositDataSetUploaded(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error {
 	vaults, exists := d.StakeWiseVaults[network]
 	if !exists {
-		return fmt.Errorf("network [%s] not found in StakeWise vaults", network)
+		return fmt.Errorf("%w: [%s]", ErrInvalidNetwork, network)
 	}
 
 	var vault *StakeWiseVault
@@ -362,38 +586,46 @@ func (d *Database) MarkDepositDataSetUploaded(vaultAddress common.Address, netwo
 		}
 	}
 	if vault == nil {
-		return fmt.Errorf("vault with address [%s] not found", vaultAddress.Hex())
+		return fmt.Errorf("%w: address [%s]", ErrVaultNotFound, vaultAddress.Hex())
 	}
 
 	// Flag each deposit data as uploaded
 	for _, depositData := range data {
-		network := depositData.NetworkName
-		for _, user := range d.Users {
-			for _, node := range user.RegisteredNodes {
-				validators, exists := node.Validators[network]
-				if !exists {
-					continue
-				}
-				for _, validator := range validators {
-					if validator.Pubkey == beacon.ValidatorPubkey(depositData.PublicKey) {
-						validator.DepositData = depositData
-						validator.UseDepositData()
-					}
-				}
-			}
+		for _, entry := range d.index.getValidators(depositData.NetworkName, beacon.ValidatorPubkey(depositData.PublicKey)) {
+			entry.validator.DepositData = depositData
+			entry.validator.UseDepositData()
+			entry.validator.SetIncludedTime = d.clock.Now()
+			d.changes.markUser(entry.user)
 		}
 	}
 
-	// Increment the index
+	// Increment the index and record the set in the vault's history
 	vault.LatestDepositDataSet = data
 	vault.LatestDepositDataSetIndex++
+	vault.LatestDepositDataSetTime = d.clock.Now()
+	set := &DepositDataSet{
+		Version:     vault.LatestDepositDataSetIndex,
+		CreatedTime: vault.LatestDepositDataSetTime,
+		DepositData: make([]beacon.ExtendedDepositData, len(data)),
+	}
+	copy(set.DepositData, data)
+	vault.DepositDataSets = append(vault.DepositDataSets, set)
+	d.changes.markVault(network, vault)
 	return nil
 }
 
+// Flag the validators in a deposit data set as registered, adding them to the simulated Beacon Chain as active
+// validators starting at epoch 0
 func (d *Database) MarkValidatorsRegistered(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error {
+	return d.MarkValidatorsRegisteredAtEpoch(vaultAddress, network, data, 0)
+}
+
+// Flag the validators in a deposit data set as registered, adding them to the simulated Beacon Chain as active
+// validators starting at the provided epoch
+func (d *Database) MarkValidatorsRegisteredAtEpoch(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData, activationEpoch uint64) error {
 	vaults, exists := d.StakeWiseVaults[network]
 	if !exists {
-		return fmt.Errorf("network [%s] not found in StakeWise vaults", network)
+		return fmt.Errorf("%w: [%s]", ErrInvalidNetwork, network)
 	}
 
 	var vault *StakeWiseVault
@@ -404,26 +636,58 @@ func (d *Database) MarkValidatorsRegistered(vaultAddress common.Address, network
 		}
 	}
 	if vault == nil {
-		return fmt.Errorf("vault with address [%s] not found", vaultAddress.Hex())
+		return fmt.Errorf("%w: address [%s]", ErrVaultNotFound, vaultAddress.Hex())
 	}
 
 	// Flag each validator as registered
 	for _, depositData := range data {
-		network := depositData.NetworkName
-		for _, user := range d.Users {
-			for _, node := range user.RegisteredNodes {
-				validators, exists := node.Validators[network]
-				if !exists {
-					continue
-				}
-				for _, validator := range validators {
-					if validator.Pubkey == beacon.ValidatorPubkey(depositData.PublicKey) {
-						validator.MarkActive()
-					}
-				}
+		for _, entry := range d.index.getValidators(depositData.NetworkName, beacon.ValidatorPubkey(depositData.PublicKey)) {
+			validator := entry.validator
+			validator.MarkActive()
+			validator.ActivatedTime = d.clock.Now()
+			d.changes.markUser(entry.user)
+			if !validator.HasBeaconIndex {
+				validator.HasBeaconIndex = true
+				validator.BeaconIndex = d.NextValidatorIndex
+				validator.ActivationEpoch = activationEpoch
+				d.index.beaconValidators[validator.BeaconIndex] = validator
+				d.NextValidatorIndex++
+				d.changes.markNextValidatorIndex()
 			}
 		}
 	}
 
 	return nil
 }
+
+// Get all of the validators that have been added to the simulated Beacon Chain, ordered by index
+func (d *Database) GetBeaconValidators() []*Validator {
+	validators := make([]*Validator, 0, len(d.index.beaconValidators))
+	for _, validator := range d.index.beaconValidators {
+		validators = append(validators, validator)
+	}
+	sort.Slice(validators, func(i, j int) bool {
+		return validators[i].BeaconIndex < validators[j].BeaconIndex
+	})
+	return validators
+}
+
+// Get a validator on the simulated Beacon Chain by its index
+func (d *Database) GetBeaconValidator(index uint64) *Validator {
+	return d.index.beaconValidators[index]
+}
+
+// Process a voluntary exit for a validator on the simulated Beacon Chain
+func (d *Database) ExitBeaconValidator(index uint64, currentEpoch uint64, exitEpoch uint64, withdrawableEpoch uint64) error {
+	validator := d.GetBeaconValidator(index)
+	if validator == nil {
+		return ErrUnknownValidatorIndex
+	}
+	if validator.GetBeaconStatus(currentEpoch) != beacon.ValidatorState_ActiveOngoing {
+		return ErrValidatorNotActive
+	}
+	validator.ExitEpoch = exitEpoch
+	validator.WithdrawableEpoch = withdrawableEpoch
+	d.markValidatorChanged(validator.DepositData.NetworkName, validator.Pubkey)
+	return nil
+}
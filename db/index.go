@@ -0,0 +1,141 @@
+package db
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rocket-pool/node-manager-core/beacon"
+)
+
+// Lookup tables over the database's collections, so lookups don't have to scan every user, node, and validator.
+// They're derived from the exported collections: every Database method that changes those keeps them up to date,
+// and they're rebuilt from scratch when the database is cloned.
+type databaseIndex struct {
+	// Users by email
+	users map[string]*User
+
+	// Nodes by address, along with the user they belong to
+	nodes map[common.Address]*nodeIndexEntry
+
+	// Validators of registered nodes by network, then pubkey. More than one node can upload the same pubkey, so each
+	// one has its own entry, in upload order.
+	validators map[string]map[beacon.ValidatorPubkey][]*validatorIndexEntry
+
+	// Validators on the simulated Beacon Chain by index
+	beaconValidators map[uint64]*Validator
+
+	// Sessions by token and by nonce
+	sessionsByToken map[string]*Session
+	sessionsByNonce map[string]*Session
+}
+
+// A node in the index
+type nodeIndexEntry struct {
+	user       *User
+	node       *Node
+	registered bool
+}
+
+// A validator in the index
+type validatorIndexEntry struct {
+	user      *User
+	node      *Node
+	validator *Validator
+}
+
+// Creates a new, empty index
+func newDatabaseIndex() *databaseIndex {
+	return &databaseIndex{
+		users:            map[string]*User{},
+		nodes:            map[common.Address]*nodeIndexEntry{},
+		validators:       map[string]map[beacon.ValidatorPubkey][]*validatorIndexEntry{},
+		beaconValidators: map[uint64]*Validator{},
+		sessionsByToken:  map[string]*Session{},
+		sessionsByNonce:  map[string]*Session{},
+	}
+}
+
+// Rebuilds the index from the database's collections
+func (d *Database) rebuildIndex() {
+	index := newDatabaseIndex()
+	for _, user := range d.Users {
+		index.users[user.Email] = user
+		for _, node := range user.RegisteredNodes {
+			index.addNode(user, node, true)
+			for network, validators := range node.Validators {
+				for _, validator := range validators {
+					index.addValidator(network, user, node, validator)
+				}
+			}
+		}
+		for _, node := range user.WhitelistedNodes {
+			index.addNode(user, node, false)
+		}
+	}
+	for _, session := range d.Sessions {
+		index.addSession(session)
+	}
+	d.index = index
+}
+
+// Adds a node to the index. Registered nodes take precedence over whitelisted ones if the address is on more than one
+// user, otherwise the first user to add the node keeps it.
+func (i *databaseIndex) addNode(user *User, node *Node, registered bool) {
+	existing, exists := i.nodes[node.Address]
+	if exists && (existing.registered || !registered) {
+		return
+	}
+	i.nodes[node.Address] = &nodeIndexEntry{
+		user:       user,
+		node:       node,
+		registered: registered,
+	}
+}
+
+// Adds a node's validator to the index if it isn't already there
+func (i *databaseIndex) addValidator(network string, user *User, node *Node, validator *Validator) {
+	networkValidators, exists := i.validators[network]
+	if !exists {
+		networkValidators = map[beacon.ValidatorPubkey][]*validatorIndexEntry{}
+		i.validators[network] = networkValidators
+	}
+	if i.getNodeValidator(network, node, validator.Pubkey) == nil {
+		networkValidators[validator.Pubkey] = append(networkValidators[validator.Pubkey], &validatorIndexEntry{
+			user:      user,
+			node:      node,
+			validator: validator,
+		})
+	}
+	if validator.HasBeaconIndex {
+		i.beaconValidators[validator.BeaconIndex] = validator
+	}
+}
+
+// Gets every node's validator for a pubkey from the index, in upload order
+func (i *databaseIndex) getValidators(network string, pubkey beacon.ValidatorPubkey) []*validatorIndexEntry {
+	networkValidators, exists := i.validators[network]
+	if !exists {
+		return nil
+	}
+	return networkValidators[pubkey]
+}
+
+// Gets a specific node's validator for a pubkey from the index
+func (i *databaseIndex) getNodeValidator(network string, node *Node, pubkey beacon.ValidatorPubkey) *Validator {
+	for _, entry := range i.getValidators(network, pubkey) {
+		if entry.node == node {
+			return entry.validator
+		}
+	}
+	return nil
+}
+
+// Adds a session to the index
+func (i *databaseIndex) addSession(session *Session) {
+	i.sessionsByToken[session.Token] = session
+	i.sessionsByNonce[session.Nonce] = session
+}
+
+// Removes a session from the index
+func (i *databaseIndex) removeSession(session *Session) {
+	delete(i.sessionsByToken, session.Token)
+	delete(i.sessionsByNonce, session.Nonce)
+}
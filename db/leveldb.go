@@ -0,0 +1,626 @@
+package db
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/clock"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Key layout of the LevelDB store. The live state is under statePrefix, with one key per user (including its nodes
+// and validators), vault, session, and audit entry. Each snapshot is a copy of the state keys under its own prefix.
+const (
+	statePrefix         string = "state/"
+	metaKey             string = statePrefix + "meta"
+	userPrefix          string = statePrefix + "user/"
+	vaultPrefix         string = statePrefix + "vault/"
+	sessionPrefix       string = statePrefix + "session/"
+	auditPrefix         string = statePrefix + "audit/"
+	snapshotNamePrefix  string = "snapshot-names/"
+	snapshotStatePrefix string = "snapshots/"
+)
+
+// A store that keeps its state in an embedded LevelDB database on disk. It's loaded into memory when the store is
+// opened and serves reads from there; each change is written through to disk before the method that made it returns.
+type LevelDbStore struct {
+	// The in-memory copy of the state
+	database *Database
+
+	// The on-disk state
+	ldb *leveldb.DB
+
+	// The positions of users and vaults in the key space, which keep them in the order they were added
+	userPositions     map[*User]uint64
+	vaultPositions    map[*StakeWiseVault]uint64
+	nextUserPosition  uint64
+	nextVaultPosition uint64
+
+	logger *slog.Logger
+}
+
+// The store's record of the database's fields that aren't collections
+type storedMeta struct {
+	NextValidatorIndex uint64
+}
+
+// The store's record of a vault
+type storedVault struct {
+	Network string
+	Vault   *StakeWiseVault
+}
+
+// Opens the LevelDB store in the directory, creating it if it doesn't exist yet, and loads its state
+func OpenLevelDbStore(path string, logger *slog.Logger) (*LevelDbStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the LevelDB store requires a path")
+	}
+	ldb, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error opening LevelDB store at [%s]: %w", path, err)
+	}
+	s := &LevelDbStore{
+		ldb:    ldb,
+		logger: logger,
+	}
+	err = s.load(clock.NewClock())
+	if err != nil {
+		_ = ldb.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Set the clock used for the store's timestamps
+func (s *LevelDbStore) SetClock(newClock *clock.Clock) {
+	s.database.SetClock(newClock)
+}
+
+// Replace the store's state with the contents of a database
+func (s *LevelDbStore) SetDatabase(database *Database) error {
+	batch := new(leveldb.Batch)
+	err := s.deletePrefix(batch, statePrefix)
+	if err != nil {
+		return fmt.Errorf("error reading state: %w", err)
+	}
+	s.database = database
+	s.database.trackChanges()
+	s.resetPositions()
+	err = s.writeDatabase(batch)
+	if err != nil {
+		return err
+	}
+	return s.ldb.Write(batch, nil)
+}
+
+// Release the store's resources
+func (s *LevelDbStore) Close() error {
+	return s.ldb.Close()
+}
+
+// Get a copy of the store's state
+func (s *LevelDbStore) Clone() *Database {
+	return s.database.Clone()
+}
+
+// Take a snapshot of the store's state, replacing any existing snapshot with the same name
+func (s *LevelDbStore) TakeSnapshot(name string) error {
+	snapshotPrefix := getSnapshotPrefix(name)
+	batch := new(leveldb.Batch)
+	err := s.deletePrefix(batch, snapshotPrefix)
+	if err != nil {
+		return fmt.Errorf("error reading existing snapshot [%s]: %w", name, err)
+	}
+	iterator := s.ldb.NewIterator(util.BytesPrefix([]byte(statePrefix)), nil)
+	for iterator.Next() {
+		key := append([]byte(snapshotPrefix), iterator.Key()[len(statePrefix):]...)
+		batch.Put(key, bytes.Clone(iterator.Value()))
+	}
+	iterator.Release()
+	err = iterator.Error()
+	if err != nil {
+		return fmt.Errorf("error reading state for snapshot [%s]: %w", name, err)
+	}
+	batch.Put([]byte(snapshotNamePrefix+name), nil)
+	return s.ldb.Write(batch, nil)
+}
+
+// Revert the store's state to a snapshot
+func (s *LevelDbStore) RevertToSnapshot(name string) error {
+	exists, err := s.ldb.Has([]byte(snapshotNamePrefix+name), nil)
+	if err != nil {
+		return fmt.Errorf("error checking for snapshot [%s]: %w", name, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: [%s]", ErrSnapshotNotFound, name)
+	}
+
+	// Replace the state with the snapshot's copy
+	snapshotPrefix := getSnapshotPrefix(name)
+	batch := new(leveldb.Batch)
+	err = s.deletePrefix(batch, statePrefix)
+	if err != nil {
+		return fmt.Errorf("error reading state: %w", err)
+	}
+	iterator := s.ldb.NewIterator(util.BytesPrefix([]byte(snapshotPrefix)), nil)
+	for iterator.Next() {
+		key := append([]byte(statePrefix), iterator.Key()[len(snapshotPrefix):]...)
+		batch.Put(key, bytes.Clone(iterator.Value()))
+	}
+	iterator.Release()
+	err = iterator.Error()
+	if err != nil {
+		return fmt.Errorf("error reading snapshot [%s]: %w", name, err)
+	}
+	err = s.ldb.Write(batch, nil)
+	if err != nil {
+		return fmt.Errorf("error reverting to snapshot [%s]: %w", name, err)
+	}
+
+	// Reload it
+	return s.load(s.database.clock)
+}
+
+// Get the names of all of the snapshots that have been taken, in alphabetical order
+func (s *LevelDbStore) GetSnapshotNames() []string {
+	names := []string{}
+	iterator := s.ldb.NewIterator(util.BytesPrefix([]byte(snapshotNamePrefix)), nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		names = append(names, string(iterator.Key()[len(snapshotNamePrefix):]))
+	}
+	err := iterator.Error()
+	if err != nil {
+		s.logger.Error("Error reading snapshot names from the LevelDB store", "error", err)
+	}
+	return names
+}
+
+// ==================
+// === Vaults ===
+// ==================
+
+// Adds a StakeWise vault
+func (s *LevelDbStore) AddStakeWiseVault(address common.Address, networkName string) error {
+	return s.write(s.database.AddStakeWiseVault(address, networkName))
+}
+
+// Gets a StakeWise vault, or nil if it doesn't exist
+func (s *LevelDbStore) GetStakeWiseVault(address common.Address, networkName string) *StakeWiseVault {
+	return s.database.GetStakeWiseVault(address, networkName)
+}
+
+// Gets all of the StakeWise vaults by network
+func (s *LevelDbStore) GetStakeWiseVaults() map[string][]*StakeWiseVault {
+	return s.database.GetStakeWiseVaults()
+}
+
+// ========================
+// === Users and Nodes ===
+// ========================
+
+// Adds a user
+func (s *LevelDbStore) AddUser(email string) error {
+	return s.write(s.database.AddUser(email))
+}
+
+// Gets all of the users
+func (s *LevelDbStore) GetUsers() []*User {
+	return s.database.GetUsers()
+}
+
+// Whitelists a node with a user
+func (s *LevelDbStore) WhitelistNodeAccount(email string, nodeAddress common.Address) error {
+	return s.write(s.database.WhitelistNodeAccount(email, nodeAddress))
+}
+
+// Registers a node with a user
+func (s *LevelDbStore) RegisterNodeAccount(email string, nodeAddress common.Address) error {
+	return s.write(s.database.RegisterNodeAccount(email, nodeAddress))
+}
+
+// Sets the max number of Constellation minipools a user's nodes can have on each deployment (0 for no limit)
+func (s *LevelDbStore) SetMinipoolLimit(email string, limit int) error {
+	return s.write(s.database.SetMinipoolLimit(email, limit))
+}
+
+// Gets a node by address - returns true if registered, false if just whitelisted
+func (s *LevelDbStore) GetNode(address common.Address) (*Node, bool) {
+	return s.database.GetNode(address)
+}
+
+// Records a Constellation whitelist signature for a registered node
+func (s *LevelDbStore) WhitelistNodeForConstellation(nodeAddress common.Address, deployment string, signature []byte) error {
+	return s.write(s.database.WhitelistNodeForConstellation(nodeAddress, deployment, signature))
+}
+
+// Records a Constellation minipool deposit signature for a registered node
+func (s *LevelDbStore) AddMinipool(nodeAddress common.Address, deployment string, minipoolAddress common.Address, salt *big.Int, signature []byte) error {
+	return s.write(s.database.AddMinipool(nodeAddress, deployment, minipoolAddress, salt, signature))
+}
+
+// ==================
+// === Validators ===
+// ==================
+
+// Gets a registered node's validator by its network and pubkey, or nil if no registered node has uploaded it
+func (s *LevelDbStore) GetValidator(network string, pubkey beacon.ValidatorPubkey) *Validator {
+	return s.database.GetValidator(network, pubkey)
+}
+
+// Handles a new collection of deposit data uploads from a node
+func (s *LevelDbStore) HandleDepositDataUpload(nodeAddress common.Address, data []beacon.ExtendedDepositData) error {
+	return s.write(s.database.HandleDepositDataUpload(nodeAddress, data))
+}
+
+// Makes sure a collection of deposit data uploads from a node can be handled, without changing anything
+func (s *LevelDbStore) ValidateDepositDataUpload(nodeAddress common.Address, data []beacon.ExtendedDepositData) error {
+	return s.database.ValidateDepositDataUpload(nodeAddress, data)
+}
+
+// Handles a new collection of signed exits from a node
+func (s *LevelDbStore) HandleSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData) error {
+	return s.write(s.database.HandleSignedExitUpload(nodeAddress, network, data))
+}
+
+// Makes sure a collection of signed exits from a node can be handled, without changing anything
+func (s *LevelDbStore) ValidateSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData) error {
+	return s.database.ValidateSignedExitUpload(nodeAddress, network, data)
+}
+
+// Creates a new deposit data set with up to the provided number of validators per user
+func (s *LevelDbStore) CreateNewDepositDataSet(network string, validatorsPerUser int) []beacon.ExtendedDepositData {
+	return s.database.CreateNewDepositDataSet(network, validatorsPerUser)
+}
+
+// Creates a new deposit data set, using the policy to choose which validators go into it
+func (s *LevelDbStore) CreateNewDepositDataSetWithPolicy(network string, policy SetSelectionPolicy, params SetSelectionParams) []beacon.ExtendedDepositData {
+	return s.database.CreateNewDepositDataSetWithPolicy(network, policy, params)
+}
+
+// Gets the number of validators on the network that haven't been used in a deposit data set yet
+func (s *LevelDbStore) GetPendingValidatorCount(network string) int {
+	return s.database.GetPendingValidatorCount(network)
+}
+
+// "Uploads" a deposit data set to a StakeWise vault
+func (s *LevelDbStore) UploadDepositDataToStakeWise(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error {
+	return s.write(s.database.UploadDepositDataToStakeWise(vaultAddress, network, data))
+}
+
+// Marks a deposit data set as uploaded to a StakeWise vault
+func (s *LevelDbStore) MarkDepositDataSetUploaded(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error {
+	return s.write(s.database.MarkDepositDataSetUploaded(vaultAddress, network, data))
+}
+
+// Marks the validators in a deposit data set as registered, adding them to the simulated Beacon Chain at epoch 0
+func (s *LevelDbStore) MarkValidatorsRegistered(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error {
+	return s.write(s.database.MarkValidatorsRegistered(vaultAddress, network, data))
+}
+
+// Marks the validators in a deposit data set as registered, adding them to the simulated Beacon Chain at the provided
+// epoch
+func (s *LevelDbStore) MarkValidatorsRegisteredAtEpoch(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData, activationEpoch uint64) error {
+	return s.write(s.database.MarkValidatorsRegisteredAtEpoch(vaultAddress, network, data, activationEpoch))
+}
+
+// Gets the validators on the simulated Beacon Chain, ordered by index
+func (s *LevelDbStore) GetBeaconValidators() []*Validator {
+	return s.database.GetBeaconValidators()
+}
+
+// Gets a validator on the simulated Beacon Chain by its index, or nil if it doesn't exist
+func (s *LevelDbStore) GetBeaconValidator(index uint64) *Validator {
+	return s.database.GetBeaconValidator(index)
+}
+
+// Exits a validator on the simulated Beacon Chain
+func (s *LevelDbStore) ExitBeaconValidator(index uint64, currentEpoch uint64, exitEpoch uint64, withdrawableEpoch uint64) error {
+	return s.write(s.database.ExitBeaconValidator(index, currentEpoch, exitEpoch, withdrawableEpoch))
+}
+
+// ================
+// === Sessions ===
+// ================
+
+// Creates a new session
+func (s *LevelDbStore) CreateSession() *Session {
+	session := s.database.CreateSession()
+	s.logWriteError(s.flush())
+	return session
+}
+
+// Creates a new session for a client, optionally bound to a node address and expiring at the provided time
+func (s *LevelDbStore) CreateClientSession(clientId string, boundAddress common.Address, expiresTime time.Time, maxOutstanding int) (*Session, error) {
+	session, err := s.database.CreateClientSession(clientId, boundAddress, expiresTime, maxOutstanding)
+	return session, s.write(err)
+}
+
+// Revokes all of a node's logged in sessions other than the one with the provided token, returning how many were
+// revoked
+func (s *LevelDbStore) RevokeSessions(nodeAddress common.Address, keepToken string) int {
+	revoked := s.database.RevokeSessions(nodeAddress, keepToken)
+	s.logWriteError(s.flush())
+	return revoked
+}
+
+// Gets a session by its nonce
+func (s *LevelDbStore) GetSessionByNonce(nonce string) *Session {
+	return s.database.GetSessionByNonce(nonce)
+}
+
+// Gets a session by its token
+func (s *LevelDbStore) GetSessionByToken(token string) *Session {
+	return s.database.GetSessionByToken(token)
+}
+
+// Logs a node in with a session's nonce
+func (s *LevelDbStore) Login(nodeAddress common.Address, nonce string) error {
+	return s.write(s.database.Login(nodeAddress, nonce))
+}
+
+// =================
+// === Audit Log ===
+// =================
+
+// Adds an entry to the audit log
+func (s *LevelDbStore) AddAuditEntry(entry AuditEntry) {
+	s.database.AddAuditEntry(entry)
+	s.logWriteError(s.flush())
+}
+
+// Gets the audit log, oldest first
+func (s *LevelDbStore) GetAuditLog() []*AuditEntry {
+	return s.database.GetAuditLog()
+}
+
+// ==========================
+// === Internal Functions ===
+// ==========================
+
+// Writes the changes made by an operation to disk, even if the operation failed partway through, and returns the
+// operation's error along with any error from writing
+func (s *LevelDbStore) write(err error) error {
+	return errors.Join(err, s.flush())
+}
+
+// Logs an error from writing changes to disk for operations that can't return one
+func (s *LevelDbStore) logWriteError(err error) {
+	if err != nil {
+		s.logger.Error("Error writing changes to the LevelDB store", "error", err)
+	}
+}
+
+// Writes the database's changes since the last flush to disk
+func (s *LevelDbStore) flush() error {
+	changes := s.database.takeChanges()
+	if changes.isEmpty() {
+		return nil
+	}
+
+	batch := new(leveldb.Batch)
+	for user := range changes.users {
+		err := s.putUser(batch, user)
+		if err != nil {
+			return err
+		}
+	}
+	for vault, network := range changes.vaults {
+		err := s.putVault(batch, network, vault)
+		if err != nil {
+			return err
+		}
+	}
+	for session, exists := range changes.sessions {
+		if !exists {
+			batch.Delete([]byte(sessionPrefix + session.Token))
+			continue
+		}
+		err := putValue(batch, sessionPrefix+session.Token, session)
+		if err != nil {
+			return err
+		}
+	}
+	for _, index := range changes.auditEntries {
+		err := putValue(batch, getPositionKey(auditPrefix, uint64(index)), s.database.AuditLog[index])
+		if err != nil {
+			return err
+		}
+	}
+	if changes.nextValidatorIndex {
+		err := s.putMeta(batch)
+		if err != nil {
+			return err
+		}
+	}
+	return s.ldb.Write(batch, nil)
+}
+
+// Writes the whole database to a batch
+func (s *LevelDbStore) writeDatabase(batch *leveldb.Batch) error {
+	for _, user := range s.database.Users {
+		err := s.putUser(batch, user)
+		if err != nil {
+			return err
+		}
+	}
+	networks := make([]string, 0, len(s.database.StakeWiseVaults))
+	for network := range s.database.StakeWiseVaults {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+	for _, network := range networks {
+		for _, vault := range s.database.StakeWiseVaults[network] {
+			err := s.putVault(batch, network, vault)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, session := range s.database.Sessions {
+		err := putValue(batch, sessionPrefix+session.Token, session)
+		if err != nil {
+			return err
+		}
+	}
+	for i, entry := range s.database.AuditLog {
+		err := putValue(batch, getPositionKey(auditPrefix, uint64(i)), entry)
+		if err != nil {
+			return err
+		}
+	}
+	return s.putMeta(batch)
+}
+
+// Loads the state from disk into memory
+func (s *LevelDbStore) load(clock *clock.Clock) error {
+	database := NewDatabase(s.logger)
+	database.SetClock(clock)
+	s.database = database
+	s.resetPositions()
+
+	// Read each record
+	iterator := s.ldb.NewIterator(util.BytesPrefix([]byte(statePrefix)), nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		key := string(iterator.Key())
+		value := iterator.Value()
+		var err error
+		switch {
+		case key == metaKey:
+			var meta storedMeta
+			err = getValue(value, &meta)
+			database.NextValidatorIndex = meta.NextValidatorIndex
+
+		case strings.HasPrefix(key, userPrefix):
+			user := &User{}
+			err = getValue(value, user)
+			user.normalize()
+			database.Users = append(database.Users, user)
+			s.userPositions[user] = s.nextUserPosition
+			s.nextUserPosition++
+
+		case strings.HasPrefix(key, vaultPrefix):
+			var record storedVault
+			err = getValue(value, &record)
+			record.Vault.normalize()
+			database.StakeWiseVaults[record.Network] = append(database.StakeWiseVaults[record.Network], record.Vault)
+			s.vaultPositions[record.Vault] = s.nextVaultPosition
+			s.nextVaultPosition++
+
+		case strings.HasPrefix(key, sessionPrefix):
+			session := &Session{}
+			err = getValue(value, session)
+			database.Sessions = append(database.Sessions, session)
+
+		case strings.HasPrefix(key, auditPrefix):
+			entry := &AuditEntry{}
+			err = getValue(value, entry)
+			database.AuditLog = append(database.AuditLog, entry)
+		}
+		if err != nil {
+			return fmt.Errorf("error loading [%s] from the LevelDB store: %w", key, err)
+		}
+	}
+	err := iterator.Error()
+	if err != nil {
+		return fmt.Errorf("error reading the LevelDB store: %w", err)
+	}
+
+	// Sessions are keyed by token, so put them back in the order they were created
+	sort.SliceStable(database.Sessions, func(i, j int) bool {
+		return database.Sessions[i].CreatedTime.Before(database.Sessions[j].CreatedTime)
+	})
+	database.rebuildIndex()
+	database.trackChanges()
+	return nil
+}
+
+// Clears the positions of users and vaults
+func (s *LevelDbStore) resetPositions() {
+	s.userPositions = map[*User]uint64{}
+	s.vaultPositions = map[*StakeWiseVault]uint64{}
+	s.nextUserPosition = 0
+	s.nextVaultPosition = 0
+}
+
+// Adds a user to a batch, giving it the next position if it's new
+func (s *LevelDbStore) putUser(batch *leveldb.Batch, user *User) error {
+	position, exists := s.userPositions[user]
+	if !exists {
+		position = s.nextUserPosition
+		s.userPositions[user] = position
+		s.nextUserPosition++
+	}
+	return putValue(batch, getPositionKey(userPrefix, position), user)
+}
+
+// Adds a vault to a batch, giving it the next position if it's new
+func (s *LevelDbStore) putVault(batch *leveldb.Batch, network string, vault *StakeWiseVault) error {
+	position, exists := s.vaultPositions[vault]
+	if !exists {
+		position = s.nextVaultPosition
+		s.vaultPositions[vault] = position
+		s.nextVaultPosition++
+	}
+	return putValue(batch, getPositionKey(vaultPrefix, position), storedVault{
+		Network: network,
+		Vault:   vault,
+	})
+}
+
+// Adds the database's fields that aren't collections to a batch
+func (s *LevelDbStore) putMeta(batch *leveldb.Batch) error {
+	return putValue(batch, metaKey, storedMeta{
+		NextValidatorIndex: s.database.NextValidatorIndex,
+	})
+}
+
+// Adds deletions for every key with the prefix to a batch
+func (s *LevelDbStore) deletePrefix(batch *leveldb.Batch, prefix string) error {
+	iterator := s.ldb.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		batch.Delete(bytes.Clone(iterator.Key()))
+	}
+	return iterator.Error()
+}
+
+// Gets the prefix of a snapshot's copy of the state. The name is hex encoded so snapshots with names that share a
+// prefix can't overlap.
+func getSnapshotPrefix(name string) string {
+	return snapshotStatePrefix + hex.EncodeToString([]byte(name)) + "/"
+}
+
+// Gets the key for a record at a position, which sorts in position order
+func getPositionKey(prefix string, position uint64) string {
+	return fmt.Sprintf("%s%016x", prefix, position)
+}
+
+// Serializes a value and adds it to a batch
+func putValue(batch *leveldb.Batch, key string, value any) error {
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(value)
+	if err != nil {
+		return fmt.Errorf("error serializing [%s]: %w", key, err)
+	}
+	batch.Put([]byte(key), buffer.Bytes())
+	return nil
+}
+
+// Deserializes a value
+func getValue(data []byte, value any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rocket-pool/node-manager-core/beacon"
+)
+
+// Gets the Merkle root of a deposit data set the way StakeWise does when a set is uploaded to a vault. Each validator
+// is a leaf of an OpenZeppelin standard Merkle tree built from its pubkey, signature, and deposit data root along with
+// its index in the set, so the root changes if the set's contents or order change. Empty sets have an empty root.
+func GetDepositDataMerkleRoot(data []beacon.ExtendedDepositData) common.Hash {
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+
+	// Hash the leaves and sort them, like StandardMerkleTree does
+	leaves := make([][]byte, len(data))
+	for i, depositData := range data {
+		leaves[i] = getDepositDataLeaf(depositData, i)
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i], leaves[j]) < 0
+	})
+
+	// Lay the tree out as an array with the leaves at the end in reverse order, then hash the nodes up to the root
+	tree := make([][]byte, 2*len(leaves)-1)
+	for i, leaf := range leaves {
+		tree[len(tree)-1-i] = leaf
+	}
+	for i := len(tree) - 1 - len(leaves); i >= 0; i-- {
+		tree[i] = hashMerklePair(tree[2*i+1], tree[2*i+2])
+	}
+	return common.BytesToHash(tree[0])
+}
+
+// Gets the leaf hash for a validator in a deposit data set: keccak256(keccak256(abi.encode(bytes, uint256))), where
+// the bytes are the validator's pubkey, signature, and deposit data root and the uint256 is its index in the set
+func getDepositDataLeaf(depositData beacon.ExtendedDepositData, index int) []byte {
+	validator := make([]byte, 0, len(depositData.PublicKey)+len(depositData.Signature)+len(depositData.DepositDataRoot))
+	validator = append(validator, depositData.PublicKey...)
+	validator = append(validator, depositData.Signature...)
+	validator = append(validator, depositData.DepositDataRoot...)
+
+	// The head is the offset of the dynamic bytes and the index, and the tail is the length-prefixed, padded bytes
+	paddedLength := (len(validator) + 31) / 32 * 32
+	encoded := make([]byte, 0, 96+paddedLength)
+	encoded = append(encoded, common.LeftPadBytes(big.NewInt(64).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(big.NewInt(int64(index)).Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(big.NewInt(int64(len(validator))).Bytes(), 32)...)
+	encoded = append(encoded, common.RightPadBytes(validator, paddedLength)...)
+	return crypto.Keccak256(crypto.Keccak256(encoded))
+}
+
+// Hashes a pair of Merkle tree nodes in sorted order
+func hashMerklePair(a []byte, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256(a, b)
+}
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/rocket-pool/node-manager-core/beacon"
 )
@@ -8,16 +10,28 @@ import (
 type Node struct {
 	Address    common.Address
 	Validators map[string][]*Validator
+
+	// Constellation info, keyed by deployment
+	Constellation map[string]*ConstellationNodeInfo
+
+	// When the node was whitelisted, which is when it was created
+	CreatedTime time.Time
+
+	// When the node was registered, or zero if it hasn't been yet
+	RegisteredTime time.Time
 }
 
 func newNode(address common.Address) *Node {
 	return &Node{
-		Address:    address,
-		Validators: map[string][]*Validator{},
+		Address:       address,
+		Validators:    map[string][]*Validator{},
+		Constellation: map[string]*ConstellationNodeInfo{},
 	}
 }
 
-func (n *Node) AddDepositData(depositData beacon.ExtendedDepositData, vaultAddress common.Address) {
+// Adds a validator for the deposit data to the node if it doesn't already have one, and returns the node's validator
+// for the deposit data's pubkey
+func (n *Node) AddDepositData(depositData beacon.ExtendedDepositData, vaultAddress common.Address) *Validator {
 	validatorsForNetwork, exists := n.Validators[depositData.NetworkName]
 	if !exists {
 		validatorsForNetwork = []*Validator{}
@@ -28,17 +42,29 @@ func (n *Node) AddDepositData(depositData beacon.ExtendedDepositData, vaultAddre
 	for _, validator := range validatorsForNetwork {
 		if validator.Pubkey == pubkey {
 			// Already present
-			return
+			return validator
 		}
 	}
 
 	validator := newValidator(depositData, vaultAddress)
 	validatorsForNetwork = append(validatorsForNetwork, validator)
 	n.Validators[depositData.NetworkName] = validatorsForNetwork
+	return validator
+}
+
+// Gets the number of Constellation minipools the node has on a deployment
+func (n *Node) GetMinipoolCount(deployment string) int {
+	info, exists := n.Constellation[deployment]
+	if !exists {
+		return 0
+	}
+	return len(info.Minipools)
 }
 
 func (n *Node) Clone() *Node {
 	clone := newNode(n.Address)
+	clone.CreatedTime = n.CreatedTime
+	clone.RegisteredTime = n.RegisteredTime
 	for network, validatorsForNetwork := range n.Validators {
 		cloneSlice := make([]*Validator, len(validatorsForNetwork))
 		for i, validator := range validatorsForNetwork {
@@ -46,5 +72,18 @@ func (n *Node) Clone() *Node {
 		}
 		clone.Validators[network] = cloneSlice
 	}
+	for deployment, info := range n.Constellation {
+		clone.Constellation[deployment] = info.Clone()
+	}
 	return clone
 }
+
+// Replaces collections left nil by deserialization with empty ones
+func (n *Node) normalize() {
+	if n.Validators == nil {
+		n.Validators = map[string][]*Validator{}
+	}
+	if n.Constellation == nil {
+		n.Constellation = map[string]*ConstellationNodeInfo{}
+	}
+}
@@ -0,0 +1,281 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// Takes the first unused validators from each user's nodes, in the order users were added
+	SetSelectionPolicy_Default string = "default"
+
+	// Takes one unused validator from each node in turn until no more can be taken
+	SetSelectionPolicy_RoundRobin string = "round-robin"
+
+	// Takes one unused validator from each user in turn, oldest users first, until no more can be taken
+	SetSelectionPolicy_FairShare string = "fair-share"
+
+	// Like the default policy, but requires a global max set size
+	SetSelectionPolicy_MaxSetSize string = "max-set-size"
+
+	// Like the default policy, but requires a per-node cap
+	SetSelectionPolicy_PerNode string = "per-node"
+)
+
+// Limits applied when selecting validators for a new deposit data set. A limit of 0 means no limit.
+type SetSelectionParams struct {
+	// The max number of validators to take from each user
+	ValidatorsPerUser int
+
+	// The max number of validators to take from each node
+	ValidatorsPerNode int
+
+	// The max number of validators in the set
+	MaxSetSize int
+}
+
+// Chooses which unused validators go into a new deposit data set
+type SetSelectionPolicy interface {
+	// Checks that the params have everything the policy needs
+	Validate(params SetSelectionParams) error
+
+	// Selects validators for the network from the users, which are provided in the order they were added.
+	// Every limit in the params that is set must be respected.
+	Select(users []*User, network string, params SetSelectionParams) []*Validator
+}
+
+// Gets one of the built-in set selection policies by name
+func GetSetSelectionPolicy(name string) (SetSelectionPolicy, error) {
+	switch name {
+	case SetSelectionPolicy_Default, "":
+		return DefaultSetSelectionPolicy{}, nil
+	case SetSelectionPolicy_RoundRobin:
+		return RoundRobinSetSelectionPolicy{}, nil
+	case SetSelectionPolicy_FairShare:
+		return FairShareSetSelectionPolicy{}, nil
+	case SetSelectionPolicy_MaxSetSize:
+		return MaxSetSizeSetSelectionPolicy{}, nil
+	case SetSelectionPolicy_PerNode:
+		return PerNodeSetSelectionPolicy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown set selection policy [%s]", name)
+	}
+}
+
+// ================
+// === Policies ===
+// ================
+
+// Takes the first unused validators from each user's nodes, in the order users were added
+type DefaultSetSelectionPolicy struct{}
+
+func (p DefaultSetSelectionPolicy) Validate(params SetSelectionParams) error {
+	return nil
+}
+
+func (p DefaultSetSelectionPolicy) Select(users []*User, network string, params SetSelectionParams) []*Validator {
+	state := newSelectionState(params)
+	for _, user := range users {
+		for _, node := range user.RegisteredNodes {
+			for _, validator := range getUnusedValidators(node, network) {
+				if state.isFull() || !state.canTake(user, node) {
+					break
+				}
+				state.take(user, node, validator)
+			}
+		}
+	}
+	return state.selected
+}
+
+// Takes one unused validator from each node in turn until no more can be taken
+type RoundRobinSetSelectionPolicy struct{}
+
+func (p RoundRobinSetSelectionPolicy) Validate(params SetSelectionParams) error {
+	return nil
+}
+
+func (p RoundRobinSetSelectionPolicy) Select(users []*User, network string, params SetSelectionParams) []*Validator {
+	// Get the unused validators for every node
+	type nodeQueue struct {
+		user       *User
+		node       *Node
+		validators []*Validator
+	}
+	queues := []*nodeQueue{}
+	for _, user := range users {
+		for _, node := range user.RegisteredNodes {
+			queues = append(queues, &nodeQueue{
+				user:       user,
+				node:       node,
+				validators: getUnusedValidators(node, network),
+			})
+		}
+	}
+
+	// Take one from each node per round
+	state := newSelectionState(params)
+	for progress := true; progress && !state.isFull(); {
+		progress = false
+		for _, queue := range queues {
+			if state.isFull() {
+				break
+			}
+			if len(queue.validators) == 0 || !state.canTake(queue.user, queue.node) {
+				continue
+			}
+			state.take(queue.user, queue.node, queue.validators[0])
+			queue.validators = queue.validators[1:]
+			progress = true
+		}
+	}
+	return state.selected
+}
+
+// Takes one unused validator from each user in turn, oldest users first, until no more can be taken
+type FairShareSetSelectionPolicy struct{}
+
+func (p FairShareSetSelectionPolicy) Validate(params SetSelectionParams) error {
+	return nil
+}
+
+func (p FairShareSetSelectionPolicy) Select(users []*User, network string, params SetSelectionParams) []*Validator {
+	// Sort the users by age
+	sortedUsers := getUsersByAge(users)
+
+	// Get the unused validators for every user
+	type userQueue struct {
+		user       *User
+		nodes      []*Node
+		validators []*Validator
+	}
+	queues := make([]*userQueue, len(sortedUsers))
+	for i, user := range sortedUsers {
+		queue := &userQueue{
+			user: user,
+		}
+		for _, node := range user.RegisteredNodes {
+			for _, validator := range getUnusedValidators(node, network) {
+				queue.nodes = append(queue.nodes, node)
+				queue.validators = append(queue.validators, validator)
+			}
+		}
+		queues[i] = queue
+	}
+
+	// Take one from each user per round
+	state := newSelectionState(params)
+	for progress := true; progress && !state.isFull(); {
+		progress = false
+		for _, queue := range queues {
+			if state.isFull() {
+				break
+			}
+			for i, validator := range queue.validators {
+				node := queue.nodes[i]
+				if !state.canTake(queue.user, node) {
+					continue
+				}
+				state.take(queue.user, node, validator)
+				queue.nodes = append(queue.nodes[:i], queue.nodes[i+1:]...)
+				queue.validators = append(queue.validators[:i], queue.validators[i+1:]...)
+				progress = true
+				break
+			}
+		}
+	}
+	return state.selected
+}
+
+// Like the default policy, but requires a global max set size
+type MaxSetSizeSetSelectionPolicy struct{}
+
+func (p MaxSetSizeSetSelectionPolicy) Validate(params SetSelectionParams) error {
+	if params.MaxSetSize < 1 {
+		return fmt.Errorf("the %s policy requires a max set size", SetSelectionPolicy_MaxSetSize)
+	}
+	return nil
+}
+
+func (p MaxSetSizeSetSelectionPolicy) Select(users []*User, network string, params SetSelectionParams) []*Validator {
+	return DefaultSetSelectionPolicy{}.Select(users, network, params)
+}
+
+// Like the default policy, but requires a per-node cap
+type PerNodeSetSelectionPolicy struct{}
+
+func (p PerNodeSetSelectionPolicy) Validate(params SetSelectionParams) error {
+	if params.ValidatorsPerNode < 1 {
+		return fmt.Errorf("the %s policy requires a per-node limit", SetSelectionPolicy_PerNode)
+	}
+	return nil
+}
+
+func (p PerNodeSetSelectionPolicy) Select(users []*User, network string, params SetSelectionParams) []*Validator {
+	return DefaultSetSelectionPolicy{}.Select(users, network, params)
+}
+
+// ===============
+// === Helpers ===
+// ===============
+
+// Tracks the validators selected so far and enforces the selection limits
+type selectionState struct {
+	params     SetSelectionParams
+	userCounts map[*User]int
+	nodeCounts map[*Node]int
+	selected   []*Validator
+}
+
+func newSelectionState(params SetSelectionParams) *selectionState {
+	return &selectionState{
+		params:     params,
+		userCounts: map[*User]int{},
+		nodeCounts: map[*Node]int{},
+		selected:   []*Validator{},
+	}
+}
+
+// Checks if another validator can be taken from the user's node
+func (s *selectionState) canTake(user *User, node *Node) bool {
+	if s.params.ValidatorsPerUser > 0 && s.userCounts[user] >= s.params.ValidatorsPerUser {
+		return false
+	}
+	if s.params.ValidatorsPerNode > 0 && s.nodeCounts[node] >= s.params.ValidatorsPerNode {
+		return false
+	}
+	return true
+}
+
+// Checks if the set has reached its max size
+func (s *selectionState) isFull() bool {
+	return s.params.MaxSetSize > 0 && len(s.selected) >= s.params.MaxSetSize
+}
+
+// Adds a validator to the set
+func (s *selectionState) take(user *User, node *Node, validator *Validator) {
+	s.userCounts[user]++
+	s.nodeCounts[node]++
+	s.selected = append(s.selected, validator)
+}
+
+// Gets the validators for a node on the network that haven't been used in a deposit data set yet
+func getUnusedValidators(node *Node, network string) []*Validator {
+	unused := []*Validator{}
+	for _, validator := range node.Validators[network] {
+		if !validator.DepositDataUsed {
+			unused = append(unused, validator)
+		}
+	}
+	return unused
+}
+
+// Gets a copy of the users sorted by age, oldest first. Users added at the same time keep the order they were added in.
+func getUsersByAge(users []*User) []*User {
+	sortedUsers := make([]*User, len(users))
+	copy(sortedUsers, users)
+	sort.SliceStable(sortedUsers, func(i, j int) bool {
+		return sortedUsers[i].CreatedTime.Before(sortedUsers[j].CreatedTime)
+	})
+	return sortedUsers
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"crypto/md5"
 	"errors"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/uuid"
@@ -10,7 +11,10 @@ import (
 )
 
 var (
-	ErrUnregisteredNode error = errors.New("node hasn't been registered with the NodeSet server yet")
+	ErrUnregisteredNode       error = errors.New("node hasn't been registered with the NodeSet server yet")
+	ErrSessionExpired         error = errors.New("session nonce has expired")
+	ErrSessionAddressMismatch error = errors.New("session nonce was issued to a different node address")
+	ErrTooManyNonces          error = errors.New("client has too many outstanding nonces")
 )
 
 // An authorization session for access to the API
@@ -24,12 +28,27 @@ type Session struct {
 	// The address of the node that requested this session
 	NodeAddress common.Address
 
+	// The address the nonce was issued to, or the zero address if any node can use it
+	BoundAddress common.Address
+
+	// The ID of the client that requested the nonce, used to limit how many it can have outstanding
+	ClientId string
+
 	// Whether or not the user for the session has logged in
 	IsLoggedIn bool
+
+	// When the session was created
+	CreatedTime time.Time
+
+	// When the session was logged in, or zero if it hasn't been yet
+	LoginTime time.Time
+
+	// When the nonce expires if it hasn't been used to log in, or zero if it never does
+	ExpiresTime time.Time
 }
 
 // Creates a new session
-func newSession() *Session {
+func newSession(createdTime time.Time) *Session {
 	// Make a random UUID for the session token
 	token := uuid.New()
 
@@ -37,22 +56,34 @@ func newSession() *Session {
 	nonce := md5.Sum(token[:])
 
 	return &Session{
-		Nonce:      utils.EncodeHexWithPrefix(nonce[:]),
-		Token:      token.String(),
-		IsLoggedIn: false,
+		Nonce:       utils.EncodeHexWithPrefix(nonce[:]),
+		Token:       token.String(),
+		IsLoggedIn:  false,
+		CreatedTime: createdTime,
 	}
 }
 
-func (s *Session) login(nodeAddress common.Address) {
+// Check if the session's nonce can no longer be used to log in at the provided time
+func (s *Session) IsExpired(now time.Time) bool {
+	return !s.IsLoggedIn && !s.ExpiresTime.IsZero() && !now.Before(s.ExpiresTime)
+}
+
+func (s *Session) login(nodeAddress common.Address, loginTime time.Time) {
 	s.NodeAddress = nodeAddress
 	s.IsLoggedIn = true
+	s.LoginTime = loginTime
 }
 
 func (s *Session) Clone() *Session {
 	return &Session{
-		Nonce:       s.Nonce,
-		Token:       s.Token,
-		NodeAddress: s.NodeAddress,
-		IsLoggedIn:  s.IsLoggedIn,
+		Nonce:        s.Nonce,
+		Token:        s.Token,
+		NodeAddress:  s.NodeAddress,
+		BoundAddress: s.BoundAddress,
+		ClientId:     s.ClientId,
+		IsLoggedIn:   s.IsLoggedIn,
+		CreatedTime:  s.CreatedTime,
+		LoginTime:    s.LoginTime,
+		ExpiresTime:  s.ExpiresTime,
 	}
 }
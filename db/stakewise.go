@@ -1,6 +1,8 @@
 package db
 
 import (
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/rocket-pool/node-manager-core/beacon"
 )
@@ -18,6 +20,27 @@ type StakeWiseVault struct {
 
 	// Latest deposit data set uploaded to StakeWise
 	LatestDepositDataSet []beacon.ExtendedDepositData
+
+	// When the vault was added
+	CreatedTime time.Time
+
+	// When the latest deposit data set was uploaded to StakeWise, or zero if there hasn't been one yet
+	LatestDepositDataSetTime time.Time
+
+	// Every deposit data set uploaded to StakeWise, oldest first
+	DepositDataSets []*DepositDataSet
+}
+
+// A deposit data set that was uploaded to a StakeWise vault
+type DepositDataSet struct {
+	// The set's version, which matches the vault's deposit data set index after it was uploaded
+	Version int
+
+	// When the set was uploaded to StakeWise
+	CreatedTime time.Time
+
+	// The deposit data in the set
+	DepositData []beacon.ExtendedDepositData
 }
 
 func NewStakeWiseVaultInfo(address common.Address) *StakeWiseVault {
@@ -26,6 +49,7 @@ func NewStakeWiseVaultInfo(address common.Address) *StakeWiseVault {
 		UploadedData:              map[beacon.ValidatorPubkey]bool{},
 		LatestDepositDataSet:      []beacon.ExtendedDepositData{},
 		LatestDepositDataSetIndex: 0,
+		DepositDataSets:           []*DepositDataSet{},
 	}
 }
 
@@ -33,13 +57,65 @@ func (v *StakeWiseVault) MarkDepositDataUploaded(pubkey beacon.ValidatorPubkey)
 	v.UploadedData[pubkey] = true
 }
 
+// Gets the deposit data set with the provided version, or nil if the vault doesn't have it
+func (v *StakeWiseVault) GetDepositDataSet(version int) *DepositDataSet {
+	for _, set := range v.DepositDataSets {
+		if set.Version == version {
+			return set
+		}
+	}
+	return nil
+}
+
 func (v *StakeWiseVault) Clone() *StakeWiseVault {
 	clone := NewStakeWiseVaultInfo(v.Address)
 	clone.LatestDepositDataSetIndex = v.LatestDepositDataSetIndex
+	clone.CreatedTime = v.CreatedTime
+	clone.LatestDepositDataSetTime = v.LatestDepositDataSetTime
 	clone.LatestDepositDataSet = make([]beacon.ExtendedDepositData, len(v.LatestDepositDataSet))
 	copy(clone.LatestDepositDataSet, v.LatestDepositDataSet)
 	for pubkey, uploaded := range v.UploadedData {
 		clone.UploadedData[pubkey] = uploaded
 	}
+	for _, set := range v.DepositDataSets {
+		clone.DepositDataSets = append(clone.DepositDataSets, set.Clone())
+	}
+	return clone
+}
+
+// Replaces collections left nil by deserialization with empty ones
+func (v *StakeWiseVault) normalize() {
+	if v.UploadedData == nil {
+		v.UploadedData = map[beacon.ValidatorPubkey]bool{}
+	}
+	if v.LatestDepositDataSet == nil {
+		v.LatestDepositDataSet = []beacon.ExtendedDepositData{}
+	}
+	if v.DepositDataSets == nil {
+		v.DepositDataSets = []*DepositDataSet{}
+	}
+	for _, set := range v.DepositDataSets {
+		if set.DepositData == nil {
+			set.DepositData = []beacon.ExtendedDepositData{}
+		}
+	}
+}
+
+// Gets the pubkeys of the validators in the set, in order
+func (s *DepositDataSet) GetPubkeys() []beacon.ValidatorPubkey {
+	pubkeys := make([]beacon.ValidatorPubkey, len(s.DepositData))
+	for i, depositData := range s.DepositData {
+		pubkeys[i] = beacon.ValidatorPubkey(depositData.PublicKey)
+	}
+	return pubkeys
+}
+
+func (s *DepositDataSet) Clone() *DepositDataSet {
+	clone := &DepositDataSet{
+		Version:     s.Version,
+		CreatedTime: s.CreatedTime,
+		DepositData: make([]beacon.ExtendedDepositData, len(s.DepositData)),
+	}
+	copy(clone.DepositData, s.DepositData)
 	return clone
 }
@@ -0,0 +1,226 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/clock"
+	"github.com/rocket-pool/node-manager-core/beacon"
+)
+
+const (
+	// Keeps the mock's state in memory, so it's lost when the mock stops
+	StoreType_Memory string = "memory"
+
+	// Keeps the mock's state in an embedded LevelDB database on disk, so it survives restarts
+	StoreType_LevelDb string = "leveldb"
+)
+
+var (
+	ErrSnapshotNotFound error = errors.New("snapshot not found")
+	ErrUnknownStoreType error = errors.New("unknown store type")
+)
+
+// Storage backend for the mock's users, nodes, validators, vaults, sessions, and audit log. The manager talks to
+// its state through this, so it can be kept in memory or on disk.
+//
+// Objects returned by a store belong to it and must only be changed through the store's methods.
+type Store interface {
+	// Set the clock used for the store's timestamps
+	SetClock(newClock *clock.Clock)
+
+	// Replace the store's state with the contents of a database
+	SetDatabase(database *Database) error
+
+	// Release the store's resources. It can't be used afterwards.
+	Close() error
+
+	// Take a snapshot of the store's state, replacing any existing snapshot with the same name
+	TakeSnapshot(name string) error
+
+	// Revert the store's state to a snapshot
+	RevertToSnapshot(name string) error
+
+	// Get the names of all of the snapshots that have been taken, in alphabetical order
+	GetSnapshotNames() []string
+
+	// Get a copy of the store's state, which can be restored later with SetDatabase
+	Clone() *Database
+
+	// Adds a StakeWise vault
+	AddStakeWiseVault(address common.Address, networkName string) error
+
+	// Gets a StakeWise vault, or nil if it doesn't exist
+	GetStakeWiseVault(address common.Address, networkName string) *StakeWiseVault
+
+	// Gets all of the StakeWise vaults by network
+	GetStakeWiseVaults() map[string][]*StakeWiseVault
+
+	// Adds a user
+	AddUser(email string) error
+
+	// Gets all of the users
+	GetUsers() []*User
+
+	// Whitelists a node with a user
+	WhitelistNodeAccount(email string, nodeAddress common.Address) error
+
+	// Registers a node with a user
+	RegisterNodeAccount(email string, nodeAddress common.Address) error
+
+	// Sets the max number of Constellation minipools a user's nodes can have on each deployment (0 for no limit)
+	SetMinipoolLimit(email string, limit int) error
+
+	// Gets a node by address - returns true if registered, false if just whitelisted
+	GetNode(address common.Address) (*Node, bool)
+
+	// Records a Constellation whitelist signature for a registered node
+	WhitelistNodeForConstellation(nodeAddress common.Address, deployment string, signature []byte) error
+
+	// Records a Constellation minipool deposit signature for a registered node
+	AddMinipool(nodeAddress common.Address, deployment string, minipoolAddress common.Address, salt *big.Int, signature []byte) error
+
+	// Gets a registered node's validator by its network and pubkey, or nil if no registered node has uploaded it
+	GetValidator(network string, pubkey beacon.ValidatorPubkey) *Validator
+
+	// Handles a new collection of deposit data uploads from a node. Nothing is added if any of it is invalid.
+	HandleDepositDataUpload(nodeAddress common.Address, data []beacon.ExtendedDepositData) error
+
+	// Makes sure a collection of deposit data uploads from a node can be handled, without changing anything
+	ValidateDepositDataUpload(nodeAddress common.Address, data []beacon.ExtendedDepositData) error
+
+	// Handles a new collection of signed exits from a node. Nothing is stored if any of them are invalid.
+	HandleSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData) error
+
+	// Makes sure a collection of signed exits from a node can be handled, without changing anything
+	ValidateSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData) error
+
+	// Creates a new deposit data set with up to the provided number of validators per user
+	CreateNewDepositDataSet(network string, validatorsPerUser int) []beacon.ExtendedDepositData
+
+	// Creates a new deposit data set, using the policy to choose which validators go into it
+	CreateNewDepositDataSetWithPolicy(network string, policy SetSelectionPolicy, params SetSelectionParams) []beacon.ExtendedDepositData
+
+	// Gets the number of validators on the network that haven't been used in a deposit data set yet
+	GetPendingValidatorCount(network string) int
+
+	// "Uploads" a deposit data set to a StakeWise vault
+	UploadDepositDataToStakeWise(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error
+
+	// Marks a deposit data set as uploaded to a StakeWise vault
+	MarkDepositDataSetUploaded(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error
+
+	// Marks the validators in a deposit data set as registered, adding them to the simulated Beacon Chain at epoch 0
+	MarkValidatorsRegistered(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error
+
+	// Marks the validators in a deposit data set as registered, adding them to the simulated Beacon Chain at the
+	// provided epoch
+	MarkValidatorsRegisteredAtEpoch(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData, activationEpoch uint64) error
+
+	// Gets the validators on the simulated Beacon Chain, ordered by index
+	GetBeaconValidators() []*Validator
+
+	// Gets a validator on the simulated Beacon Chain by its index, or nil if it doesn't exist
+	GetBeaconValidator(index uint64) *Validator
+
+	// Exits a validator on the simulated Beacon Chain
+	ExitBeaconValidator(index uint64, currentEpoch uint64, exitEpoch uint64, withdrawableEpoch uint64) error
+
+	// Creates a new session
+	CreateSession() *Session
+
+	// Creates a new session for a client, optionally bound to a node address and expiring at the provided time
+	CreateClientSession(clientId string, boundAddress common.Address, expiresTime time.Time, maxOutstanding int) (*Session, error)
+
+	// Revokes all of a node's logged in sessions other than the one with the provided token, returning how many were
+	// revoked
+	RevokeSessions(nodeAddress common.Address, keepToken string) int
+
+	// Gets a session by its nonce
+	GetSessionByNonce(nonce string) *Session
+
+	// Gets a session by its token
+	GetSessionByToken(token string) *Session
+
+	// Logs a node in with a session's nonce
+	Login(nodeAddress common.Address, nonce string) error
+
+	// Adds an entry to the audit log
+	AddAuditEntry(entry AuditEntry)
+
+	// Gets the audit log, oldest first
+	GetAuditLog() []*AuditEntry
+}
+
+// Opens a store of the provided type. The path is the store's directory on disk, and is ignored for in-memory stores.
+func OpenStore(storeType string, path string, logger *slog.Logger) (Store, error) {
+	switch storeType {
+	case StoreType_Memory:
+		return NewMemoryStore(logger), nil
+	case StoreType_LevelDb:
+		return OpenLevelDbStore(path, logger)
+	default:
+		return nil, fmt.Errorf("%w: [%s]", ErrUnknownStoreType, storeType)
+	}
+}
+
+// A store that keeps its state in memory. Snapshots are clones of the database.
+type MemoryStore struct {
+	*Database
+	snapshots map[string]*Database
+}
+
+// Creates a new, empty in-memory store
+func NewMemoryStore(logger *slog.Logger) *MemoryStore {
+	return &MemoryStore{
+		Database:  NewDatabase(logger),
+		snapshots: map[string]*Database{},
+	}
+}
+
+// Replace the store's state with the contents of a database
+func (s *MemoryStore) SetDatabase(database *Database) error {
+	s.Database = database
+	return nil
+}
+
+// Release the store's resources
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// Take a snapshot of the store's state, replacing any existing snapshot with the same name
+func (s *MemoryStore) TakeSnapshot(name string) error {
+	s.snapshots[name] = s.Database.Clone()
+	return nil
+}
+
+// Revert the store's state to a snapshot
+func (s *MemoryStore) RevertToSnapshot(name string) error {
+	snapshot, exists := s.snapshots[name]
+	if !exists {
+		return fmt.Errorf("%w: [%s]", ErrSnapshotNotFound, name)
+	}
+
+	// Revert to a copy so the snapshot itself stays intact for later reverts
+	clock := s.Database.clock
+	s.Database = snapshot.Clone()
+	s.Database.SetClock(clock)
+	return nil
+}
+
+// Get the names of all of the snapshots that have been taken, in alphabetical order
+func (s *MemoryStore) GetSnapshotNames() []string {
+	names := make([]string, 0, len(s.snapshots))
+	for name := range s.snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"errors"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -15,29 +16,38 @@ type User struct {
 	Email            string
 	WhitelistedNodes []*Node
 	RegisteredNodes  []*Node
+
+	// The max number of Constellation minipools the user's nodes can have on each deployment (0 for no limit)
+	MinipoolLimit int
+
+	// When the user was added
+	CreatedTime time.Time
 }
 
-func newUser(email string) *User {
+func newUser(email string, createdTime time.Time) *User {
 	return &User{
 		Email:            email,
 		WhitelistedNodes: []*Node{},
 		RegisteredNodes:  []*Node{},
+		CreatedTime:      createdTime,
 	}
 }
 
-func (u *User) WhitelistNode(nodeAddress common.Address) {
+// Whitelists a node for the user if it isn't already, and returns the user's node with the address
+func (u *User) WhitelistNode(nodeAddress common.Address) *Node {
 	for _, node := range u.RegisteredNodes {
 		if node.Address == nodeAddress {
-			return
+			return node
 		}
 	}
 	for _, node := range u.WhitelistedNodes {
 		if node.Address == nodeAddress {
-			return
+			return node
 		}
 	}
 	node := newNode(nodeAddress)
 	u.WhitelistedNodes = append(u.WhitelistedNodes, node)
+	return node
 }
 
 func (u *User) RegisterNode(nodeAddress common.Address) error {
@@ -59,7 +69,8 @@ func (u *User) RegisterNode(nodeAddress common.Address) error {
 }
 
 func (u *User) Clone() *User {
-	clone := newUser(u.Email)
+	clone := newUser(u.Email, u.CreatedTime)
+	clone.MinipoolLimit = u.MinipoolLimit
 	clone.WhitelistedNodes = make([]*Node, len(u.WhitelistedNodes))
 	clone.RegisteredNodes = make([]*Node, len(u.RegisteredNodes))
 	for i, node := range u.WhitelistedNodes {
@@ -70,3 +81,19 @@ func (u *User) Clone() *User {
 	}
 	return clone
 }
+
+// Replaces collections left nil by deserialization with empty ones
+func (u *User) normalize() {
+	if u.WhitelistedNodes == nil {
+		u.WhitelistedNodes = []*Node{}
+	}
+	if u.RegisteredNodes == nil {
+		u.RegisteredNodes = []*Node{}
+	}
+	for _, node := range u.WhitelistedNodes {
+		node.normalize()
+	}
+	for _, node := range u.RegisteredNodes {
+		node.normalize()
+	}
+}
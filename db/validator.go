@@ -1,11 +1,19 @@
 package db
 
 import (
+	"math"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
 	"github.com/rocket-pool/node-manager-core/beacon"
 )
 
+const (
+	// The epoch used by the Beacon Chain for events that haven't been scheduled yet
+	FarFutureEpoch uint64 = math.MaxUint64
+)
+
 type Validator struct {
 	Pubkey              beacon.ValidatorPubkey
 	VaultAddress        common.Address
@@ -14,13 +22,30 @@ type Validator struct {
 	ExitMessageUploaded bool
 	DepositDataUsed     bool
 	MarkedActive        bool
+
+	// When the deposit data was uploaded, the validator was included in a deposit data set, it was activated,
+	// and its signed exit was uploaded. These are zero until each happens.
+	DepositDataUploadedTime time.Time
+	SetIncludedTime         time.Time
+	ActivatedTime           time.Time
+	ExitUploadedTime        time.Time
+
+	// Simulated Beacon Chain state, assigned once the validator has been registered
+	HasBeaconIndex    bool
+	BeaconIndex       uint64
+	ActivationEpoch   uint64
+	ExitEpoch         uint64
+	WithdrawableEpoch uint64
 }
 
 func newValidator(depositData beacon.ExtendedDepositData, vaultAddress common.Address) *Validator {
 	return &Validator{
-		Pubkey:       beacon.ValidatorPubkey(depositData.PublicKey),
-		VaultAddress: vaultAddress,
-		DepositData:  depositData,
+		Pubkey:            beacon.ValidatorPubkey(depositData.PublicKey),
+		VaultAddress:      vaultAddress,
+		DepositData:       depositData,
+		ActivationEpoch:   FarFutureEpoch,
+		ExitEpoch:         FarFutureEpoch,
+		WithdrawableEpoch: FarFutureEpoch,
 	}
 }
 
@@ -38,14 +63,45 @@ func (v *Validator) SetExitMessage(exitMessage api.ExitMessage) {
 	v.ExitMessageUploaded = true
 }
 
+// Get the validator's status on the simulated Beacon Chain at the given epoch
+func (v *Validator) GetBeaconStatus(epoch uint64) beacon.ValidatorState {
+	switch {
+	case epoch < v.ActivationEpoch:
+		return beacon.ValidatorState_PendingQueued
+	case epoch < v.ExitEpoch:
+		if v.ExitEpoch == FarFutureEpoch {
+			return beacon.ValidatorState_ActiveOngoing
+		}
+		return beacon.ValidatorState_ActiveExiting
+	case epoch < v.WithdrawableEpoch:
+		return beacon.ValidatorState_ExitedUnslashed
+	default:
+		return beacon.ValidatorState_WithdrawalPossible
+	}
+}
+
+// Check if the validator has exited the simulated Beacon Chain by the given epoch
+func (v *Validator) IsExited(epoch uint64) bool {
+	return v.HasBeaconIndex && epoch >= v.ExitEpoch
+}
+
 func (v *Validator) Clone() *Validator {
 	return &Validator{
-		Pubkey:              v.Pubkey,
-		VaultAddress:        v.VaultAddress,
-		DepositData:         v.DepositData,
-		SignedExit:          v.SignedExit,
-		ExitMessageUploaded: v.ExitMessageUploaded,
-		DepositDataUsed:     v.DepositDataUsed,
-		MarkedActive:        v.MarkedActive,
+		Pubkey:                  v.Pubkey,
+		VaultAddress:            v.VaultAddress,
+		DepositData:             v.DepositData,
+		SignedExit:              v.SignedExit,
+		ExitMessageUploaded:     v.ExitMessageUploaded,
+		DepositDataUsed:         v.DepositDataUsed,
+		MarkedActive:            v.MarkedActive,
+		DepositDataUploadedTime: v.DepositDataUploadedTime,
+		SetIncludedTime:         v.SetIncludedTime,
+		ActivatedTime:           v.ActivatedTime,
+		ExitUploadedTime:        v.ExitUploadedTime,
+		HasBeaconIndex:          v.HasBeaconIndex,
+		BeaconIndex:             v.BeaconIndex,
+		ActivationEpoch:         v.ActivationEpoch,
+		ExitEpoch:               v.ExitEpoch,
+		WithdrawableEpoch:       v.WithdrawableEpoch,
 	}
 }
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+)
+
+const (
+	benchUserCount          int = 100
+	benchNodesPerUser       int = 10
+	benchValidatorsPerNode  int = 100
+	benchDepositDataSetSize int = 10
+)
+
+// A large database shared by the benchmarks, since building it takes a while
+var benchFleet *fleet
+
+// A database with a large fleet of nodes and validators
+type fleet struct {
+	database    *db.Database
+	lastNode    common.Address
+	lastSession *db.Session
+	depositData []beacon.ExtendedDepositData
+}
+
+func BenchmarkGetNode(b *testing.B) {
+	fleet := getBenchFleet(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		node, registered := fleet.database.GetNode(fleet.lastNode)
+		if node == nil || !registered {
+			b.Fatalf("node [%s] not found", fleet.lastNode.Hex())
+		}
+	}
+}
+
+func BenchmarkGetSessionByToken(b *testing.B) {
+	fleet := getBenchFleet(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if fleet.database.GetSessionByToken(fleet.lastSession.Token) == nil {
+			b.Fatalf("session not found")
+		}
+	}
+}
+
+func BenchmarkGetValidator(b *testing.B) {
+	fleet := getBenchFleet(b)
+	pubkey := beacon.ValidatorPubkey(fleet.depositData[len(fleet.depositData)-1].PublicKey)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if fleet.database.GetValidator(test.Network, pubkey) == nil {
+			b.Fatalf("validator [%s] not found", pubkey.HexWithPrefix())
+		}
+	}
+}
+
+func BenchmarkHandleDepositDataUpload(b *testing.B) {
+	fleet := getBenchFleet(b)
+	data := fleet.depositData[len(fleet.depositData)-benchDepositDataSetSize:]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := fleet.database.HandleDepositDataUpload(fleet.lastNode, data)
+		if err != nil {
+			b.Fatalf("error uploading deposit data: %v", err)
+		}
+	}
+}
+
+func BenchmarkHandleSignedExitUpload(b *testing.B) {
+	fleet := getBenchFleet(b)
+	data := make([]api.ExitData, benchDepositDataSetSize)
+	for i, depositData := range fleet.depositData[len(fleet.depositData)-benchDepositDataSetSize:] {
+		data[i] = api.ExitData{
+			Pubkey: beacon.ValidatorPubkey(depositData.PublicKey).HexWithPrefix(),
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := fleet.database.HandleSignedExitUpload(fleet.lastNode, test.Network, data)
+		if err != nil {
+			b.Fatalf("error uploading signed exits: %v", err)
+		}
+	}
+}
+
+func BenchmarkMarkDepositDataSetUploaded(b *testing.B) {
+	fleet := getBenchFleet(b)
+	data := fleet.depositData[len(fleet.depositData)-benchDepositDataSetSize:]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := fleet.database.MarkDepositDataSetUploaded(test.StakeWiseVaultAddress, test.Network, data)
+		if err != nil {
+			b.Fatalf("error marking deposit data set uploaded: %v", err)
+		}
+	}
+}
+
+// ==========================
+// === Internal Functions ===
+// ==========================
+
+// Get the shared benchmark fleet, building it if it hasn't been built yet
+func getBenchFleet(b *testing.B) *fleet {
+	if benchFleet != nil {
+		return benchFleet
+	}
+
+	database := db.NewDatabase(slog.Default())
+	err := database.AddStakeWiseVault(test.StakeWiseVaultAddress, test.Network)
+	if err != nil {
+		b.Fatalf("Error adding StakeWise vault to database: %v", err)
+	}
+
+	fleet := &fleet{
+		database:    database,
+		depositData: make([]beacon.ExtendedDepositData, 0, benchUserCount*benchNodesPerUser*benchValidatorsPerNode),
+	}
+	for i := 0; i < benchUserCount; i++ {
+		email := fmt.Sprintf("user%d@test.com", i)
+		err := database.AddUser(email)
+		if err != nil {
+			b.Fatalf("Error adding user [%s] to database: %v", email, err)
+		}
+		for j := 0; j < benchNodesPerUser; j++ {
+			nodeAddress := common.BigToAddress(big.NewInt(int64(i*benchNodesPerUser + j + 1)))
+			err = database.WhitelistNodeAccount(email, nodeAddress)
+			if err != nil {
+				b.Fatalf("Error whitelisting node [%s]: %v", nodeAddress.Hex(), err)
+			}
+			err = database.RegisterNodeAccount(email, nodeAddress)
+			if err != nil {
+				b.Fatalf("Error registering node [%s]: %v", nodeAddress.Hex(), err)
+			}
+			session := database.CreateSession()
+			err = database.Login(nodeAddress, session.Nonce)
+			if err != nil {
+				b.Fatalf("Error logging in node [%s]: %v", nodeAddress.Hex(), err)
+			}
+
+			// Synthesize the deposit data, since generating real BLS keys for this many validators is too slow
+			depositData := make([]beacon.ExtendedDepositData, benchValidatorsPerNode)
+			for k := range depositData {
+				pubkey := make([]byte, beacon.ValidatorPubkeyLength)
+				binary.BigEndian.PutUint64(pubkey, uint64(len(fleet.depositData)+k+1))
+				depositData[k] = beacon.ExtendedDepositData{
+					PublicKey:             pubkey,
+					WithdrawalCredentials: common.LeftPadBytes(test.StakeWiseVaultAddress.Bytes(), 32),
+					Amount:                test.DepositAmount,
+					NetworkName:           test.Network,
+				}
+			}
+			err = database.HandleDepositDataUpload(nodeAddress, depositData)
+			if err != nil {
+				b.Fatalf("Error uploading deposit data for node [%s]: %v", nodeAddress.Hex(), err)
+			}
+			fleet.depositData = append(fleet.depositData, depositData...)
+			fleet.lastNode = nodeAddress
+			fleet.lastSession = session
+		}
+	}
+
+	benchFleet = fleet
+	return fleet
+}
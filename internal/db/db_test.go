@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"testing"
 
+	"github.com/nodeset-org/nodeset-svc-mock/api"
 	"github.com/nodeset-org/nodeset-svc-mock/db"
 	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
 	"github.com/rocket-pool/node-manager-core/beacon"
@@ -65,6 +66,36 @@ func TestDatabaseClone(t *testing.T) {
 	t.Log("Clone wasn't updated, as expected")
 }
 
+// Make sure a clone's lookups return its own copies, and stay in sync with later changes
+func TestDatabaseCloneIndex(t *testing.T) {
+	// Set up a database and clone it
+	logger := slog.Default()
+	db := ProvisionFullDatabase(t, logger, false)
+	clone := db.Clone()
+	user1 := clone.Users[1]
+	node0 := user1.RegisteredNodes[0]
+	validator := node0.Validators[test.Network][0]
+
+	// Lookups on the clone should return the clone's objects
+	node, registered := clone.GetNode(node0.Address)
+	assert.True(t, registered)
+	assert.Same(t, node0, node)
+	assert.Same(t, validator, clone.GetValidator(test.Network, validator.Pubkey))
+	assert.Same(t, clone.Sessions[0], clone.GetSessionByToken(db.Sessions[0].Token))
+	assert.Same(t, clone.Sessions[0], clone.GetSessionByNonce(db.Sessions[0].Nonce))
+	t.Log("Clone lookups returned the clone's objects")
+
+	// Changes to the clone should be visible through its lookups, but not the original's
+	err := clone.HandleSignedExitUpload(node0.Address, test.Network, []api.ExitData{GenerateSignedExit(t, 0)})
+	assert.NoError(t, err)
+	assert.True(t, clone.GetValidator(test.Network, validator.Pubkey).ExitMessageUploaded)
+	assert.False(t, db.GetValidator(test.Network, validator.Pubkey).ExitMessageUploaded)
+	session := clone.CreateSession()
+	assert.Same(t, session, clone.GetSessionByToken(session.Token))
+	assert.Nil(t, db.GetSessionByToken(session.Token))
+	t.Log("Clone changes were isolated from the original")
+}
+
 // ==========================
 // === Internal Functions ===
 // ==========================
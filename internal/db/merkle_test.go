@@ -0,0 +1,65 @@
+package db
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure deposit data set roots match an OpenZeppelin standard Merkle tree of the set's validators
+func TestDepositDataMerkleRoot(t *testing.T) {
+	set := []beacon.ExtendedDepositData{
+		GenerateDepositData(t, 0, test.StakeWiseVaultAddress),
+		GenerateDepositData(t, 1, test.StakeWiseVaultAddress),
+		GenerateDepositData(t, 2, test.StakeWiseVaultAddress),
+	}
+
+	// Empty sets have an empty root, and single validator sets have the validator's leaf as the root
+	require.Equal(t, common.Hash{}, db.GetDepositDataMerkleRoot(nil))
+	require.Equal(t, common.BytesToHash(getLeaf(t, set[0], 0)), db.GetDepositDataMerkleRoot(set[:1]))
+
+	// Build the tree for the full set by hand: the leaves sorted, with the two smallest hashed together first
+	leaves := [][]byte{}
+	for i, depositData := range set {
+		leaves = append(leaves, getLeaf(t, depositData, i))
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i], leaves[j]) < 0
+	})
+	expected := hashPair(hashPair(leaves[1], leaves[0]), leaves[2])
+	require.Equal(t, common.BytesToHash(expected), db.GetDepositDataMerkleRoot(set))
+
+	// The root should depend on the order of the set
+	reordered := []beacon.ExtendedDepositData{set[1], set[0], set[2]}
+	require.NotEqual(t, db.GetDepositDataMerkleRoot(set), db.GetDepositDataMerkleRoot(reordered))
+}
+
+// Get a validator's leaf hash with the ABI encoder
+func getLeaf(t *testing.T, depositData beacon.ExtendedDepositData, index int) []byte {
+	bytesType, err := abi.NewType("bytes", "", nil)
+	require.NoError(t, err)
+	uintType, err := abi.NewType("uint256", "", nil)
+	require.NoError(t, err)
+	arguments := abi.Arguments{{Type: bytesType}, {Type: uintType}}
+	validator := append(append(append([]byte{}, depositData.PublicKey...), depositData.Signature...), depositData.DepositDataRoot...)
+	encoded, err := arguments.Pack(validator, big.NewInt(int64(index)))
+	require.NoError(t, err)
+	return crypto.Keccak256(crypto.Keccak256(encoded))
+}
+
+// Hash a pair of tree nodes in sorted order
+func hashPair(a []byte, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256(a, b)
+}
@@ -23,7 +23,7 @@ var (
 )
 
 // Create a full database for testing
-func ProvisionFullDatabase(t *testing.T, logger *slog.Logger, includeDepositDataSet bool) *db.Database {
+func ProvisionFullDatabase(t testing.TB, logger *slog.Logger, includeDepositDataSet bool) *db.Database {
 	db := db.NewDatabase(logger)
 
 	// Add a StakeWise vault to the database
@@ -104,7 +104,7 @@ func ProvisionFullDatabase(t *testing.T, logger *slog.Logger, includeDepositData
 // ==========================
 
 // Add a user to the database
-func addUserToDatabase(t *testing.T, db *db.Database, userEmail string) {
+func addUserToDatabase(t testing.TB, db *db.Database, userEmail string) {
 	err := db.AddUser(userEmail)
 	if err != nil {
 		t.Fatalf("Error adding user [%s] to database: %v", userEmail, err)
@@ -112,7 +112,7 @@ func addUserToDatabase(t *testing.T, db *db.Database, userEmail string) {
 }
 
 // Create a node, register it with the user, and log it in with a new session
-func createNodeAndAddToDatabase(t *testing.T, db *db.Database, userEmail string, index uint) common.Address {
+func createNodeAndAddToDatabase(t testing.TB, db *db.Database, userEmail string, index uint) common.Address {
 	nodeKey, exists := NodeKeys[index]
 	if !exists {
 		var err error
@@ -146,7 +146,7 @@ func createNodeAndAddToDatabase(t *testing.T, db *db.Database, userEmail string,
 }
 
 // Generate a validator private key and deposit data for the given index
-func GenerateDepositData(t *testing.T, index uint, withdrawalAddress common.Address) beacon.ExtendedDepositData {
+func GenerateDepositData(t testing.TB, index uint, withdrawalAddress common.Address) beacon.ExtendedDepositData {
 	validatorKey, exists := BeaconKeys[index]
 	if !exists {
 		var err error
@@ -170,7 +170,7 @@ func GenerateDepositData(t *testing.T, index uint, withdrawalAddress common.Addr
 }
 
 // Generate a signed exit for the given validator index
-func GenerateSignedExit(t *testing.T, index uint) api.ExitData {
+func GenerateSignedExit(t testing.TB, index uint) api.ExitData {
 	// Create the exit domain
 	domain, err := types.ComputeDomain(types.DomainVoluntaryExit, test.CapellaForkVersion, test.GenesisValidatorsRoot)
 	if err != nil {
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure each of the built-in set selection policies picks the right validators
+func TestSetSelectionPolicies(t *testing.T) {
+	// Set up a database
+	logger := slog.Default()
+	database := ProvisionFullDatabase(t, logger, false)
+
+	// Users 1 and 2 have one node each, with 1 and 2 validators. User 3 has two nodes with 1 validator each.
+	tests := []struct {
+		name     string
+		policy   string
+		params   db.SetSelectionParams
+		expected []uint
+	}{
+		{
+			name:     "default with a user limit",
+			policy:   db.SetSelectionPolicy_Default,
+			params:   db.SetSelectionParams{ValidatorsPerUser: 1},
+			expected: []uint{0, 1, 3},
+		}, {
+			name:     "default without limits",
+			policy:   db.SetSelectionPolicy_Default,
+			params:   db.SetSelectionParams{},
+			expected: []uint{0, 1, 2, 3, 4},
+		}, {
+			name:     "round robin without limits",
+			policy:   db.SetSelectionPolicy_RoundRobin,
+			params:   db.SetSelectionParams{},
+			expected: []uint{0, 1, 3, 4, 2},
+		}, {
+			name:     "round robin with a user limit",
+			policy:   db.SetSelectionPolicy_RoundRobin,
+			params:   db.SetSelectionParams{ValidatorsPerUser: 1},
+			expected: []uint{0, 1, 3},
+		}, {
+			name:     "fair share without limits",
+			policy:   db.SetSelectionPolicy_FairShare,
+			params:   db.SetSelectionParams{},
+			expected: []uint{0, 1, 3, 2, 4},
+		}, {
+			name:     "fair share with a max set size",
+			policy:   db.SetSelectionPolicy_FairShare,
+			params:   db.SetSelectionParams{MaxSetSize: 4},
+			expected: []uint{0, 1, 3, 2},
+		}, {
+			name:     "max set size",
+			policy:   db.SetSelectionPolicy_MaxSetSize,
+			params:   db.SetSelectionParams{MaxSetSize: 2},
+			expected: []uint{0, 1},
+		}, {
+			name:     "per node",
+			policy:   db.SetSelectionPolicy_PerNode,
+			params:   db.SetSelectionParams{ValidatorsPerNode: 1},
+			expected: []uint{0, 1, 3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := db.GetSetSelectionPolicy(tt.policy)
+			require.NoError(t, err)
+			require.NoError(t, policy.Validate(tt.params))
+
+			expected := make([]beacon.ExtendedDepositData, len(tt.expected))
+			for i, index := range tt.expected {
+				expected[i] = GenerateDepositData(t, index, test.StakeWiseVaultAddress)
+			}
+			set := database.CreateNewDepositDataSetWithPolicy(test.Network, policy, tt.params)
+			require.Equal(t, expected, set)
+		})
+	}
+}
+
+// Make sure policies reject params they can't work with
+func TestSetSelectionPolicyValidation(t *testing.T) {
+	policy, err := db.GetSetSelectionPolicy(db.SetSelectionPolicy_MaxSetSize)
+	require.NoError(t, err)
+	require.Error(t, policy.Validate(db.SetSelectionParams{}))
+
+	policy, err = db.GetSetSelectionPolicy(db.SetSelectionPolicy_PerNode)
+	require.NoError(t, err)
+	require.Error(t, policy.Validate(db.SetSelectionParams{}))
+
+	_, err = db.GetSetSelectionPolicy("unknown")
+	require.Error(t, err)
+}
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/clock"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure each backend applies changes and reverts them with its snapshots
+func TestStoreSnapshots(t *testing.T) {
+	logger := slog.Default()
+	for _, storeType := range []string{db.StoreType_Memory, db.StoreType_LevelDb} {
+		t.Run(storeType, func(t *testing.T) {
+			store, err := db.OpenStore(storeType, filepath.Join(t.TempDir(), "store"), logger)
+			require.NoError(t, err)
+			defer store.Close()
+			err = store.SetDatabase(ProvisionFullDatabase(t, logger, false))
+			require.NoError(t, err)
+
+			// Take a snapshot and record the state it holds
+			err = store.TakeSnapshot("base")
+			require.NoError(t, err)
+			base := getStoreState(t, store)
+
+			// Change the state and take another snapshot
+			runStoreOperations(t, store)
+			changed := getStoreState(t, store)
+			require.NotEqual(t, base, changed)
+			err = store.TakeSnapshot("changed")
+			require.NoError(t, err)
+
+			// Revert to each snapshot more than once to make sure reverting doesn't consume them
+			for i := 0; i < 2; i++ {
+				err = store.RevertToSnapshot("base")
+				require.NoError(t, err)
+				require.Equal(t, base, getStoreState(t, store))
+				err = store.RevertToSnapshot("changed")
+				require.NoError(t, err)
+				require.Equal(t, changed, getStoreState(t, store))
+			}
+			require.Equal(t, []string{"base", "changed"}, store.GetSnapshotNames())
+
+			// Lookups should work on the reverted state
+			err = store.RevertToSnapshot("base")
+			require.NoError(t, err)
+			node, registered := store.GetNode(getNodeAddress(0))
+			require.NotNil(t, node)
+			require.True(t, registered)
+			pubkey := beacon.ValidatorPubkey(GenerateDepositData(t, 0, test.StakeWiseVaultAddress).PublicKey)
+			require.NotNil(t, store.GetValidator(test.Network, pubkey))
+			require.Empty(t, store.GetBeaconValidators())
+
+			// Reverting to a missing snapshot should fail
+			err = store.RevertToSnapshot("missing")
+			require.True(t, errors.Is(err, db.ErrSnapshotNotFound))
+		})
+	}
+}
+
+// Make sure the LevelDB store keeps its state and snapshots when it's closed and reopened
+func TestLevelDbStorePersistence(t *testing.T) {
+	logger := slog.Default()
+	path := filepath.Join(t.TempDir(), "store")
+	database := ProvisionFullDatabase(t, logger, false)
+	session := database.Sessions[1] // Node 1's, since node 0's is revoked by runStoreOperations
+
+	// Run the same operations on an in-memory store and a LevelDB store, with the same time
+	storeClock := clock.NewClock()
+	storeClock.Freeze()
+	storeClock.Set(time.Unix(1700000000, 0))
+	memoryStore := db.NewMemoryStore(logger)
+	err := memoryStore.SetDatabase(database.Clone())
+	require.NoError(t, err)
+	memoryStore.SetClock(storeClock)
+	levelDbStore, err := db.OpenLevelDbStore(path, logger)
+	require.NoError(t, err)
+	err = levelDbStore.SetDatabase(database.Clone())
+	require.NoError(t, err)
+	levelDbStore.SetClock(storeClock)
+	err = levelDbStore.TakeSnapshot("base")
+	require.NoError(t, err)
+	base := getStoreState(t, levelDbStore)
+	runStoreOperations(t, memoryStore)
+	runStoreOperations(t, levelDbStore)
+	expected := getStoreState(t, memoryStore)
+	require.Equal(t, expected, getStoreState(t, levelDbStore))
+
+	// Reopen the LevelDB store and make sure nothing was lost
+	err = levelDbStore.Close()
+	require.NoError(t, err)
+	levelDbStore, err = db.OpenLevelDbStore(path, logger)
+	require.NoError(t, err)
+	defer levelDbStore.Close()
+	require.Equal(t, expected, getStoreState(t, levelDbStore))
+	require.Equal(t, []string{"base"}, levelDbStore.GetSnapshotNames())
+
+	// Lookups should work on the reloaded state
+	loadedSession := levelDbStore.GetSessionByToken(session.Token)
+	require.NotNil(t, loadedSession)
+	require.True(t, loadedSession.IsLoggedIn)
+	require.Same(t, loadedSession, levelDbStore.GetSessionByNonce(session.Nonce))
+	node, registered := levelDbStore.GetNode(getNodeAddress(0))
+	require.NotNil(t, node)
+	require.True(t, registered)
+	require.Len(t, levelDbStore.GetBeaconValidators(), 3)
+	require.Same(t, levelDbStore.GetBeaconValidators()[0], levelDbStore.GetBeaconValidator(0))
+
+	// The snapshot should have survived too
+	err = levelDbStore.RevertToSnapshot("base")
+	require.NoError(t, err)
+	require.Equal(t, base, getStoreState(t, levelDbStore))
+}
+
+// ==========================
+// === Internal Functions ===
+// ==========================
+
+// Runs operations that change every kind of record in a store provisioned with ProvisionFullDatabase
+func runStoreOperations(t *testing.T, store db.Store) {
+	// Add a user with a node and change another's settings
+	err := store.AddUser("new@nodeset.io")
+	require.NoError(t, err)
+	err = store.WhitelistNodeAccount("new@nodeset.io", common.HexToAddress("0x1234"))
+	require.NoError(t, err)
+	err = store.SetMinipoolLimit(test.User1Email, 5)
+	require.NoError(t, err)
+
+	// Run a full deposit data set cycle and exit one of its validators
+	set := store.CreateNewDepositDataSet(test.Network, 1)
+	require.Len(t, set, 3)
+	err = store.UploadDepositDataToStakeWise(test.StakeWiseVaultAddress, test.Network, set)
+	require.NoError(t, err)
+	err = store.MarkDepositDataSetUploaded(test.StakeWiseVaultAddress, test.Network, set)
+	require.NoError(t, err)
+	err = store.MarkValidatorsRegistered(test.StakeWiseVaultAddress, test.Network, set)
+	require.NoError(t, err)
+	err = store.ExitBeaconValidator(0, 0, 5, 10)
+	require.NoError(t, err)
+
+	// Upload a signed exit
+	err = store.HandleSignedExitUpload(getNodeAddress(0), test.Network, []api.ExitData{GenerateSignedExit(t, 0)})
+	require.NoError(t, err)
+
+	// Log a node in with a new session and revoke its old one
+	session := store.CreateSession()
+	err = store.Login(getNodeAddress(0), session.Nonce)
+	require.NoError(t, err)
+	require.Equal(t, 1, store.RevokeSessions(getNodeAddress(0), session.Token))
+
+	// Record it in the audit log
+	store.AddAuditEntry(db.AuditEntry{
+		Time:  time.Unix(1700000000, 0),
+		Actor: "admin",
+		Route: "test operations",
+	})
+}
+
+// Gets the address of a node provisioned by ProvisionFullDatabase
+func getNodeAddress(index uint) common.Address {
+	return crypto.PubkeyToAddress(NodeKeys[index].PublicKey)
+}
+
+// Serializes the state of a store so stores can be compared regardless of how they keep it
+func getStoreState(t *testing.T, store db.Store) string {
+	// Pubkeys can't be JSON keys, so key the vaults' uploaded data by their hex strings
+	vaults := map[string][]any{}
+	for network, networkVaults := range store.GetStakeWiseVaults() {
+		for _, vault := range networkVaults {
+			uploadedData := map[string]bool{}
+			for pubkey, uploaded := range vault.UploadedData {
+				uploadedData[pubkey.HexWithPrefix()] = uploaded
+			}
+			vaults[network] = append(vaults[network], []any{
+				vault.Address,
+				uploadedData,
+				vault.LatestDepositDataSetIndex,
+				vault.LatestDepositDataSet,
+				vault.CreatedTime,
+				vault.LatestDepositDataSetTime,
+				vault.DepositDataSets,
+			})
+		}
+	}
+	bytes, err := json.Marshal(map[string]any{
+		"users":            store.GetUsers(),
+		"vaults":           vaults,
+		"beaconValidators": store.GetBeaconValidators(),
+		"auditLog":         store.GetAuditLog(),
+	})
+	require.NoError(t, err)
+	return string(bytes)
+}
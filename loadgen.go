@@ -0,0 +1,449 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/rocket-pool/node-manager-core/node/validator"
+	"github.com/urfave/cli/v2"
+	types "github.com/wealdtech/go-eth2-types/v2"
+)
+
+// Operations run by each simulated node operator, in order
+const (
+	loadgenOperation_AddUser           string = "add-user"
+	loadgenOperation_WhitelistNode     string = "whitelist-node"
+	loadgenOperation_Register          string = "register"
+	loadgenOperation_GetNonce          string = "get-nonce"
+	loadgenOperation_Login             string = "login"
+	loadgenOperation_UploadDepositData string = "upload-deposit-data"
+	loadgenOperation_GetValidators     string = "get-validators"
+	loadgenOperation_UploadSignedExits string = "upload-signed-exits"
+)
+
+// Error keys for failures that don't come with one from the mock
+const (
+	loadgenErrorKey_RequestFailed string = "request_failed"
+	loadgenErrorKey_Status        string = "status_%d"
+)
+
+// Creates the loadgen command, which simulates node operators running the full flow against a running mock
+func createLoadgenCommand() *cli.Command {
+	apiUrlFlag := &cli.StringFlag{
+		Name:  "api-url",
+		Usage: "The URL of the running mock's API routes, if they aren't served alongside the admin routes at --url",
+	}
+	nodesFlag := &cli.UintFlag{
+		Name:  "nodes",
+		Usage: "The number of node operators to simulate",
+		Value: 10,
+	}
+	keyOffsetFlag := &cli.UintFlag{
+		Name:  "key-offset",
+		Usage: "The index of the first node's key. Node keys and validator keys are derived from the test mnemonic starting at this index, so use a different offset to run again against the same mock.",
+	}
+	validatorsFlag := &cli.UintFlag{
+		Name:  "validators",
+		Usage: "The number of validators each node uploads deposit data and signed exits for",
+		Value: 2,
+	}
+	pollsFlag := &cli.UintFlag{
+		Name:  "polls",
+		Usage: "The number of times each node polls its validators before uploading signed exits",
+		Value: 3,
+	}
+	networkFlag := &cli.StringFlag{
+		Name:  "network",
+		Usage: "The name of the network",
+		Value: test.Network,
+	}
+	vaultFlag := &cli.StringFlag{
+		Name:  "vault",
+		Usage: "The address of the StakeWise vault to upload deposit data for. It's added to the mock if it isn't there already.",
+		Value: test.StakeWiseVaultAddressHex,
+	}
+
+	return &cli.Command{
+		Name:  "loadgen",
+		Usage: "Simulate node operators running the full flow against a running mock concurrently, and report throughput, latency, and errors",
+		Flags: append([]cli.Flag{apiUrlFlag, nodesFlag, keyOffsetFlag, validatorsFlag, pollsFlag, networkFlag, vaultFlag}, clientFlags...),
+		Action: func(c *cli.Context) error {
+			vaultAddress, err := parseAddress(c.String(vaultFlag.Name))
+			if err != nil {
+				return err
+			}
+			apiUrl := c.String(apiUrlFlag.Name)
+			if apiUrl == "" {
+				apiUrl = c.String(mockUrlFlag.Name)
+			}
+			generator := &loadGenerator{
+				admin:      newAdminClient(c),
+				nodeCount:  c.Uint(nodesFlag.Name),
+				keyOffset:  c.Uint(keyOffsetFlag.Name),
+				validators: c.Uint(validatorsFlag.Name),
+				polls:      c.Uint(pollsFlag.Name),
+				network:    c.String(networkFlag.Name),
+				stats:      newLoadgenStats(),
+			}
+			generator.apiUrl, generator.httpClient = getLoadgenHttpClient(apiUrl, int(generator.nodeCount))
+
+			// Make sure the vault exists
+			err = generator.admin.request(api.AdminAddVaultPath, map[string]string{
+				"network": generator.network,
+				"address": vaultAddress.Hex(),
+			}, nil)
+			var requestErr *requestError
+			if err != nil && !(errors.As(err, &requestErr) && requestErr.Key == "vault_already_exists") {
+				return fmt.Errorf("error adding vault: %w", err)
+			}
+
+			report, err := generator.run(vaultAddress)
+			if err != nil {
+				return err
+			}
+			return printJson(report)
+		},
+	}
+}
+
+// Report of a load generator run
+type loadgenReport struct {
+	// The number of simulated node operators
+	Nodes uint `json:"nodes"`
+
+	// The number of node operators that finished the flow without errors
+	CompletedNodes int `json:"completedNodes"`
+
+	// The wall time of the run
+	Duration string `json:"duration"`
+
+	// The number of requests sent
+	Requests int `json:"requests"`
+
+	// Requests per second over the run
+	Throughput float64 `json:"throughput"`
+
+	// The latency of all requests
+	Latency loadgenLatency `json:"latency"`
+
+	// The latency of each operation's requests
+	Operations map[string]loadgenLatency `json:"operations"`
+
+	// The number of failed requests
+	Errors int `json:"errors"`
+
+	// The number of failed requests for each error key
+	ErrorsByKey map[string]int `json:"errorsByKey"`
+}
+
+// Latency percentiles for a collection of requests
+type loadgenLatency struct {
+	Requests int    `json:"requests"`
+	P50      string `json:"p50"`
+	P90      string `json:"p90"`
+	P99      string `json:"p99"`
+	Max      string `json:"max"`
+}
+
+// Request latencies and errors recorded during a load generator run
+type loadgenStats struct {
+	latencies   map[string][]time.Duration
+	errorsByKey map[string]int
+	lock        sync.Mutex
+}
+
+// Creates an empty set of stats
+func newLoadgenStats() *loadgenStats {
+	return &loadgenStats{
+		latencies:   map[string][]time.Duration{},
+		errorsByKey: map[string]int{},
+	}
+}
+
+// Records the result of a request for an operation that was sent at the start time
+func (s *loadgenStats) record(operation string, start time.Time, err error) {
+	latency := time.Since(start)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.latencies[operation] = append(s.latencies[operation], latency)
+	if err != nil {
+		s.errorsByKey[getLoadgenErrorKey(err)]++
+	}
+}
+
+// Simulates node operators running the full flow against a running mock
+type loadGenerator struct {
+	admin      *adminClient
+	apiUrl     string
+	httpClient *http.Client
+	nodeCount  uint
+	keyOffset  uint
+	validators uint
+	polls      uint
+	network    string
+	stats      *loadgenStats
+
+	// The public key of the mock's exit encryption identity
+	encryptionPublicKey string
+}
+
+// The keys and pre-signed messages of a simulated node operator, prepared before the run so signing doesn't count
+// towards request latency
+type loadgenNode struct {
+	email       string
+	client      *nodeClient
+	depositData []beacon.ExtendedDepositData
+	signedExits []api.EncryptedExitData
+}
+
+// Runs every node operator's flow concurrently and reports the results
+func (g *loadGenerator) run(vaultAddress common.Address) (*loadgenReport, error) {
+	// Get the key the nodes encrypt their exits to
+	var err error
+	g.encryptionPublicKey, err = getEncryptionPublicKey(g.apiUrl, g.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("error getting exit encryption public key: %w", err)
+	}
+
+	// Prepare the nodes
+	nodes := make([]*loadgenNode, g.nodeCount)
+	prepareErrs := make([]error, g.nodeCount)
+	wg := &sync.WaitGroup{}
+	for i := range nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nodes[i], prepareErrs[i] = g.prepareNode(g.keyOffset+uint(i), vaultAddress)
+		}(i)
+	}
+	wg.Wait()
+	err = errors.Join(prepareErrs...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run their flows
+	completed := make([]bool, g.nodeCount)
+	start := time.Now()
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node *loadgenNode) {
+			defer wg.Done()
+			completed[i] = g.runNode(node)
+		}(i, node)
+	}
+	wg.Wait()
+	duration := time.Since(start)
+
+	// Build the report
+	report := &loadgenReport{
+		Nodes:       g.nodeCount,
+		Duration:    duration.String(),
+		Operations:  map[string]loadgenLatency{},
+		ErrorsByKey: g.stats.errorsByKey,
+	}
+	for _, nodeCompleted := range completed {
+		if nodeCompleted {
+			report.CompletedNodes++
+		}
+	}
+	all := []time.Duration{}
+	for operation, latencies := range g.stats.latencies {
+		report.Operations[operation] = getLoadgenLatency(latencies)
+		all = append(all, latencies...)
+	}
+	report.Requests = len(all)
+	report.Latency = getLoadgenLatency(all)
+	report.Throughput = float64(report.Requests) / duration.Seconds()
+	for _, count := range g.stats.errorsByKey {
+		report.Errors += count
+	}
+	return report, nil
+}
+
+// Derives a node operator's keys, signs its deposit data and exits, and encrypts the exits
+func (g *loadGenerator) prepareNode(index uint, vaultAddress common.Address) (*loadgenNode, error) {
+	nodeKey, err := test.GetEthPrivateKey(index)
+	if err != nil {
+		return nil, fmt.Errorf("error getting node key %d: %w", index, err)
+	}
+	node := &loadgenNode{
+		email:       fmt.Sprintf("loadgen_%d@test.com", index),
+		client:      newNodeClient(g.apiUrl, g.httpClient, nodeKey),
+		depositData: make([]beacon.ExtendedDepositData, g.validators),
+		signedExits: make([]api.EncryptedExitData, g.validators),
+	}
+
+	// Give each node its own range of validator keys
+	domain, err := types.ComputeDomain(types.DomainVoluntaryExit, test.CapellaForkVersion, test.GenesisValidatorsRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error computing exit domain: %w", err)
+	}
+	for i := range node.depositData {
+		validatorIndex := index*g.validators + uint(i)
+		validatorKey, err := test.GetBeaconPrivateKey(validatorIndex)
+		if err != nil {
+			return nil, fmt.Errorf("error getting validator key %d: %w", validatorIndex, err)
+		}
+		node.depositData[i], err = validator.GetDepositData(
+			validatorKey,
+			validator.GetWithdrawalCredsFromAddress(vaultAddress),
+			test.GenesisForkVersion,
+			test.DepositAmount,
+			g.network,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error generating deposit data for validator %d: %w", validatorIndex, err)
+		}
+		beaconIndex := strconv.FormatUint(uint64(validatorIndex), 10)
+		exitSignature, err := validator.GetSignedExitMessage(validatorKey, beaconIndex, test.ExitEpoch, domain)
+		if err != nil {
+			return nil, fmt.Errorf("error signing exit for validator %d: %w", validatorIndex, err)
+		}
+		encryptedExit, err := auth.EncryptExitMessage(api.ExitMessage{
+			Message: api.ExitMessageDetails{
+				Epoch:          strconv.FormatUint(test.ExitEpoch, 10),
+				ValidatorIndex: beaconIndex,
+			},
+			Signature: exitSignature.HexWithPrefix(),
+		}, g.encryptionPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("error encrypting exit for validator %d: %w", validatorIndex, err)
+		}
+		node.signedExits[i] = api.EncryptedExitData{
+			Pubkey:      beacon.ValidatorPubkey(validatorKey.PublicKey().Marshal()).HexWithPrefix(),
+			ExitMessage: encryptedExit,
+		}
+	}
+	return node, nil
+}
+
+// Runs a node operator's flow, stopping at the first failed request. Returns true if the whole flow succeeded.
+func (g *loadGenerator) runNode(node *loadgenNode) bool {
+	address := node.client.nodeAddress.Hex()
+	networkQuery := map[string]string{
+		"network": g.network,
+	}
+
+	// Set up the user and node
+	if !g.runOperation(loadgenOperation_AddUser, func() error {
+		return g.admin.request(api.AdminAddUserPath, map[string]string{
+			"email": node.email,
+		}, nil)
+	}) {
+		return false
+	}
+	if !g.runOperation(loadgenOperation_WhitelistNode, func() error {
+		return g.admin.request(api.AdminWhitelistNodePath, map[string]string{
+			"email":   node.email,
+			"address": address,
+		}, nil)
+	}) {
+		return false
+	}
+	if !g.runOperation(loadgenOperation_Register, func() error {
+		return node.client.register(node.email)
+	}) {
+		return false
+	}
+
+	// Log in
+	var nonce string
+	if !g.runOperation(loadgenOperation_GetNonce, func() error {
+		var err error
+		nonce, err = node.client.getNonce()
+		return err
+	}) {
+		return false
+	}
+	if !g.runOperation(loadgenOperation_Login, func() error {
+		return node.client.login(nonce)
+	}) {
+		return false
+	}
+
+	// Upload deposit data, poll the validators, and upload their exits
+	if !g.runOperation(loadgenOperation_UploadDepositData, func() error {
+		return node.client.request(http.MethodPost, api.DepositDataPath, nil, node.depositData, nil)
+	}) {
+		return false
+	}
+	for i := uint(0); i < g.polls; i++ {
+		if !g.runOperation(loadgenOperation_GetValidators, func() error {
+			var data api.ValidatorsData
+			return node.client.request(http.MethodGet, api.ValidatorsPath, networkQuery, nil, &data)
+		}) {
+			return false
+		}
+	}
+	return g.runOperation(loadgenOperation_UploadSignedExits, func() error {
+		return node.client.request(http.MethodPatch, api.ValidatorsPath, networkQuery, node.signedExits, nil)
+	})
+}
+
+// Runs and records a request for an operation, returning true if it succeeded
+func (g *loadGenerator) runOperation(operation string, request func() error) bool {
+	start := time.Now()
+	err := request()
+	g.stats.record(operation, start, err)
+	return err == nil
+}
+
+// Gets an HTTP client for the mock URL that keeps enough idle connections open for every simulated node
+func getLoadgenHttpClient(mockUrl string, nodeCount int) (string, *http.Client) {
+	baseUrl, httpClient := getHttpClient(mockUrl)
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.MaxIdleConnsPerHost = nodeCount
+	return baseUrl, &http.Client{
+		Transport: transport,
+	}
+}
+
+// Gets the key to count an error under
+func getLoadgenErrorKey(err error) string {
+	var requestErr *requestError
+	if !errors.As(err, &requestErr) {
+		return loadgenErrorKey_RequestFailed
+	}
+	if requestErr.Key != "" {
+		return requestErr.Key
+	}
+	return fmt.Sprintf(loadgenErrorKey_Status, requestErr.StatusCode)
+}
+
+// Gets the latency percentiles of a collection of requests
+func getLoadgenLatency(latencies []time.Duration) loadgenLatency {
+	if len(latencies) == 0 {
+		return loadgenLatency{}
+	}
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+	return loadgenLatency{
+		Requests: len(sorted),
+		P50:      getPercentile(sorted, 50).String(),
+		P90:      getPercentile(sorted, 90).String(),
+		P99:      getPercentile(sorted, 99).String(),
+		Max:      sorted[len(sorted)-1].String(),
+	}
+}
+
+// Gets a percentile of sorted latencies with the nearest-rank method
+func getPercentile(sorted []time.Duration, percentile float64) time.Duration {
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure percentiles are picked with the nearest-rank method
+func TestGetPercentile(t *testing.T) {
+	// 1ms through 10ms
+	sorted := make([]time.Duration, 10)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	tests := []struct {
+		name       string
+		latencies  []time.Duration
+		percentile float64
+		expected   time.Duration
+	}{
+		{name: "p50 of 10", latencies: sorted, percentile: 50, expected: 5 * time.Millisecond},
+		{name: "p90 of 10", latencies: sorted, percentile: 90, expected: 9 * time.Millisecond},
+		{name: "p99 of 10 rounds up", latencies: sorted, percentile: 99, expected: 10 * time.Millisecond},
+		{name: "p100 of 10", latencies: sorted, percentile: 100, expected: 10 * time.Millisecond},
+		{name: "p0 takes the first", latencies: sorted, percentile: 0, expected: time.Millisecond},
+		{name: "p51 of 10 rounds up", latencies: sorted, percentile: 51, expected: 6 * time.Millisecond},
+		{name: "p99 of 1", latencies: sorted[:1], percentile: 99, expected: time.Millisecond},
+		{name: "p50 of 2", latencies: sorted[:2], percentile: 50, expected: time.Millisecond},
+		{name: "p50 of 3", latencies: sorted[:3], percentile: 50, expected: 2 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, getPercentile(tt.latencies, tt.percentile))
+		})
+	}
+}
+
+// Make sure latencies are summarized without changing the recorded ones
+func TestGetLoadgenLatency(t *testing.T) {
+	require.Equal(t, loadgenLatency{}, getLoadgenLatency(nil))
+
+	latencies := []time.Duration{
+		4 * time.Millisecond,
+		1 * time.Millisecond,
+		3 * time.Millisecond,
+		2 * time.Millisecond,
+	}
+	latency := getLoadgenLatency(latencies)
+	require.Equal(t, loadgenLatency{
+		Requests: 4,
+		P50:      "2ms",
+		P90:      "4ms",
+		P99:      "4ms",
+		Max:      "4ms",
+	}, latency)
+	require.Equal(t, 4*time.Millisecond, latencies[0])
+}
+
+// Make sure failed requests are counted under the mock's error key, or a fallback if there wasn't one
+func TestLoadgenErrorKeys(t *testing.T) {
+	stats := newLoadgenStats()
+	start := time.Now()
+	stats.record(loadgenOperation_Login, start, nil)
+	stats.record(loadgenOperation_Login, start, &requestError{StatusCode: 401, Key: "invalid_session"})
+	stats.record(loadgenOperation_Login, start, fmt.Errorf("error logging in: %w", &requestError{StatusCode: 401, Key: "invalid_session"}))
+	stats.record(loadgenOperation_GetValidators, start, &requestError{StatusCode: 502})
+	stats.record(loadgenOperation_GetValidators, start, errors.New("connection refused"))
+
+	require.Equal(t, map[string]int{
+		"invalid_session":             2,
+		"status_502":                  1,
+		loadgenErrorKey_RequestFailed: 1,
+	}, stats.errorsByKey)
+	require.Len(t, stats.latencies[loadgenOperation_Login], 3)
+	require.Len(t, stats.latencies[loadgenOperation_GetValidators], 2)
+}
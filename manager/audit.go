@@ -0,0 +1,20 @@
+package manager
+
+import (
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+)
+
+// Records a state-changing operation in the audit log
+func (m *NodeSetMockManager) AddAuditEntry(entry db.AuditEntry) {
+	m.store.AddAuditEntry(entry)
+}
+
+// Get a copy of the audit log, oldest entry first
+func (m *NodeSetMockManager) GetAuditLog() []*db.AuditEntry {
+	log := m.store.GetAuditLog()
+	entries := make([]*db.AuditEntry, len(log))
+	for i, entry := range log {
+		entries[i] = entry.Clone()
+	}
+	return entries
+}
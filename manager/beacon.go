@@ -0,0 +1,117 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+)
+
+// Settings for the simulated Beacon Chain
+type BeaconConfig struct {
+	// The time of the chain's genesis
+	GenesisTime time.Time
+
+	// The fork version and validators root reported by the genesis route
+	GenesisForkVersion    []byte
+	GenesisValidatorsRoot common.Hash
+
+	// The length of a slot, in seconds
+	SecondsPerSlot uint64
+
+	// The number of slots in an epoch
+	SlotsPerEpoch uint64
+
+	// The number of epochs between a voluntary exit being submitted and the validator exiting
+	ExitDelayEpochs uint64
+
+	// The number of epochs between a validator exiting and its balance becoming withdrawable
+	WithdrawalDelayEpochs uint64
+}
+
+// Get the default Beacon Chain settings, based on mainnet's, with genesis at the provided time
+func DefaultBeaconConfig(genesisTime time.Time) BeaconConfig {
+	return BeaconConfig{
+		GenesisTime:           genesisTime,
+		GenesisForkVersion:    []byte{0x00, 0x00, 0x00, 0x00},
+		GenesisValidatorsRoot: common.Hash{},
+		SecondsPerSlot:        12,
+		SlotsPerEpoch:         32,
+		ExitDelayEpochs:       5, // 1 + MAX_SEED_LOOKAHEAD
+		WithdrawalDelayEpochs: 256,
+	}
+}
+
+// Get the slot at the provided time. This is 0 before genesis.
+func (c BeaconConfig) GetSlotAt(now time.Time) uint64 {
+	if now.Before(c.GenesisTime) {
+		return 0
+	}
+	elapsed := uint64(now.Sub(c.GenesisTime) / time.Second)
+	return elapsed / c.SecondsPerSlot
+}
+
+// Get the epoch at the provided time. This is 0 before genesis.
+func (c BeaconConfig) GetEpochAt(now time.Time) uint64 {
+	return c.GetSlotAt(now) / c.SlotsPerEpoch
+}
+
+// Set the simulated Beacon Chain settings. Its slots and epochs follow the manager's clock.
+func (m *NodeSetMockManager) SetBeaconConfig(config BeaconConfig) error {
+	if config.SecondsPerSlot == 0 {
+		return fmt.Errorf("seconds per slot must be greater than 0")
+	}
+	if config.SlotsPerEpoch == 0 {
+		return fmt.Errorf("slots per epoch must be greater than 0")
+	}
+	m.beaconConfig = config
+	return nil
+}
+
+// Get the simulated Beacon Chain settings
+func (m *NodeSetMockManager) GetBeaconConfig() BeaconConfig {
+	return m.beaconConfig
+}
+
+// Get the current slot of the simulated Beacon Chain, according to the manager's clock
+func (m *NodeSetMockManager) GetBeaconSlot() uint64 {
+	return m.beaconConfig.GetSlotAt(m.clock.Now())
+}
+
+// Get the current epoch of the simulated Beacon Chain, according to the manager's clock
+func (m *NodeSetMockManager) GetBeaconEpoch() uint64 {
+	return m.beaconConfig.GetEpochAt(m.clock.Now())
+}
+
+// Get all of the validators on the simulated Beacon Chain, ordered by index
+func (m *NodeSetMockManager) GetBeaconValidators() []*db.Validator {
+	return m.store.GetBeaconValidators()
+}
+
+// Submit a voluntary exit for a validator on the simulated Beacon Chain. The signature isn't checked, but the exit's
+// epoch must have been reached and the validator must be active.
+func (m *NodeSetMockManager) SubmitVoluntaryExit(index uint64, epoch uint64) error {
+	currentEpoch := m.GetBeaconEpoch()
+	if epoch > currentEpoch {
+		return fmt.Errorf("exit epoch %d is after the current epoch %d", epoch, currentEpoch)
+	}
+
+	validator := m.store.GetBeaconValidator(index)
+	if validator == nil {
+		return db.ErrUnknownValidatorIndex
+	}
+	statuses := m.getValidatorStatuses([]trackedValidator{
+		{network: validator.DepositData.NetworkName, pubkey: validator.Pubkey},
+	})
+
+	exitEpoch := currentEpoch + m.beaconConfig.ExitDelayEpochs
+	withdrawableEpoch := exitEpoch + m.beaconConfig.WithdrawalDelayEpochs
+	err := m.store.ExitBeaconValidator(index, currentEpoch, exitEpoch, withdrawableEpoch)
+	if err != nil {
+		return err
+	}
+	m.logger.Info("Processed voluntary exit", "index", index, "exitEpoch", exitEpoch)
+	m.publishStatusChanges(statuses)
+	return nil
+}
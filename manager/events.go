@@ -0,0 +1,150 @@
+package manager
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/rocket-pool/node-manager-core/beacon"
+)
+
+// A subscription to the manager's state change events
+type EventSubscription struct {
+	// Delivers the events in the order they happened. It's closed when the subscription ends.
+	Events <-chan api.Event
+
+	events  chan api.Event
+	manager *NodeSetMockManager
+}
+
+// Stops delivering events to the subscription and closes its channel
+func (s *EventSubscription) Unsubscribe() {
+	m := s.manager
+	m.eventsLock.Lock()
+	defer m.eventsLock.Unlock()
+
+	if _, exists := m.subscriptions[s]; exists {
+		delete(m.subscriptions, s)
+		close(s.events)
+	}
+}
+
+// Subscribes to the manager's state change events. Events are dropped for the subscription if its buffer is full,
+// so it should be drained promptly and Unsubscribe() should be called when it's no longer needed.
+func (m *NodeSetMockManager) SubscribeToEvents(bufferSize int) *EventSubscription {
+	events := make(chan api.Event, bufferSize)
+	subscription := &EventSubscription{
+		Events:  events,
+		events:  events,
+		manager: m,
+	}
+
+	m.eventsLock.Lock()
+	defer m.eventsLock.Unlock()
+	m.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// Sends an event to every subscription
+func (m *NodeSetMockManager) publishEvent(eventType api.EventType, data any) {
+	m.eventsLock.Lock()
+	defer m.eventsLock.Unlock()
+
+	// Hold it back if it's part of a transaction
+	if m.inTransaction {
+		m.pendingEvents = append(m.pendingEvents, pendingEvent{
+			eventType: eventType,
+			data:      data,
+		})
+		return
+	}
+
+	m.lastEventId++
+	event := api.Event{
+		ID:   m.lastEventId,
+		Type: eventType,
+		Time: m.clock.Now(),
+		Data: data,
+	}
+	for subscription := range m.subscriptions {
+		select {
+		case subscription.events <- event:
+		default:
+			m.logger.Warn("Dropped event for a full subscription", "id", event.ID, "type", eventType)
+		}
+	}
+}
+
+// A validator whose status is being tracked for changes
+type trackedValidator struct {
+	network string
+	pubkey  beacon.ValidatorPubkey
+}
+
+// Gets the current statuses of the validators so changes can be published later
+func (m *NodeSetMockManager) getValidatorStatuses(validators []trackedValidator) map[trackedValidator]api.StakeWiseStatus {
+	statuses := map[trackedValidator]api.StakeWiseStatus{}
+	for _, validator := range validators {
+		statuses[validator] = m.GetValidatorStatus(validator.network, validator.pubkey)
+	}
+	return statuses
+}
+
+// Publishes an event for each validator whose status is different from the previous one
+func (m *NodeSetMockManager) publishStatusChanges(previousStatuses map[trackedValidator]api.StakeWiseStatus) {
+	for validator, previousStatus := range previousStatuses {
+		status := m.GetValidatorStatus(validator.network, validator.pubkey)
+		if status == previousStatus {
+			continue
+		}
+		m.publishEvent(api.EventType_ValidatorStatusChanged, api.ValidatorStatusChangedEventData{
+			Network:        validator.network,
+			Pubkey:         validator.pubkey,
+			PreviousStatus: previousStatus,
+			Status:         status,
+		})
+	}
+}
+
+// Gets the validators in a deposit data set so their statuses can be tracked
+func getTrackedValidators(data []beacon.ExtendedDepositData) []trackedValidator {
+	validators := make([]trackedValidator, len(data))
+	for i, depositData := range data {
+		validators[i] = trackedValidator{
+			network: depositData.NetworkName,
+			pubkey:  beacon.ValidatorPubkey(depositData.PublicKey),
+		}
+	}
+	return validators
+}
+
+// Gets the validators on the simulated Beacon Chain that have exited or are exiting, whose statuses depend on time
+func (m *NodeSetMockManager) getExitingValidators() []trackedValidator {
+	validators := []trackedValidator{}
+	for _, validator := range m.store.GetBeaconValidators() {
+		if validator.ExitEpoch == db.FarFutureEpoch {
+			continue
+		}
+		validators = append(validators, trackedValidator{
+			network: validator.DepositData.NetworkName,
+			pubkey:  validator.Pubkey,
+		})
+	}
+	return validators
+}
+
+// Gets the pubkeys of the validators in a deposit data set
+func getPubkeys(data []beacon.ExtendedDepositData) []beacon.ValidatorPubkey {
+	pubkeys := make([]beacon.ValidatorPubkey, len(data))
+	for i, depositData := range data {
+		pubkeys[i] = beacon.ValidatorPubkey(depositData.PublicKey)
+	}
+	return pubkeys
+}
+
+// Gets the node data payload for a node event
+func getNodeEventData(email string, nodeAddress common.Address) api.NodeEventData {
+	return api.NodeEventData{
+		Email:       email,
+		NodeAddress: nodeAddress.Hex(),
+	}
+}
@@ -0,0 +1,115 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+
+	"filippo.io/age"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/rocket-pool/node-manager-core/beacon"
+)
+
+var (
+	ErrPlaintextExitsDisabled error = errors.New("signed exits must be encrypted")
+)
+
+// Set the identity signed exits are encrypted to, replacing the randomly generated one
+func (m *NodeSetMockManager) SetExitEncryptionIdentity(identity *age.X25519Identity) {
+	m.exitEncryptionIdentity = identity
+}
+
+// Get the public key signed exits must be encrypted to
+func (m *NodeSetMockManager) GetExitEncryptionPublicKey() string {
+	return m.exitEncryptionIdentity.Recipient().String()
+}
+
+// Set whether plaintext signed exits are accepted, for compatibility with clients that don't encrypt them. They're
+// rejected by default, like the real service does.
+func (m *NodeSetMockManager) SetPlaintextExitsAllowed(allowed bool) {
+	m.plaintextExitsAllowed = allowed
+}
+
+// Get whether plaintext signed exits are accepted
+func (m *NodeSetMockManager) GetPlaintextExitsAllowed() bool {
+	return m.plaintextExitsAllowed
+}
+
+// Handle a new collection of encrypted signed exits from a node, decrypting and validating each one. Nothing is
+// stored if any of them are invalid, and the error reports the problem with each invalid one.
+func (m *NodeSetMockManager) HandleEncryptedSignedExitUpload(nodeAddress common.Address, network string, data []api.EncryptedExitData) error {
+	var batchErr db.BatchError
+	exits := make([]api.ExitData, len(data))
+	for i, encryptedExit := range data {
+		exits[i].Pubkey = encryptedExit.Pubkey
+		_, err := beacon.HexToValidatorPubkey(encryptedExit.Pubkey)
+		if err != nil {
+			batchErr.Add(i, fmt.Errorf("%w [%s]: %s", db.ErrInvalidPubkey, encryptedExit.Pubkey, err.Error()))
+			continue
+		}
+		message, err := auth.DecryptExitMessage(encryptedExit.ExitMessage, m.exitEncryptionIdentity)
+		if err != nil {
+			batchErr.Add(i, fmt.Errorf("error with exit for [%s]: %w", encryptedExit.Pubkey, err))
+			continue
+		}
+		exits[i].ExitMessage = message
+	}
+	return m.handleSignedExitUpload(nodeAddress, network, exits, &batchErr)
+}
+
+// Handle a new collection of plaintext signed exits from a node, validating each one. This fails unless plaintext
+// exits are allowed. Nothing is stored if any of them are invalid, and the error reports the problem with each
+// invalid one.
+func (m *NodeSetMockManager) HandleSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData) error {
+	if !m.plaintextExitsAllowed {
+		return ErrPlaintextExitsDisabled
+	}
+	var batchErr db.BatchError
+	for i, exit := range data {
+		err := auth.ValidateExitMessage(exit.ExitMessage)
+		if err != nil {
+			batchErr.Add(i, fmt.Errorf("error with exit for [%s]: %w", exit.Pubkey, err))
+		}
+	}
+	return m.handleSignedExitUpload(nodeAddress, network, data, &batchErr)
+}
+
+// Checks a collection of signed exits from a node against its validators, then stores them and publishes the event
+// for them. Nothing is stored if there are problems with any of them, including the ones already in batchErr.
+func (m *NodeSetMockManager) handleSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData, batchErr *db.BatchError) error {
+	// Check the exits against the node's validators too, so every problem is reported together
+	err := m.store.ValidateSignedExitUpload(nodeAddress, network, data)
+	var storeErr *db.BatchError
+	if errors.As(err, &storeErr) {
+		for _, entry := range storeErr.Entries {
+			batchErr.Add(entry.Index, entry.Err)
+		}
+	} else if err != nil {
+		return err
+	}
+	err = batchErr.Err()
+	if err != nil {
+		return err
+	}
+
+	// Store them
+	err = m.store.HandleSignedExitUpload(nodeAddress, network, data)
+	if err != nil {
+		return err
+	}
+	pubkeys := []beacon.ValidatorPubkey{}
+	for _, exitData := range data {
+		pubkey, err := beacon.HexToValidatorPubkey(exitData.Pubkey)
+		if err == nil {
+			pubkeys = append(pubkeys, pubkey)
+		}
+	}
+	m.publishEvent(api.EventType_ExitsUploaded, api.ExitsUploadedEventData{
+		NodeAddress: nodeAddress.Hex(),
+		Network:     network,
+		Pubkeys:     pubkeys,
+	})
+	return nil
+}
@@ -1,84 +1,270 @@
 package manager
 
 import (
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/big"
 	"net/http"
+	"sync"
+	"time"
 
+	"filippo.io/age"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
 	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/clock"
 	"github.com/nodeset-org/nodeset-svc-mock/db"
 	"github.com/rocket-pool/node-manager-core/beacon"
 )
 
 // Mock manager for the nodeset.io service
 type NodeSetMockManager struct {
-	database *db.Database
+	// Where the users, nodes, validators, vaults, sessions, and audit log are kept
+	store db.Store
+
+	// The key used to sign Constellation whitelist and deposit messages
+	constellationAdminKey *ecdsa.PrivateKey
+
+	// The source of time for everything the manager does
+	clock *clock.Clock
+
+	// Simulated Beacon Chain settings
+	beaconConfig BeaconConfig
+
+	// Deposit data set cycle scheduling
+	schedules       map[vaultKey]*vaultScheduleState
+	defaultSchedule *CycleSchedule
+	schedulerStop   chan struct{}
+	schedulerDone   chan struct{}
+
+	// Event subscriptions
+	subscriptions map[*EventSubscription]struct{}
+	lastEventId   uint64
+	eventsLock    sync.Mutex
+
+	// Events held back until the transaction they were published in succeeds
+	inTransaction bool
+	pendingEvents []pendingEvent
+
+	// Nonce issuing and login settings
+	sessionPolicy SessionPolicy
+
+	// The identity signed exits are encrypted to, and whether plaintext exits are still accepted
+	exitEncryptionIdentity *age.X25519Identity
+	plaintextExitsAllowed  bool
+
+	// Outbound webhooks
+	webhooks          map[uint64]*webhookState
+	lastWebhookId     uint64
+	webhookDeliveries []WebhookDelivery
+	webhooksLock      sync.Mutex
 
 	// Internal fields
-	snapshots map[string]*db.Database
-	logger    *slog.Logger
+	logger *slog.Logger
+	lock   sync.Mutex
 }
 
 var (
-	ErrInvalidSession error = errors.New("session token is invalid")
+	ErrInvalidSession   error = errors.New("session token is invalid")
+	ErrSnapshotNotFound error = db.ErrSnapshotNotFound
+	ErrWebhookNotFound  error = errors.New("webhook not found")
 )
 
 // Creates a new manager
 func NewNodeSetMockManager(logger *slog.Logger) *NodeSetMockManager {
-	return &NodeSetMockManager{
-		database:  db.NewDatabase(logger),
-		snapshots: map[string]*db.Database{},
-		logger:    logger,
+	// Generate a random Constellation admin key by default
+	adminKey, err := crypto.GenerateKey()
+	if err != nil {
+		panic(fmt.Errorf("error generating Constellation admin key: %w", err))
+	}
+
+	// Generate a random exit encryption identity by default
+	exitIdentity, err := age.GenerateX25519Identity()
+	if err != nil {
+		panic(fmt.Errorf("error generating exit encryption identity: %w", err))
+	}
+
+	m := &NodeSetMockManager{
+		store:                  db.NewMemoryStore(logger),
+		constellationAdminKey:  adminKey,
+		exitEncryptionIdentity: exitIdentity,
+		clock:                  clock.NewClock(),
+		schedules:              map[vaultKey]*vaultScheduleState{},
+		subscriptions:          map[*EventSubscription]struct{}{},
+		webhooks:               map[uint64]*webhookState{},
+		logger:                 logger,
+	}
+
+	m.store.SetClock(m.clock)
+
+	// Start the Beacon Chain now by default
+	err = m.SetBeaconConfig(DefaultBeaconConfig(m.clock.Now().Truncate(time.Second)))
+	if err != nil {
+		panic(fmt.Errorf("error setting default Beacon Chain config: %w", err))
 	}
+	return m
+}
+
+// Locks the manager for exclusive access. The server holds this lock while it handles each request, and background
+// tasks hold it while they run, so direct callers should hold it too if the server is running.
+func (m *NodeSetMockManager) Lock() {
+	m.lock.Lock()
+}
+
+// Unlocks the manager
+func (m *NodeSetMockManager) Unlock() {
+	m.lock.Unlock()
+}
+
+// Set the database for the manager directly if you need to custom provision it. Its contents replace the store's.
+func (m *NodeSetMockManager) SetDatabase(db *db.Database) error {
+	db.SetClock(m.clock)
+	err := m.store.SetDatabase(db)
+	if err != nil {
+		return fmt.Errorf("error writing database to the store: %w", err)
+	}
+	return nil
+}
+
+// Set the store that holds the manager's state, replacing the default in-memory one. The caller is responsible for
+// closing the previous store if it needs to be.
+func (m *NodeSetMockManager) SetStore(store db.Store) {
+	store.SetClock(m.clock)
+	m.store = store
+}
+
+// Get the store that holds the manager's state
+func (m *NodeSetMockManager) GetStore() db.Store {
+	return m.store
+}
+
+// Set the private key used to sign Constellation whitelist and deposit messages
+func (m *NodeSetMockManager) SetConstellationAdminPrivateKey(key *ecdsa.PrivateKey) {
+	m.constellationAdminKey = key
 }
 
-// Set the database for the manager directly if you need to custom provision it
-func (m *NodeSetMockManager) SetDatabase(db *db.Database) {
-	m.database = db
+// Get the address of the key used to sign Constellation whitelist and deposit messages
+func (m *NodeSetMockManager) GetConstellationAdminAddress() common.Address {
+	return crypto.PubkeyToAddress(m.constellationAdminKey.PublicKey)
 }
 
-// Take a snapshot of the current database state
-func (m *NodeSetMockManager) TakeSnapshot(name string) {
-	m.snapshots[name] = m.database.Clone()
+// Take a snapshot of the current database state, including the audit log
+func (m *NodeSetMockManager) TakeSnapshot(name string) error {
+	err := m.store.TakeSnapshot(name)
+	if err != nil {
+		return fmt.Errorf("error taking snapshot [%s]: %w", name, err)
+	}
 	m.logger.Info("Took DB snapshot", "name", name)
+	return nil
 }
 
-// Revert to a snapshot of the database state
+// Revert to a snapshot of the database state, including the audit log
 func (m *NodeSetMockManager) RevertToSnapshot(name string) error {
-	snapshot, exists := m.snapshots[name]
-	if !exists {
-		return fmt.Errorf("snapshot with name [%s] does not exist", name)
+	err := m.store.RevertToSnapshot(name)
+	if err != nil {
+		return err
 	}
-	m.database = snapshot
 	m.logger.Info("Reverted to DB snapshot", "name", name)
 	return nil
 }
 
+// Get the names of all of the snapshots that have been taken, in alphabetical order
+func (m *NodeSetMockManager) GetSnapshotNames() []string {
+	return m.store.GetSnapshotNames()
+}
+
 // ================
 // === Database ===
 // ================
 
 // Adds a StakeWise vault
 func (m *NodeSetMockManager) AddStakeWiseVault(address common.Address, networkName string) error {
-	return m.database.AddStakeWiseVault(address, networkName)
+	return m.store.AddStakeWiseVault(address, networkName)
+}
+
+// Adds several StakeWise vaults on a network as a transaction, so none of them are added if any of them can't be.
+// The error reports the problem with each one that couldn't be added.
+func (m *NodeSetMockManager) AddStakeWiseVaults(addresses []common.Address, networkName string) error {
+	return m.RunTransaction(func() error {
+		var batchErr db.BatchError
+		for i, address := range addresses {
+			err := m.AddStakeWiseVault(address, networkName)
+			if err != nil {
+				batchErr.Add(i, err)
+			}
+		}
+		return batchErr.Err()
+	})
+}
+
+// Gets all of the users, in the order they were added
+func (m *NodeSetMockManager) GetUsers() []*db.User {
+	return m.store.GetUsers()
+}
+
+// Gets all of the StakeWise vaults, keyed by network
+func (m *NodeSetMockManager) GetStakeWiseVaults() map[string][]*db.StakeWiseVault {
+	return m.store.GetStakeWiseVaults()
 }
 
 // Gets a StakeWise vault
 func (m *NodeSetMockManager) GetStakeWiseVault(address common.Address, networkName string) *db.StakeWiseVault {
-	return m.database.GetStakeWiseVault(address, networkName)
+	return m.store.GetStakeWiseVault(address, networkName)
 }
 
 // Adds a user to the database
 func (m *NodeSetMockManager) AddUser(email string) error {
-	return m.database.AddUser(email)
+	err := m.store.AddUser(email)
+	if err != nil {
+		return err
+	}
+	m.publishEvent(api.EventType_UserAdded, api.UserAddedEventData{
+		Email: email,
+	})
+	return nil
+}
+
+// Adds several users to the database as a transaction, so none of them are added if any of them can't be. The error
+// reports the problem with each one that couldn't be added.
+func (m *NodeSetMockManager) AddUsers(emails []string) error {
+	return m.RunTransaction(func() error {
+		var batchErr db.BatchError
+		for i, email := range emails {
+			err := m.AddUser(email)
+			if err != nil {
+				batchErr.Add(i, err)
+			}
+		}
+		return batchErr.Err()
+	})
 }
 
 // Whitelists a node with a user
 func (m *NodeSetMockManager) WhitelistNodeAccount(email string, nodeAddress common.Address) error {
-	return m.database.WhitelistNodeAccount(email, nodeAddress)
+	err := m.store.WhitelistNodeAccount(email, nodeAddress)
+	if err != nil {
+		return err
+	}
+	m.publishEvent(api.EventType_NodeWhitelisted, getNodeEventData(email, nodeAddress))
+	return nil
+}
+
+// Whitelists several nodes with a user as a transaction, so none of them are whitelisted if any of them can't be.
+// The error reports the problem with each one that couldn't be whitelisted.
+func (m *NodeSetMockManager) WhitelistNodeAccounts(email string, nodeAddresses []common.Address) error {
+	return m.RunTransaction(func() error {
+		var batchErr db.BatchError
+		for i, nodeAddress := range nodeAddresses {
+			err := m.WhitelistNodeAccount(email, nodeAddress)
+			if err != nil {
+				batchErr.Add(i, err)
+			}
+		}
+		return batchErr.Err()
+	})
 }
 
 // Registers a whitelisted node with a user
@@ -90,12 +276,48 @@ func (m *NodeSetMockManager) RegisterNodeAccount(email string, nodeAddress commo
 	}
 
 	// Try to register the node
-	return m.database.RegisterNodeAccount(email, nodeAddress)
+	err = m.store.RegisterNodeAccount(email, nodeAddress)
+	if err != nil {
+		return err
+	}
+	m.publishEvent(api.EventType_NodeRegistered, getNodeEventData(email, nodeAddress))
+	return nil
+}
+
+// Sets the max number of Constellation minipools a user's nodes can have on each deployment (0 for no limit)
+func (m *NodeSetMockManager) SetMinipoolLimit(email string, limit int) error {
+	return m.store.SetMinipoolLimit(email, limit)
+}
+
+// Creates a Constellation whitelist signature for a registered node
+func (m *NodeSetMockManager) WhitelistNodeForConstellation(nodeAddress common.Address, deployment string) ([]byte, error) {
+	signature, err := auth.GetConstellationWhitelistSignature(nodeAddress, m.constellationAdminKey)
+	if err != nil {
+		return nil, err
+	}
+	err = m.store.WhitelistNodeForConstellation(nodeAddress, deployment, signature)
+	if err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// Creates a Constellation minipool deposit signature for a whitelisted node, enforcing its user's minipool limit
+func (m *NodeSetMockManager) CreateMinipoolDepositSignature(nodeAddress common.Address, deployment string, minipoolAddress common.Address, salt *big.Int) ([]byte, error) {
+	signature, err := auth.GetConstellationDepositSignature(minipoolAddress, salt, nodeAddress, m.constellationAdminKey)
+	if err != nil {
+		return nil, err
+	}
+	err = m.store.AddMinipool(nodeAddress, deployment, minipoolAddress, salt, signature)
+	if err != nil {
+		return nil, err
+	}
+	return signature, nil
 }
 
 // Creates a new session and returns the nonce for it
 func (m *NodeSetMockManager) CreateSession() *db.Session {
-	return m.database.CreateSession()
+	return m.store.CreateSession()
 }
 
 // Logs a session in
@@ -107,17 +329,31 @@ func (m *NodeSetMockManager) Login(nonce string, nodeAddress common.Address, sig
 	}
 
 	// Log the session in
-	return m.database.Login(nodeAddress, nonce)
+	err = m.store.Login(nodeAddress, nonce)
+	if err != nil {
+		return err
+	}
+	if m.sessionPolicy.RevokeOldSessions {
+		session := m.store.GetSessionByNonce(nonce)
+		revoked := m.store.RevokeSessions(nodeAddress, session.Token)
+		if revoked > 0 {
+			m.logger.Info("Revoked old sessions", "address", nodeAddress.Hex(), "count", revoked)
+		}
+	}
+	m.publishEvent(api.EventType_SessionLogin, api.SessionLoginEventData{
+		NodeAddress: nodeAddress.Hex(),
+	})
+	return nil
 }
 
 // Gets a session by nonce
 func (m *NodeSetMockManager) GetSessionByNonce(nonce string) *db.Session {
-	return m.database.GetSessionByNonce(nonce)
+	return m.store.GetSessionByNonce(nonce)
 }
 
 // Gets a session by token
 func (m *NodeSetMockManager) GetSessionByToken(token string) *db.Session {
-	return m.database.GetSessionByToken(token)
+	return m.store.GetSessionByToken(token)
 }
 
 // Verifies a request's session and returns the node address the session belongs to
@@ -128,7 +364,7 @@ func (m *NodeSetMockManager) VerifyRequest(r *http.Request) (*db.Session, error)
 	}
 
 	// Get the session
-	session := m.database.GetSessionByToken(token)
+	session := m.store.GetSessionByToken(token)
 	if session == nil {
 		return nil, ErrInvalidSession
 	}
@@ -137,39 +373,27 @@ func (m *NodeSetMockManager) VerifyRequest(r *http.Request) (*db.Session, error)
 
 // Get a node by address - returns true if registered, false if just whitelisted
 func (m *NodeSetMockManager) GetNode(address common.Address) (*db.Node, bool) {
-	return m.database.GetNode(address)
+	return m.store.GetNode(address)
 }
 
 // Get the StakeWise status of a validator
 func (m *NodeSetMockManager) GetValidatorStatus(network string, pubkey beacon.ValidatorPubkey) api.StakeWiseStatus {
-	vaults, exists := m.database.StakeWiseVaults[network]
+	vaults, exists := m.store.GetStakeWiseVaults()[network]
 	if !exists {
 		return api.StakeWiseStatus_Pending
 	}
 
 	// Get the validator for this pubkey
-	var validator *db.Validator
-	for _, user := range m.database.Users {
-		for _, node := range user.RegisteredNodes {
-			validators, exists := node.Validators[network]
-			if !exists {
-				continue
-			}
-			for _, candidate := range validators {
-				if candidate.Pubkey == pubkey {
-					validator = candidate
-					break
-				}
-			}
-		}
-		if validator != nil {
-			break
-		}
-	}
+	validator := m.store.GetValidator(network, pubkey)
 	if validator == nil {
 		return api.StakeWiseStatus_Pending
 	}
 
+	// Check if the validator has left the Beacon Chain
+	if validator.IsExited(m.GetBeaconEpoch()) {
+		return api.StakeWiseStatus_Removed
+	}
+
 	// Check if the StakeWise vault has already seen it
 	for _, vault := range vaults {
 		if vault.Address == validator.VaultAddress && vault.UploadedData[validator.Pubkey] {
@@ -188,30 +412,94 @@ func (m *NodeSetMockManager) GetValidatorStatus(network string, pubkey beacon.Va
 
 // Handle a new collection of deposit data uploads from a node
 func (m *NodeSetMockManager) HandleDepositDataUpload(nodeAddress common.Address, data []beacon.ExtendedDepositData) error {
-	return m.database.HandleDepositDataUpload(nodeAddress, data)
-}
-
-// Handle a new collection of signed exits from a node
-func (m *NodeSetMockManager) HandleSignedExitUpload(nodeAddress common.Address, network string, data []api.ExitData) error {
-	return m.database.HandleSignedExitUpload(nodeAddress, network, data)
+	err := m.store.HandleDepositDataUpload(nodeAddress, data)
+	if err != nil {
+		return err
+	}
+	m.publishEvent(api.EventType_DepositDataUploaded, api.DepositDataUploadedEventData{
+		NodeAddress: nodeAddress.Hex(),
+		Pubkeys:     getPubkeys(data),
+	})
+	return nil
 }
 
 // Create a new deposit data set
 func (m *NodeSetMockManager) CreateNewDepositDataSet(network string, validatorsPerUser int) []beacon.ExtendedDepositData {
-	return m.database.CreateNewDepositDataSet(network, validatorsPerUser)
+	return m.store.CreateNewDepositDataSet(network, validatorsPerUser)
+}
+
+// Create a new deposit data set, using the policy to choose which validators go into it
+func (m *NodeSetMockManager) CreateNewDepositDataSetWithPolicy(network string, policy db.SetSelectionPolicy, params db.SetSelectionParams) []beacon.ExtendedDepositData {
+	return m.store.CreateNewDepositDataSetWithPolicy(network, policy, params)
+}
+
+// Creates a new deposit data set with the policy, "uploads" it to the StakeWise vault, and marks it as uploaded
+func (m *NodeSetMockManager) CycleDepositDataSet(vaultAddress common.Address, network string, policy db.SetSelectionPolicy, params db.SetSelectionParams) ([]beacon.ExtendedDepositData, error) {
+	err := policy.Validate(params)
+	if err != nil {
+		return nil, err
+	}
+	vault := m.store.GetStakeWiseVault(vaultAddress, network)
+	if vault == nil {
+		return nil, fmt.Errorf("%w: address [%s] on network [%s]", db.ErrVaultNotFound, vaultAddress.Hex(), network)
+	}
+
+	// Create a new deposit data set
+	set := m.store.CreateNewDepositDataSetWithPolicy(network, policy, params)
+	m.logger.Info("Created new deposit data set", "network", network, "size", len(set))
+
+	// Upload it
+	err = m.UploadDepositDataToStakeWise(vaultAddress, network, set)
+	if err != nil {
+		return nil, err
+	}
+	m.logger.Info("Uploaded deposit data set", "vault", vaultAddress.Hex())
+
+	// Mark it as uploaded
+	err = m.MarkDepositDataSetUploaded(vaultAddress, network, set)
+	if err != nil {
+		return nil, err
+	}
+	m.logger.Info("Marked deposit data set as uploaded", "version", vault.LatestDepositDataSetIndex)
+	return set, nil
 }
 
 // Call this to "upload" a deposit data set to StakeWise
 func (m *NodeSetMockManager) UploadDepositDataToStakeWise(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error {
-	return m.database.UploadDepositDataToStakeWise(vaultAddress, network, data)
+	statuses := m.getValidatorStatuses(getTrackedValidators(data))
+	err := m.store.UploadDepositDataToStakeWise(vaultAddress, network, data)
+	if err != nil {
+		return err
+	}
+	m.publishStatusChanges(statuses)
+	return nil
 }
 
 // Call this once a deposit data set has been "uploaded" to StakeWise
 func (m *NodeSetMockManager) MarkDepositDataSetUploaded(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error {
-	return m.database.MarkDepositDataSetUploaded(vaultAddress, network, data)
+	statuses := m.getValidatorStatuses(getTrackedValidators(data))
+	err := m.store.MarkDepositDataSetUploaded(vaultAddress, network, data)
+	if err != nil {
+		return err
+	}
+	vault := m.store.GetStakeWiseVault(vaultAddress, network)
+	m.publishEvent(api.EventType_SetCycled, api.SetCycledEventData{
+		Network: network,
+		Vault:   vaultAddress.Hex(),
+		Version: vault.LatestDepositDataSetIndex,
+		Pubkeys: getPubkeys(data),
+	})
+	m.publishStatusChanges(statuses)
+	return nil
 }
 
 // Call this once a deposit data set has been "registered" to StakeWise
 func (m *NodeSetMockManager) MarkValidatorsRegistered(vaultAddress common.Address, network string, data []beacon.ExtendedDepositData) error {
-	return m.database.MarkValidatorsRegistered(vaultAddress, network, data)
+	statuses := m.getValidatorStatuses(getTrackedValidators(data))
+	err := m.store.MarkValidatorsRegisteredAtEpoch(vaultAddress, network, data, m.GetBeaconEpoch())
+	if err != nil {
+		return err
+	}
+	m.publishStatusChanges(statuses)
+	return nil
 }
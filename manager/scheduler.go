@@ -0,0 +1,281 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/rocket-pool/node-manager-core/log"
+)
+
+// Settings for automatically cycling a StakeWise vault's deposit data sets
+type CycleSchedule struct {
+	// Cycle a new set this often (0 to disable)
+	Interval time.Duration
+
+	// Cycle a new set whenever at least this many validators are pending (0 to disable)
+	PendingThreshold int
+
+	// The name of the selection policy to build each set with
+	Policy string
+
+	// The limits to build each set with
+	Params db.SetSelectionParams
+
+	// Mark the validators in each set as registered this long after the set is cycled (0 to disable)
+	RegisterDelay time.Duration
+}
+
+// A cycle schedule for a specific vault
+type VaultCycleSchedule struct {
+	CycleSchedule
+	VaultAddress common.Address
+	Network      string
+}
+
+// Identifies a vault
+type vaultKey struct {
+	address common.Address
+	network string
+}
+
+// The scheduler's state for a vault
+type vaultScheduleState struct {
+	// True if the schedule was set explicitly for this vault instead of coming from the default
+	explicit bool
+
+	schedule      CycleSchedule
+	lastCycle     time.Time
+	registrations []pendingRegistration
+}
+
+// How many times the scheduler tries to mark a cycled set as registered before giving up on it
+const maxRegistrationAttempts int = 5
+
+// A cycled set waiting to be marked as registered
+type pendingRegistration struct {
+	set          []beacon.ExtendedDepositData
+	registerTime time.Time
+
+	// The number of times marking the set as registered has failed
+	failedAttempts int
+}
+
+// Sets the cycle schedule for a vault, replacing the default schedule for it
+func (m *NodeSetMockManager) SetCycleSchedule(vaultAddress common.Address, network string, schedule CycleSchedule) error {
+	vault := m.store.GetStakeWiseVault(vaultAddress, network)
+	if vault == nil {
+		return fmt.Errorf("%w: address [%s] on network [%s]", db.ErrVaultNotFound, vaultAddress.Hex(), network)
+	}
+	err := validateCycleSchedule(schedule)
+	if err != nil {
+		return err
+	}
+
+	key := vaultKey{address: vaultAddress, network: network}
+	state, exists := m.schedules[key]
+	if !exists {
+		state = &vaultScheduleState{}
+		m.schedules[key] = state
+	}
+	state.explicit = true
+	state.schedule = schedule
+	state.lastCycle = m.clock.Now()
+	return nil
+}
+
+// Removes the cycle schedule for a vault, so it goes back to the default schedule
+func (m *NodeSetMockManager) ClearCycleSchedule(vaultAddress common.Address, network string) {
+	delete(m.schedules, vaultKey{address: vaultAddress, network: network})
+}
+
+// Sets the cycle schedule used for vaults that don't have their own. Use nil to disable it.
+func (m *NodeSetMockManager) SetDefaultCycleSchedule(schedule *CycleSchedule) error {
+	if schedule != nil {
+		err := validateCycleSchedule(*schedule)
+		if err != nil {
+			return err
+		}
+	}
+	m.defaultSchedule = schedule
+
+	// Reset the vaults using the default
+	for key, state := range m.schedules {
+		if !state.explicit {
+			delete(m.schedules, key)
+		}
+	}
+	return nil
+}
+
+// Gets the cycle schedule used for vaults that don't have their own, or nil if there isn't one
+func (m *NodeSetMockManager) GetDefaultCycleSchedule() *CycleSchedule {
+	return m.defaultSchedule
+}
+
+// Gets the cycle schedules that have been set for specific vaults
+func (m *NodeSetMockManager) GetCycleSchedules() []VaultCycleSchedule {
+	schedules := []VaultCycleSchedule{}
+	for key, state := range m.schedules {
+		if !state.explicit {
+			continue
+		}
+		schedules = append(schedules, VaultCycleSchedule{
+			CycleSchedule: state.schedule,
+			VaultAddress:  key.address,
+			Network:       key.network,
+		})
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Network != schedules[j].Network {
+			return schedules[i].Network < schedules[j].Network
+		}
+		return schedules[i].VaultAddress.Hex() < schedules[j].VaultAddress.Hex()
+	})
+	return schedules
+}
+
+// Starts running the cycle schedules in the background, checking them at the provided interval
+func (m *NodeSetMockManager) StartScheduler(pollInterval time.Duration) {
+	if m.schedulerStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	m.schedulerStop = stop
+	m.schedulerDone = done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				m.RunScheduledTasks()
+			}
+		}
+	}()
+}
+
+// Stops running the cycle schedules in the background
+func (m *NodeSetMockManager) StopScheduler() {
+	if m.schedulerStop == nil {
+		return
+	}
+	close(m.schedulerStop)
+	<-m.schedulerDone
+	m.schedulerStop = nil
+	m.schedulerDone = nil
+}
+
+// Runs any cycles and registrations that are due. This is called periodically by the scheduler, but can be called
+// directly to run them deterministically; stop the scheduler first so it doesn't run them too. Unlike most of the
+// manager's methods, this takes the manager's lock itself, so callers must not hold it.
+func (m *NodeSetMockManager) RunScheduledTasks() {
+	m.Lock()
+	defer m.Unlock()
+
+	now := m.clock.Now()
+	for network, vaults := range m.store.GetStakeWiseVaults() {
+		for _, vault := range vaults {
+			key := vaultKey{address: vault.Address, network: network}
+			state, exists := m.schedules[key]
+			if !exists {
+				if m.defaultSchedule == nil {
+					continue
+				}
+				state = &vaultScheduleState{
+					schedule:  *m.defaultSchedule,
+					lastCycle: now,
+				}
+				m.schedules[key] = state
+			}
+			m.runVaultSchedule(key, state, now)
+		}
+	}
+}
+
+// Runs any cycle and registrations that are due for a vault
+func (m *NodeSetMockManager) runVaultSchedule(key vaultKey, state *vaultScheduleState, now time.Time) {
+	schedule := state.schedule
+
+	// Check if a cycle is due
+	due := false
+	if schedule.Interval > 0 && now.Sub(state.lastCycle) >= schedule.Interval {
+		due = true
+	}
+	if schedule.PendingThreshold > 0 && m.store.GetPendingValidatorCount(key.network) >= schedule.PendingThreshold {
+		due = true
+	}
+	if due {
+		policy, _ := db.GetSetSelectionPolicy(schedule.Policy)
+		set, err := m.CycleDepositDataSet(key.address, key.network, policy, schedule.Params)
+		m.addSchedulerAuditEntry("cycle-set", key, err)
+		if err != nil {
+			m.logger.Error("Error running scheduled deposit data set cycle", "vault", key.address.Hex(), "network", key.network, log.Err(err))
+		} else {
+			state.lastCycle = now
+			if schedule.RegisterDelay > 0 && len(set) > 0 {
+				state.registrations = append(state.registrations, pendingRegistration{
+					set:          set,
+					registerTime: now.Add(schedule.RegisterDelay),
+				})
+			}
+		}
+	}
+
+	// Mark any sets that are due as registered
+	remaining := []pendingRegistration{}
+	for _, registration := range state.registrations {
+		if now.Before(registration.registerTime) {
+			remaining = append(remaining, registration)
+			continue
+		}
+		err := m.MarkValidatorsRegistered(key.address, key.network, registration.set)
+		m.addSchedulerAuditEntry("register", key, err)
+		if err != nil {
+			// Keep the set so it's retried on the next run, unless it's failed too many times already
+			registration.failedAttempts++
+			if registration.failedAttempts < maxRegistrationAttempts {
+				m.logger.Warn("Error marking scheduled validators as registered, will retry", "vault", key.address.Hex(), "network", key.network, "attempts", registration.failedAttempts, log.Err(err))
+				remaining = append(remaining, registration)
+			} else {
+				m.logger.Error("Error marking scheduled validators as registered, giving up", "vault", key.address.Hex(), "network", key.network, "attempts", registration.failedAttempts, log.Err(err))
+			}
+			continue
+		}
+		m.logger.Info("Marked scheduled validators as registered", "vault", key.address.Hex(), "count", len(registration.set))
+	}
+	state.registrations = remaining
+}
+
+// Records a scheduled operation on a vault in the audit log
+func (m *NodeSetMockManager) addSchedulerAuditEntry(operation string, key vaultKey, err error) {
+	entry := db.AuditEntry{
+		Actor:   db.AuditActor_Scheduler,
+		Route:   fmt.Sprintf("scheduled %s for vault %s on %s", operation, key.address.Hex(), key.network),
+		Success: err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	m.store.AddAuditEntry(entry)
+}
+
+// Makes sure a cycle schedule is usable
+func validateCycleSchedule(schedule CycleSchedule) error {
+	if schedule.Interval < 0 || schedule.PendingThreshold < 0 || schedule.RegisterDelay < 0 {
+		return fmt.Errorf("cycle schedule values can't be negative")
+	}
+	policy, err := db.GetSetSelectionPolicy(schedule.Policy)
+	if err != nil {
+		return err
+	}
+	return policy.Validate(schedule.Params)
+}
@@ -0,0 +1,57 @@
+package manager
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure a scheduled registration that fails is retried on the next run instead of being dropped
+func TestScheduledRegistrationRetry(t *testing.T) {
+	m := NewNodeSetMockManager(slog.Default())
+	key := vaultKey{address: test.StakeWiseVaultAddress, network: test.Network}
+	now := m.clock.Now()
+	state := &vaultScheduleState{
+		explicit:  true,
+		lastCycle: now,
+		registrations: []pendingRegistration{
+			{
+				set:          []beacon.ExtendedDepositData{},
+				registerTime: now,
+			},
+		},
+	}
+
+	// Registering fails since the vault doesn't exist yet, so the set should be kept
+	m.runVaultSchedule(key, state, now)
+	require.Len(t, state.registrations, 1)
+	require.Equal(t, 1, state.registrations[0].failedAttempts)
+	t.Log("Failed registration was kept")
+
+	// Once the vault exists, the retry should succeed
+	require.NoError(t, m.AddStakeWiseVault(test.StakeWiseVaultAddress, test.Network))
+	m.runVaultSchedule(key, state, now.Add(time.Second))
+	require.Empty(t, state.registrations)
+	t.Log("Retried registration succeeded")
+
+	// A set that keeps failing should eventually be given up on
+	key.network = "unknown"
+	state.registrations = []pendingRegistration{
+		{
+			set:          []beacon.ExtendedDepositData{},
+			registerTime: now,
+		},
+	}
+	for i := 1; i < maxRegistrationAttempts; i++ {
+		m.runVaultSchedule(key, state, now)
+		require.Len(t, state.registrations, 1)
+		require.Equal(t, i, state.registrations[0].failedAttempts)
+	}
+	m.runVaultSchedule(key, state, now)
+	require.Empty(t, state.registrations)
+	t.Log("Registration was dropped after too many failures")
+}
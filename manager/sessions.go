@@ -0,0 +1,45 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+)
+
+// Settings for how nonces are issued and how sessions are logged in
+type SessionPolicy struct {
+	// How long a nonce can be used to log in after it's issued (0 for no expiration)
+	NonceLifetime time.Duration
+
+	// The max number of unused, unexpired nonces each client can have at once (0 for no limit)
+	MaxNoncesPerClient int
+
+	// If true, logging a node in revokes all of its other sessions
+	RevokeOldSessions bool
+}
+
+// Set the policy for issuing nonces and logging sessions in
+func (m *NodeSetMockManager) SetSessionPolicy(policy SessionPolicy) error {
+	if policy.NonceLifetime < 0 || policy.MaxNoncesPerClient < 0 {
+		return fmt.Errorf("session policy values can't be negative")
+	}
+	m.sessionPolicy = policy
+	return nil
+}
+
+// Get the policy for issuing nonces and logging sessions in
+func (m *NodeSetMockManager) GetSessionPolicy() SessionPolicy {
+	return m.sessionPolicy
+}
+
+// Creates a new session for a client according to the session policy, and returns the nonce for it. If the bound
+// address isn't the zero address, only that node can use the nonce to log in.
+func (m *NodeSetMockManager) CreateClientSession(clientId string, boundAddress common.Address) (*db.Session, error) {
+	var expiresTime time.Time
+	if m.sessionPolicy.NonceLifetime > 0 {
+		expiresTime = m.clock.Now().Add(m.sessionPolicy.NonceLifetime)
+	}
+	return m.store.CreateClientSession(clientId, boundAddress, expiresTime, m.sessionPolicy.MaxNoncesPerClient)
+}
@@ -0,0 +1,66 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/nodeset-org/nodeset-svc-mock/clock"
+)
+
+// Set the clock used by the manager, such as one shared with other mocks in the same test
+func (m *NodeSetMockManager) SetClock(newClock *clock.Clock) {
+	m.clock = newClock
+	m.store.SetClock(newClock)
+}
+
+// Get the clock used by the manager
+func (m *NodeSetMockManager) GetClock() *clock.Clock {
+	return m.clock
+}
+
+// Get the current time according to the manager's clock
+func (m *NodeSetMockManager) GetTime() time.Time {
+	return m.clock.Now()
+}
+
+// Stop the manager's clock at the current time
+func (m *NodeSetMockManager) FreezeTime() {
+	m.clock.Freeze()
+	m.logger.Info("Froze time", "time", m.clock.Now())
+}
+
+// Start the manager's clock again from the time it was frozen at
+func (m *NodeSetMockManager) UnfreezeTime() {
+	statuses := m.getValidatorStatuses(m.getExitingValidators())
+	defer m.publishStatusChanges(statuses)
+	m.clock.Unfreeze()
+	m.logger.Info("Unfroze time", "time", m.clock.Now())
+}
+
+// Set the current time of the manager's clock
+func (m *NodeSetMockManager) SetTime(now time.Time) {
+	statuses := m.getValidatorStatuses(m.getExitingValidators())
+	defer m.publishStatusChanges(statuses)
+	m.clock.Set(now)
+	m.logger.Info("Set time", "time", now)
+}
+
+// Move the manager's clock forward by the provided duration. Like the other methods that move the clock, this publishes
+// status change events for validators that exit as a result. Time passing on its own while the clock is running doesn't.
+func (m *NodeSetMockManager) AdvanceTime(duration time.Duration) error {
+	statuses := m.getValidatorStatuses(m.getExitingValidators())
+	defer m.publishStatusChanges(statuses)
+	err := m.clock.Advance(duration)
+	if err != nil {
+		return err
+	}
+	m.logger.Info("Advanced time", "duration", duration, "time", m.clock.Now())
+	return nil
+}
+
+// Unfreeze the manager's clock and put it back in line with the system clock
+func (m *NodeSetMockManager) ResetTime() {
+	statuses := m.getValidatorStatuses(m.getExitingValidators())
+	defer m.publishStatusChanges(statuses)
+	m.clock.Reset()
+	m.logger.Info("Reset time", "time", m.clock.Now())
+}
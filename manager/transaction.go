@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+// An event published during a transaction, which is held back until the transaction succeeds
+type pendingEvent struct {
+	eventType api.EventType
+	data      any
+}
+
+// Runs an operation as a transaction, so batch operations are all-or-nothing. If the operation fails, the store is
+// rolled back to the state it was in before the operation started and the events the operation published are
+// dropped; otherwise the events are published once it finishes. An operation that panics or exits its goroutine is
+// rolled back too, and the panic is re-raised afterward. Transactions can be nested, in which case a failed inner
+// transaction only rolls back its own changes. Callers should hold the manager's lock.
+func (m *NodeSetMockManager) RunTransaction(operation func() error) (err error) {
+	backup := m.store.Clone()
+	m.eventsLock.Lock()
+	outermost := !m.inTransaction
+	m.inTransaction = true
+	eventCount := len(m.pendingEvents)
+	m.eventsLock.Unlock()
+
+	completed := false
+	defer func() {
+		recovered := recover()
+		failed := !completed || err != nil
+
+		// Drop the operation's events if it failed, and take the held events if this is the outermost transaction
+		m.eventsLock.Lock()
+		if failed {
+			m.pendingEvents = m.pendingEvents[:eventCount]
+		}
+		var events []pendingEvent
+		if outermost {
+			events = m.pendingEvents
+			m.pendingEvents = nil
+			m.inTransaction = false
+		}
+		m.eventsLock.Unlock()
+
+		// Roll back or publish the events
+		if failed {
+			rollbackErr := m.store.SetDatabase(backup)
+			if rollbackErr != nil {
+				if !completed {
+					m.logger.Error("Error rolling back transaction", "error", rollbackErr)
+				} else {
+					err = errors.Join(err, fmt.Errorf("error rolling back transaction: %w", rollbackErr))
+				}
+			}
+			if recovered != nil {
+				panic(recovered)
+			}
+			return
+		}
+		for _, event := range events {
+			m.publishEvent(event.eventType, event.data)
+		}
+	}()
+
+	err = operation()
+	completed = true
+	return err
+}
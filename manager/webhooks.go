@@ -0,0 +1,340 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/rocket-pool/node-manager-core/log"
+)
+
+const (
+	// The number of events buffered for each webhook while earlier ones are being delivered
+	webhookBufferSize int = 256
+
+	// The max number of deliveries kept in the delivery log
+	maxWebhookDeliveries int = 1000
+
+	// The timeout for each delivery attempt
+	webhookRequestTimeout time.Duration = 10 * time.Second
+)
+
+// Controls how failed webhook deliveries are retried
+type WebhookRetryPolicy struct {
+	// The max number of times to try each delivery, including the first attempt
+	MaxAttempts int
+
+	// The delay before the first retry, which doubles after each one
+	InitialBackoff time.Duration
+
+	// The max delay between retries
+	MaxBackoff time.Duration
+}
+
+// Gets the retry policy used for webhooks that don't set their own
+func DefaultWebhookRetryPolicy() WebhookRetryPolicy {
+	return WebhookRetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// A URL that events are POSTed to as they happen
+type Webhook struct {
+	// Assigned when the webhook is added
+	ID uint64
+
+	// The URL to POST the events to
+	URL string
+
+	// The key used to sign each delivery. Deliveries aren't signed if this is empty.
+	Secret string
+
+	// The event types to deliver, or empty for all of them
+	Types []api.EventType
+
+	// How failed deliveries are retried
+	RetryPolicy WebhookRetryPolicy
+}
+
+// The result of delivering an event to a webhook
+type WebhookDelivery struct {
+	// When the last attempt finished
+	Time time.Time
+
+	WebhookID uint64
+	URL       string
+	EventID   uint64
+	EventType api.EventType
+
+	// The number of attempts made
+	Attempts int
+
+	// The status code of the last attempt, or 0 if it didn't get a response
+	StatusCode int
+
+	// True if the event was delivered
+	Success bool
+
+	// The error from the last attempt if it failed
+	Error string
+}
+
+// A running webhook
+type webhookState struct {
+	webhook      Webhook
+	subscription *EventSubscription
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+// Adds a webhook and starts delivering events to it. The provided webhook's ID is ignored; the returned one has the
+// ID that was assigned.
+func (m *NodeSetMockManager) AddWebhook(webhook Webhook) (Webhook, error) {
+	err := validateWebhook(&webhook)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	m.webhooksLock.Lock()
+	defer m.webhooksLock.Unlock()
+	m.lastWebhookId++
+	webhook.ID = m.lastWebhookId
+	state := &webhookState{
+		webhook:      webhook,
+		subscription: m.SubscribeToEvents(webhookBufferSize),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	m.webhooks[webhook.ID] = state
+	go m.runWebhook(state)
+	m.logger.Info("Added webhook", "id", webhook.ID, "url", webhook.URL)
+	return webhook, nil
+}
+
+// Removes a webhook, cancelling any delivery that's in progress
+func (m *NodeSetMockManager) RemoveWebhook(id uint64) error {
+	m.webhooksLock.Lock()
+	state, exists := m.webhooks[id]
+	if !exists {
+		m.webhooksLock.Unlock()
+		return fmt.Errorf("%w: ID [%d]", ErrWebhookNotFound, id)
+	}
+	delete(m.webhooks, id)
+	m.webhooksLock.Unlock()
+
+	// The delivery log needs the lock, so this has to wait until it's released
+	stopWebhook(state)
+	m.logger.Info("Removed webhook", "id", id, "url", state.webhook.URL)
+	return nil
+}
+
+// Gets the webhooks that have been added, in the order they were added
+func (m *NodeSetMockManager) GetWebhooks() []Webhook {
+	m.webhooksLock.Lock()
+	defer m.webhooksLock.Unlock()
+
+	webhooks := make([]Webhook, 0, len(m.webhooks))
+	for _, state := range m.webhooks {
+		webhooks = append(webhooks, state.webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks
+}
+
+// Gets the finished webhook deliveries, oldest first. Only the most recent ones are kept.
+func (m *NodeSetMockManager) GetWebhookDeliveries() []WebhookDelivery {
+	m.webhooksLock.Lock()
+	defer m.webhooksLock.Unlock()
+
+	deliveries := make([]WebhookDelivery, len(m.webhookDeliveries))
+	copy(deliveries, m.webhookDeliveries)
+	return deliveries
+}
+
+// Removes all of the webhooks. This should be called when the manager is no longer needed.
+func (m *NodeSetMockManager) StopWebhooks() {
+	m.webhooksLock.Lock()
+	states := m.webhooks
+	m.webhooks = map[uint64]*webhookState{}
+	m.webhooksLock.Unlock()
+
+	for _, state := range states {
+		stopWebhook(state)
+	}
+}
+
+// Delivers events to a webhook until it's stopped
+func (m *NodeSetMockManager) runWebhook(state *webhookState) {
+	defer close(state.done)
+	for {
+		select {
+		case <-state.stop:
+			return
+		case event, ok := <-state.subscription.Events:
+			if !ok {
+				return
+			}
+			if len(state.webhook.Types) > 0 && !slices.Contains(state.webhook.Types, event.Type) {
+				continue
+			}
+			delivery := m.deliverWebhookEvent(state, event)
+			select {
+			case <-state.stop:
+				// Don't log deliveries that were cancelled
+				return
+			default:
+			}
+			m.addWebhookDelivery(delivery)
+		}
+	}
+}
+
+// Delivers an event to a webhook, retrying with backoff until it succeeds or runs out of attempts
+func (m *NodeSetMockManager) deliverWebhookEvent(state *webhookState, event api.Event) WebhookDelivery {
+	webhook := state.webhook
+	delivery := WebhookDelivery{
+		WebhookID: webhook.ID,
+		URL:       webhook.URL,
+		EventID:   event.ID,
+		EventType: event.Type,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		delivery.Time = m.clock.Now()
+		delivery.Error = fmt.Sprintf("error serializing event: %s", err.Error())
+		return delivery
+	}
+
+	// Cancel any request in flight if the webhook is stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-state.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	backoff := webhook.RetryPolicy.InitialBackoff
+	for {
+		delivery.Attempts++
+		delivery.StatusCode, err = postWebhookEvent(ctx, webhook, event, body)
+		delivery.Time = m.clock.Now()
+		if err == nil {
+			delivery.Success = true
+			delivery.Error = ""
+			m.logger.Info("Delivered webhook event", "url", webhook.URL, "event", event.ID, "type", event.Type, "attempts", delivery.Attempts)
+			return delivery
+		}
+		delivery.Error = err.Error()
+		if delivery.Attempts >= webhook.RetryPolicy.MaxAttempts {
+			m.logger.Error("Failed to deliver webhook event", "url", webhook.URL, "event", event.ID, "type", event.Type, "attempts", delivery.Attempts, log.Err(err))
+			return delivery
+		}
+		m.logger.Warn("Webhook delivery failed, retrying", "url", webhook.URL, "event", event.ID, "attempt", delivery.Attempts, "backoff", backoff, log.Err(err))
+
+		// Wait before trying again
+		select {
+		case <-ctx.Done():
+			return delivery
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > webhook.RetryPolicy.MaxBackoff {
+			backoff = webhook.RetryPolicy.MaxBackoff
+		}
+	}
+}
+
+// Records a finished delivery in the delivery log
+func (m *NodeSetMockManager) addWebhookDelivery(delivery WebhookDelivery) {
+	m.webhooksLock.Lock()
+	defer m.webhooksLock.Unlock()
+
+	m.webhookDeliveries = append(m.webhookDeliveries, delivery)
+	if len(m.webhookDeliveries) > maxWebhookDeliveries {
+		m.webhookDeliveries = m.webhookDeliveries[len(m.webhookDeliveries)-maxWebhookDeliveries:]
+	}
+}
+
+// Sends an event to a webhook once, returning the status code of the response if there was one
+func postWebhookEvent(ctx context.Context, webhook Webhook, event api.Event, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookRequestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(auth.WebhookEventHeader, string(event.Type))
+	request.Header.Set(auth.WebhookDeliveryHeader, strconv.FormatUint(event.ID, 10))
+	if webhook.Secret != "" {
+		request.Header.Set(auth.WebhookSignatureHeader, auth.GetWebhookSignature(body, webhook.Secret))
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("received status code %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Stops a webhook and waits for it to finish
+func stopWebhook(state *webhookState) {
+	close(state.stop)
+	<-state.done
+	state.subscription.Unsubscribe()
+}
+
+// Makes sure a webhook is usable, filling in the default retry policy if it doesn't have one
+func validateWebhook(webhook *Webhook) error {
+	parsedUrl, err := url.Parse(webhook.URL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL [%s]: %w", webhook.URL, err)
+	}
+	if (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return fmt.Errorf("invalid webhook URL [%s]: must be an absolute http or https URL", webhook.URL)
+	}
+	for _, eventType := range webhook.Types {
+		if !slices.Contains(api.EventTypes, eventType) {
+			return fmt.Errorf("unknown event type [%s]", eventType)
+		}
+	}
+
+	policy := &webhook.RetryPolicy
+	if policy.MaxAttempts < 0 || policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+		return fmt.Errorf("webhook retry values can't be negative")
+	}
+	defaultPolicy := DefaultWebhookRetryPolicy()
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = defaultPolicy.MaxAttempts
+	}
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = defaultPolicy.InitialBackoff
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = defaultPolicy.MaxBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package mocktest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/rocket-pool/node-manager-core/utils"
+)
+
+// A client for the mock's node routes that authenticates as a node
+type Client struct {
+	// The base URL of the server, such as http://localhost:12345
+	BaseUrl string
+
+	// The node's wallet key
+	PrivateKey *ecdsa.PrivateKey
+
+	// The node's address
+	NodeAddress common.Address
+
+	// The token of the node's session, which is empty until it logs in
+	Token string
+}
+
+// Creates a new client for the node with the provided key. It needs to log in before using routes that require a
+// session.
+func NewClient(baseUrl string, privateKey *ecdsa.PrivateKey) *Client {
+	return &Client{
+		BaseUrl:     strings.TrimSuffix(baseUrl, "/"),
+		PrivateKey:  privateKey,
+		NodeAddress: crypto.PubkeyToAddress(privateKey.PublicKey),
+	}
+}
+
+// Gets a nonce and logs in with it, using the new session for future requests
+func (c *Client) Login() error {
+	// Get a nonce
+	var nonceData api.NonceData
+	_, err := c.Request(http.MethodGet, "/api/"+api.NoncePath, nil, nil, &nonceData)
+	if err != nil {
+		return fmt.Errorf("error getting nonce: %w", err)
+	}
+	c.Token = nonceData.Token
+
+	// Log in with it
+	signature, err := auth.GetSignatureForLogin(nonceData.Nonce, c.NodeAddress, c.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("error signing login message: %w", err)
+	}
+	request := api.LoginRequest{
+		Nonce:     nonceData.Nonce,
+		Address:   c.NodeAddress.Hex(),
+		Signature: utils.EncodeHexWithPrefix(signature),
+	}
+	var loginData api.LoginData
+	_, err = c.Request(http.MethodPost, "/api/"+api.LoginPath, nil, request, &loginData)
+	if err != nil {
+		return fmt.Errorf("error logging in: %w", err)
+	}
+	if loginData.Token != "" {
+		c.Token = loginData.Token
+	}
+	return nil
+}
+
+// Sends a request to the path (such as /api/deposit-data) with the session's auth header, returning the status code.
+// If body isn't nil, it's serialized as the JSON request body. If data isn't nil and the request succeeds, the
+// response's data is deserialized into it. Requests that don't get a 200 response return an error with the
+// response's message.
+func (c *Client) Request(method string, path string, queryParams map[string]string, body any, data any) (int, error) {
+	// Create the request
+	var bodyReader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("error serializing request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+	request, err := http.NewRequest(method, c.BaseUrl+path, bodyReader)
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+	query := url.Values{}
+	for name, value := range queryParams {
+		query.Set(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+	if c.Token != "" {
+		auth.AddSessionTokenAuthorizationHeader(request, c.Token)
+	}
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %w", err)
+	}
+	defer response.Body.Close()
+	responseBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return response.StatusCode, fmt.Errorf("error reading the response body: %w", err)
+	}
+
+	// Parse the response
+	var parsedResponse api.NodeSetResponse[json.RawMessage]
+	err = json.Unmarshal(responseBytes, &parsedResponse)
+	if response.StatusCode != http.StatusOK {
+		if err == nil && parsedResponse.Message != "" {
+			return response.StatusCode, fmt.Errorf("request failed with status %d: %s", response.StatusCode, parsedResponse.Message)
+		}
+		return response.StatusCode, fmt.Errorf("request failed with status %d", response.StatusCode)
+	}
+	if err != nil {
+		return response.StatusCode, fmt.Errorf("error deserializing response: %w", err)
+	}
+	if data != nil && len(parsedResponse.Data) > 0 {
+		err = json.Unmarshal(parsedResponse.Data, data)
+		if err != nil {
+			return response.StatusCode, fmt.Errorf("error deserializing response data: %w", err)
+		}
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,119 @@
+// Package mocktest starts the nodeset.io mock for Go tests, so consumers don't need their own TestMain boilerplate.
+//
+//	func TestSomething(t *testing.T) {
+//		mock := mocktest.Start(t)
+//		status, err := mock.Client.Request(http.MethodGet, "/api/"+api.DepositDataMetaPath, query, nil, &meta)
+//		...
+//	}
+package mocktest
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+	"github.com/nodeset-org/nodeset-svc-mock/server"
+)
+
+const (
+	// The index of the provisioned node the client is logged in as
+	ClientNodeIndex uint = 0
+
+	// The email of the user that owns the client's node
+	ClientUserEmail string = test.User1Email
+)
+
+// A running mock for a test
+type Mock struct {
+	// The mock server
+	Server *server.NodeSetMockServer
+
+	// The server's manager. Hold its lock while using it directly, since the server is running.
+	Manager *manager.NodeSetMockManager
+
+	// The base URL of the server, such as http://localhost:12345
+	BaseUrl string
+
+	// A client logged in as a provisioned node
+	Client *Client
+}
+
+// Starts a mock on a random port for the test and stops it when the test is done. The database is provisioned with
+// a StakeWise vault, users, registered nodes with uploaded deposit data, and the first deposit data set, and the
+// returned client is logged in as node ClientNodeIndex.
+func Start(t *testing.T) *Mock {
+	t.Helper()
+	logger := slog.Default()
+
+	// Create and start the server
+	mockServer, err := server.NewNodeSetMockServer(logger, "localhost", 0)
+	if err != nil {
+		t.Fatalf("error creating mock server: %v", err)
+	}
+	wg := &sync.WaitGroup{}
+	err = mockServer.Start(wg)
+	if err != nil {
+		t.Fatalf("error starting mock server: %v", err)
+	}
+	t.Cleanup(func() {
+		err := mockServer.Stop()
+		if err != nil {
+			t.Errorf("error stopping mock server: %v", err)
+		}
+		wg.Wait()
+	})
+
+	mock := &Mock{
+		Server:  mockServer,
+		Manager: mockServer.GetManager(),
+		BaseUrl: fmt.Sprintf("http://localhost:%d", mockServer.GetPort()),
+	}
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, true)
+	mock.Manager.Lock()
+	err = mock.Manager.SetDatabase(database)
+	mock.Manager.Unlock()
+	if err != nil {
+		t.Fatalf("error provisioning the database: %v", err)
+	}
+
+	// Log the client in
+	nodeKey, err := test.GetEthPrivateKey(ClientNodeIndex)
+	if err != nil {
+		t.Fatalf("error getting node key: %v", err)
+	}
+	mock.Client = NewClient(mock.BaseUrl, nodeKey)
+	err = mock.Client.Login()
+	if err != nil {
+		t.Fatalf("error logging in as node %s: %v", crypto.PubkeyToAddress(nodeKey.PublicKey).Hex(), err)
+	}
+	return mock
+}
+
+// Isolates the test's changes to the mock by taking a snapshot now and reverting to it when the test is done. This
+// is useful for subtests that share a mock.
+func (m *Mock) Isolate(t *testing.T) {
+	t.Helper()
+	name := "mocktest/" + strings.ReplaceAll(t.Name(), " ", "_")
+	m.Manager.Lock()
+	err := m.Manager.TakeSnapshot(name)
+	m.Manager.Unlock()
+	if err != nil {
+		t.Fatalf("error taking snapshot [%s]: %v", name, err)
+	}
+	t.Cleanup(func() {
+		m.Manager.Lock()
+		defer m.Manager.Unlock()
+		err := m.Manager.RevertToSnapshot(name)
+		if err != nil {
+			t.Errorf("error reverting to snapshot [%s]: %v", name, err)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package mocktest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the mock starts with a logged-in client and isolates subtests
+func TestStart(t *testing.T) {
+	mock := Start(t)
+	query := map[string]string{
+		"network": test.Network,
+		"vault":   test.StakeWiseVaultAddressHex,
+	}
+
+	// Make sure the client is logged in
+	var meta api.DepositDataMetaData
+	status, err := mock.Client.Request(http.MethodGet, "/api/"+api.DepositDataMetaPath, query, nil, &meta)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 1, meta.Version)
+	t.Logf("Client is logged in - deposit data version = %d", meta.Version)
+
+	// Make sure requests without a session fail
+	anonymous := NewClient(mock.BaseUrl, mock.Client.PrivateKey)
+	status, err = anonymous.Request(http.MethodGet, "/api/"+api.DepositDataMetaPath, query, nil, &meta)
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	// Make a change in an isolated subtest
+	t.Run("isolated", func(t *testing.T) {
+		mock.Isolate(t)
+		mock.Manager.Lock()
+		defer mock.Manager.Unlock()
+		err := mock.Manager.AddUser("isolated@nodeset.io")
+		require.NoError(t, err)
+	})
+
+	// Make sure it was reverted
+	mock.Manager.Lock()
+	defer mock.Manager.Unlock()
+	for _, user := range mock.Manager.GetUsers() {
+		require.NotEqual(t, "isolated@nodeset.io", user.Email)
+	}
+	t.Log("Subtest changes were reverted")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/rocket-pool/node-manager-core/utils"
+)
+
+// Client for the API routes of a running mock that authenticates as a node
+type nodeClient struct {
+	baseUrl     string
+	privateKey  *ecdsa.PrivateKey
+	nodeAddress common.Address
+	token       string
+	httpClient  *http.Client
+}
+
+// Creates a client for the node with the provided key
+func newNodeClient(baseUrl string, httpClient *http.Client, privateKey *ecdsa.PrivateKey) *nodeClient {
+	return &nodeClient{
+		baseUrl:     baseUrl,
+		privateKey:  privateKey,
+		nodeAddress: crypto.PubkeyToAddress(privateKey.PublicKey),
+		httpClient:  httpClient,
+	}
+}
+
+// Registers the node with the user
+func (c *nodeClient) register(email string) error {
+	signature, err := auth.GetSignatureForRegistration(email, c.nodeAddress, c.privateKey)
+	if err != nil {
+		return fmt.Errorf("error signing registration message: %w", err)
+	}
+	return c.request(http.MethodPost, api.RegisterPath, nil, api.RegisterNodeRequest{
+		Email:       email,
+		NodeAddress: c.nodeAddress.Hex(),
+		Signature:   utils.EncodeHexWithPrefix(signature),
+	}, nil)
+}
+
+// Gets a nonce bound to the node, using its session for future requests
+func (c *nodeClient) getNonce() (string, error) {
+	var data api.NonceData
+	err := c.request(http.MethodGet, api.NoncePath, map[string]string{
+		"address": c.nodeAddress.Hex(),
+	}, nil, &data)
+	if err != nil {
+		return "", err
+	}
+	c.token = data.Token
+	return data.Nonce, nil
+}
+
+// Logs in with a nonce from getNonce
+func (c *nodeClient) login(nonce string) error {
+	signature, err := auth.GetSignatureForLogin(nonce, c.nodeAddress, c.privateKey)
+	if err != nil {
+		return fmt.Errorf("error signing login message: %w", err)
+	}
+	var data api.LoginData
+	err = c.request(http.MethodPost, api.LoginPath, nil, api.LoginRequest{
+		Nonce:     nonce,
+		Address:   c.nodeAddress.Hex(),
+		Signature: utils.EncodeHexWithPrefix(signature),
+	}, &data)
+	if err != nil {
+		return err
+	}
+	if data.Token != "" {
+		c.token = data.Token
+	}
+	return nil
+}
+
+// Runs a request to an API route with the node's session, serializing body as the request body if it isn't nil and
+// deserializing the response data into data if it isn't nil
+func (c *nodeClient) request(method string, path string, queryParams map[string]string, body any, data any) error {
+	// Create the request
+	var bodyReader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error serializing request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+	request, err := http.NewRequest(method, fmt.Sprintf("%s/api/%s", c.baseUrl, path), bodyReader)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	setQueryParams(request, queryParams)
+	if c.token != "" {
+		auth.AddSessionTokenAuthorizationHeader(request, c.token)
+	}
+	return sendRequest(c.httpClient, request, data)
+}
+
+// Gets the public key nodes must encrypt their signed exits to. This doesn't need a session.
+func getEncryptionPublicKey(baseUrl string, httpClient *http.Client) (string, error) {
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/%s", baseUrl, api.EncryptionIdentityPath), nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	var data api.EncryptionIdentityData
+	err = sendRequest(httpClient, request, &data)
+	if err != nil {
+		return "", err
+	}
+	return data.PublicKey, nil
+}
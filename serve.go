@@ -0,0 +1,443 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"filippo.io/age"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+	"github.com/nodeset-org/nodeset-svc-mock/server"
+	"github.com/rocket-pool/node-manager-core/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	ipFlag = &cli.StringFlag{
+		Name:    "ip",
+		Aliases: []string{"i"},
+		Usage:   "The IP address to bind the API server to",
+		Value:   "127.0.0.1",
+	}
+	portFlag = &cli.UintFlag{
+		Name:    "port",
+		Aliases: []string{"p"},
+		Usage:   "The port to bind the API server to",
+		Value:   49537,
+	}
+
+	unixSocketFlag = &cli.StringFlag{
+		Name:  "unix-socket",
+		Usage: "The path of a Unix domain socket to serve the API on instead of --ip and --port",
+	}
+
+	adminIpFlag = &cli.StringFlag{
+		Name:  "admin-ip",
+		Usage: "The IP address to bind the admin API server to. If this or --admin-port is set, the admin routes are served on their own listener instead of alongside the API routes.",
+		Value: "127.0.0.1",
+	}
+	adminPortFlag = &cli.UintFlag{
+		Name:  "admin-port",
+		Usage: "The port to bind the admin API server to. If this or --admin-ip is set, the admin routes are served on their own listener instead of alongside the API routes.",
+	}
+	adminUnixSocketFlag = &cli.StringFlag{
+		Name:  "admin-unix-socket",
+		Usage: "The path of a Unix domain socket to serve the admin routes on, instead of alongside the API routes",
+	}
+	adminTokenFlag = &cli.StringFlag{
+		Name:    "admin-token",
+		Usage:   "The bearer token required for read-write access to the admin routes. Admin authentication is disabled if neither this nor --admin-read-only-token is set.",
+		EnvVars: []string{"NODESET_MOCK_ADMIN_TOKEN"},
+	}
+	adminReadOnlyTokenFlag = &cli.StringFlag{
+		Name:    "admin-read-only-token",
+		Usage:   "The bearer token required for read-only access to the admin routes. If this is set without --admin-token, the admin routes that modify the mock's state can't be used.",
+		EnvVars: []string{"NODESET_MOCK_ADMIN_READ_ONLY_TOKEN"},
+	}
+
+	apiVersionsFlag = &cli.StringSliceFlag{
+		Name:  "api-version",
+		Usage: fmt.Sprintf("The API versions to serve (%s for the /api and /api/dev routes, %s for the /api/v2 routes). Can be specified multiple times.", api.V1, api.V2),
+		Value: cli.NewStringSlice(api.V1, api.V2),
+	}
+
+	constellationAdminKeyFlag = &cli.StringFlag{
+		Name:    "constellation-admin-key",
+		Usage:   "The hex-encoded private key used to sign Constellation whitelist and deposit messages. A random one is generated if this isn't set.",
+		EnvVars: []string{"NODESET_MOCK_CONSTELLATION_ADMIN_KEY"},
+	}
+
+	exitEncryptionKeyFlag = &cli.StringFlag{
+		Name:    "exit-encryption-key",
+		Usage:   "The age secret key (AGE-SECRET-KEY-1...) used to decrypt uploaded signed exits. A random one is generated if this isn't set.",
+		EnvVars: []string{"NODESET_MOCK_EXIT_ENCRYPTION_KEY"},
+	}
+	allowPlaintextExitsFlag = &cli.BoolFlag{
+		Name:  "allow-plaintext-exits",
+		Usage: "Accept signed exits uploaded in the old plaintext format as well as encrypted ones, for compatibility with older clients",
+	}
+
+	cycleIntervalFlag = &cli.DurationFlag{
+		Name:  "cycle-interval",
+		Usage: "Automatically cycle a new deposit data set for each vault this often (e.g. 5m). Vaults can override this with the admin API.",
+	}
+	cyclePendingThresholdFlag = &cli.UintFlag{
+		Name:  "cycle-pending-threshold",
+		Usage: "Automatically cycle a new deposit data set for each vault whenever this many validators are pending. Vaults can override this with the admin API.",
+	}
+	cyclePolicyFlag = &cli.StringFlag{
+		Name:  "cycle-policy",
+		Usage: "The selection policy used for automatic cycles (default, round-robin, fair-share, max-set-size, or per-node)",
+		Value: db.SetSelectionPolicy_Default,
+	}
+	cycleUserLimitFlag = &cli.UintFlag{
+		Name:  "cycle-user-limit",
+		Usage: "The max number of validators per user in automatic cycles (0 for no limit)",
+	}
+	cycleNodeLimitFlag = &cli.UintFlag{
+		Name:  "cycle-node-limit",
+		Usage: "The max number of validators per node in automatic cycles (0 for no limit)",
+	}
+	cycleMaxSetSizeFlag = &cli.UintFlag{
+		Name:  "cycle-max-set-size",
+		Usage: "The max number of validators in each automatic cycle (0 for no limit)",
+	}
+	registerDelayFlag = &cli.DurationFlag{
+		Name:  "register-delay",
+		Usage: "Mark the validators in each automatically cycled set as registered after this delay (e.g. 1m)",
+	}
+
+	beaconApiFlag = &cli.BoolFlag{
+		Name:  "beacon-api",
+		Usage: "Serve a minimal simulated Beacon API under /eth, consistent with the mock's validators",
+	}
+	beaconGenesisTimeFlag = &cli.Int64Flag{
+		Name:  "beacon-genesis-time",
+		Usage: "The genesis time of the simulated Beacon Chain, as a Unix timestamp. Defaults to the time the mock started.",
+	}
+	beaconSecondsPerSlotFlag = &cli.UintFlag{
+		Name:  "beacon-seconds-per-slot",
+		Usage: "The length of a slot on the simulated Beacon Chain, in seconds",
+		Value: 12,
+	}
+	beaconSlotsPerEpochFlag = &cli.UintFlag{
+		Name:  "beacon-slots-per-epoch",
+		Usage: "The number of slots in an epoch on the simulated Beacon Chain",
+		Value: 32,
+	}
+
+	nonceLifetimeFlag = &cli.DurationFlag{
+		Name:  "nonce-lifetime",
+		Usage: "How long a nonce can be used to log in after it's issued (e.g. 5m). Nonces don't expire if this isn't set.",
+	}
+	maxNoncesPerClientFlag = &cli.UintFlag{
+		Name:  "max-nonces-per-client",
+		Usage: "The max number of unused, unexpired nonces each client IP can have at once (0 for no limit)",
+	}
+	revokeOldSessionsFlag = &cli.BoolFlag{
+		Name:  "revoke-old-sessions",
+		Usage: "Revoke a node's other sessions whenever it logs in",
+	}
+
+	storeFlag = &cli.StringFlag{
+		Name:  "store",
+		Usage: fmt.Sprintf("Where to keep the mock's state (%s to keep it in memory, or %s to keep it on disk in --store-path so it survives restarts)", db.StoreType_Memory, db.StoreType_LevelDb),
+		Value: db.StoreType_Memory,
+	}
+	storePathFlag = &cli.StringFlag{
+		Name:  "store-path",
+		Usage: "The directory of the on-disk store, which is created if it doesn't exist. Required if --store is leveldb.",
+	}
+
+	waitForSeedingFlag = &cli.BoolFlag{
+		Name:  "wait-for-seeding",
+		Usage: "Report the mock as not ready on /ready until seeding is marked as finished with the admin set-ready route, so orchestrators can wait for it to be seeded",
+	}
+
+	webhookUrlFlag = &cli.StringSliceFlag{
+		Name:  "webhook-url",
+		Usage: "A URL to POST state change events to as JSON. Can be specified multiple times.",
+	}
+	webhookSecretFlag = &cli.StringFlag{
+		Name:    "webhook-secret",
+		Usage:   "The key used to sign webhook deliveries with HMAC-SHA256. Deliveries aren't signed if this isn't set.",
+		EnvVars: []string{"NODESET_MOCK_WEBHOOK_SECRET"},
+	}
+	webhookEventFlag = &cli.StringSliceFlag{
+		Name:  "webhook-event",
+		Usage: "An event type to deliver to the webhooks. All event types are delivered if this isn't set. Can be specified multiple times.",
+	}
+)
+
+// Flags for serving the mock, which are accepted by the serve command and by the app itself for compatibility
+var serveFlags []cli.Flag = []cli.Flag{
+	ipFlag,
+	portFlag,
+	unixSocketFlag,
+	adminIpFlag,
+	adminPortFlag,
+	adminUnixSocketFlag,
+	adminTokenFlag,
+	adminReadOnlyTokenFlag,
+	apiVersionsFlag,
+	constellationAdminKeyFlag,
+	exitEncryptionKeyFlag,
+	allowPlaintextExitsFlag,
+	cycleIntervalFlag,
+	cyclePendingThresholdFlag,
+	cyclePolicyFlag,
+	cycleUserLimitFlag,
+	cycleNodeLimitFlag,
+	cycleMaxSetSizeFlag,
+	registerDelayFlag,
+	beaconApiFlag,
+	beaconGenesisTimeFlag,
+	beaconSecondsPerSlotFlag,
+	beaconSlotsPerEpochFlag,
+	webhookUrlFlag,
+	webhookSecretFlag,
+	webhookEventFlag,
+	nonceLifetimeFlag,
+	maxNoncesPerClientFlag,
+	revokeOldSessionsFlag,
+	storeFlag,
+	storePathFlag,
+	waitForSeedingFlag,
+}
+
+// Creates the serve command
+func createServeCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "serve",
+		Usage:  "Run the mock server. This is the default if no command is provided.",
+		Flags:  serveFlags,
+		Action: serve,
+	}
+}
+
+// Runs the mock server until the process is stopped
+func serve(c *cli.Context) error {
+	logger := slog.Default()
+
+	// Create the server
+	var err error
+	ip := c.String(ipFlag.Name)
+	port := uint16(c.Uint(portFlag.Name))
+	server, err := server.NewNodeSetMockServer(logger, ip, port)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating server: %v", err)
+		os.Exit(1)
+	}
+	enabledVersions := map[string]bool{}
+	for _, version := range c.StringSlice(apiVersionsFlag.Name) {
+		if version != api.V1 && version != api.V2 {
+			fmt.Fprintf(os.Stderr, "Unknown API version: %s", version)
+			os.Exit(1)
+		}
+		enabledVersions[version] = true
+	}
+	_ = server.SetApiVersionEnabled(api.V1, enabledVersions[api.V1])
+	_ = server.SetApiVersionEnabled(api.V2, enabledVersions[api.V2])
+	if c.IsSet(constellationAdminKeyFlag.Name) {
+		adminKey, err := crypto.HexToECDSA(utils.RemovePrefix(c.String(constellationAdminKeyFlag.Name)))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing Constellation admin key: %v", err)
+			os.Exit(1)
+		}
+		server.GetManager().SetConstellationAdminPrivateKey(adminKey)
+	}
+	if c.IsSet(exitEncryptionKeyFlag.Name) {
+		identity, err := age.ParseX25519Identity(c.String(exitEncryptionKeyFlag.Name))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing exit encryption key: %v", err)
+			os.Exit(1)
+		}
+		server.GetManager().SetExitEncryptionIdentity(identity)
+	}
+	server.GetManager().SetPlaintextExitsAllowed(c.Bool(allowPlaintextExitsFlag.Name))
+	if c.IsSet(cycleIntervalFlag.Name) || c.IsSet(cyclePendingThresholdFlag.Name) {
+		schedule := &manager.CycleSchedule{
+			Interval:         c.Duration(cycleIntervalFlag.Name),
+			PendingThreshold: int(c.Uint(cyclePendingThresholdFlag.Name)),
+			Policy:           c.String(cyclePolicyFlag.Name),
+			Params: db.SetSelectionParams{
+				ValidatorsPerUser: int(c.Uint(cycleUserLimitFlag.Name)),
+				ValidatorsPerNode: int(c.Uint(cycleNodeLimitFlag.Name)),
+				MaxSetSize:        int(c.Uint(cycleMaxSetSizeFlag.Name)),
+			},
+			RegisterDelay: c.Duration(registerDelayFlag.Name),
+		}
+		err = server.GetManager().SetDefaultCycleSchedule(schedule)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting cycle schedule: %v", err)
+			os.Exit(1)
+		}
+	}
+	beaconConfig := server.GetManager().GetBeaconConfig()
+	if c.IsSet(beaconGenesisTimeFlag.Name) {
+		beaconConfig.GenesisTime = time.Unix(c.Int64(beaconGenesisTimeFlag.Name), 0)
+	}
+	beaconConfig.SecondsPerSlot = uint64(c.Uint(beaconSecondsPerSlotFlag.Name))
+	beaconConfig.SlotsPerEpoch = uint64(c.Uint(beaconSlotsPerEpochFlag.Name))
+	err = server.GetManager().SetBeaconConfig(beaconConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting Beacon Chain config: %v", err)
+		os.Exit(1)
+	}
+	server.SetBeaconApiEnabled(c.Bool(beaconApiFlag.Name))
+	eventTypes := []api.EventType{}
+	for _, eventType := range c.StringSlice(webhookEventFlag.Name) {
+		eventTypes = append(eventTypes, api.EventType(eventType))
+	}
+	for _, url := range c.StringSlice(webhookUrlFlag.Name) {
+		_, err = server.GetManager().AddWebhook(manager.Webhook{
+			URL:    url,
+			Secret: c.String(webhookSecretFlag.Name),
+			Types:  eventTypes,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error adding webhook: %v", err)
+			os.Exit(1)
+		}
+	}
+	err = server.GetManager().SetSessionPolicy(manager.SessionPolicy{
+		NonceLifetime:      c.Duration(nonceLifetimeFlag.Name),
+		MaxNoncesPerClient: int(c.Uint(maxNoncesPerClientFlag.Name)),
+		RevokeOldSessions:  c.Bool(revokeOldSessionsFlag.Name),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting session policy: %v", err)
+		os.Exit(1)
+	}
+	server.SetAdminTokens(c.String(adminReadOnlyTokenFlag.Name), c.String(adminTokenFlag.Name))
+	storeType := c.String(storeFlag.Name)
+	storePath := c.String(storePathFlag.Name)
+	if storeType != db.StoreType_Memory && storeType != db.StoreType_LevelDb {
+		fmt.Fprintf(os.Stderr, "Unknown store type: %s", storeType)
+		os.Exit(1)
+	}
+	if storeType == db.StoreType_LevelDb && storePath == "" {
+		fmt.Fprintf(os.Stderr, "--%s is required with the %s store", storePathFlag.Name, db.StoreType_LevelDb)
+		os.Exit(1)
+	}
+	if c.Bool(waitForSeedingFlag.Name) {
+		server.SetReadinessStatus(api.ReadinessStatus_Seeding)
+	}
+
+	// Load the store before anything can use the manager's state
+	mgr := server.GetManager()
+	store, err := db.OpenStore(storeType, storePath, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening store: %v", err)
+		os.Exit(1)
+	}
+	mgr.SetStore(store)
+	adminSocketPath := c.String(adminUnixSocketFlag.Name)
+	useAdminListener := c.IsSet(adminIpFlag.Name) || c.IsSet(adminPortFlag.Name)
+	if adminSocketPath != "" {
+		adminSocket, err := listenOnUnixSocket(adminSocketPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating admin socket: %v", err)
+			os.Exit(1)
+		}
+		server.SetAdminNetListener(adminSocket)
+	} else if useAdminListener {
+		server.SetAdminListener(c.String(adminIpFlag.Name), uint16(c.Uint(adminPortFlag.Name)))
+	}
+
+	// Start it
+	wg := &sync.WaitGroup{}
+	socketPath := c.String(unixSocketFlag.Name)
+	if socketPath != "" {
+		socket, err := listenOnUnixSocket(socketPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating socket: %v", err)
+			os.Exit(1)
+		}
+		err = server.StartWithListener(wg, socket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting server: %v", err)
+			os.Exit(1)
+		}
+	} else {
+		err = server.Start(wg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting server: %v", err)
+			os.Exit(1)
+		}
+	}
+	port = server.GetPort()
+
+	// Handle process closures
+	termListener := make(chan os.Signal, 1)
+	signal.Notify(termListener, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-termListener
+		fmt.Println("Shutting down...")
+		err := server.Stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error stopping server: %v", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Run the daemon until closed
+	if socketPath != "" {
+		logger.Info(fmt.Sprintf("Started nodeset.io mock server on Unix socket %s", socketPath))
+	} else {
+		logger.Info(fmt.Sprintf("Started nodeset.io mock server on %s:%d", ip, port))
+	}
+	logger.Info("Using Constellation admin", "address", server.GetManager().GetConstellationAdminAddress().Hex())
+	logger.Info("Using exit encryption", "publicKey", server.GetManager().GetExitEncryptionPublicKey())
+	if c.Bool(allowPlaintextExitsFlag.Name) {
+		logger.Info("Accepting plaintext signed exits")
+	}
+	if c.Bool(beaconApiFlag.Name) {
+		logger.Info("Serving simulated Beacon API", "genesisTime", beaconConfig.GenesisTime.Unix())
+	}
+	if storeType == db.StoreType_LevelDb {
+		logger.Info("Using on-disk store", "path", storePath)
+	}
+	if c.Bool(waitForSeedingFlag.Name) {
+		logger.Info("Waiting for seeding to be marked as finished before reporting ready")
+	}
+	if adminSocketPath != "" {
+		logger.Info(fmt.Sprintf("Serving admin routes on Unix socket %s", adminSocketPath))
+	} else if useAdminListener {
+		logger.Info(fmt.Sprintf("Serving admin routes on %s:%d", c.String(adminIpFlag.Name), server.GetAdminPort()))
+	}
+	wg.Wait()
+	mgr.Lock()
+	err = store.Close()
+	mgr.Unlock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error closing store: %v", err)
+		os.Exit(1)
+	}
+	fmt.Println("Server stopped.")
+	return nil
+}
+
+// Listens on a Unix domain socket, replacing a stale socket file left behind by a previous run
+func listenOnUnixSocket(path string) (net.Listener, error) {
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("[%s] already exists and isn't a socket", path)
+		}
+		err = os.Remove(path)
+		if err != nil {
+			return nil, fmt.Errorf("error removing stale socket [%s]: %w", path, err)
+		}
+	}
+	return net.Listen("unix", path)
+}
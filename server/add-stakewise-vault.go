@@ -3,8 +3,6 @@ package server
 import (
 	"fmt"
 	"net/http"
-
-	"github.com/ethereum/go-ethereum/common"
 )
 
 func (s *NodeSetMockServer) addStakeWiseVault(w http.ResponseWriter, r *http.Request) {
@@ -20,19 +18,20 @@ func (s *NodeSetMockServer) addStakeWiseVault(w http.ResponseWriter, r *http.Req
 		handleInputError(w, s.logger, fmt.Errorf("missing network query parameter"))
 		return
 	}
-	addressString := query.Get("address")
-	if addressString == "" {
-		handleInputError(w, s.logger, fmt.Errorf("missing address query parameter"))
+	addresses, err := getAddresses(query, "address")
+	if err != nil {
+		handleInputError(w, s.logger, err)
 		return
 	}
-	address := common.HexToAddress(addressString)
 
-	// Create a new deposit data set
-	err := s.manager.AddStakeWiseVault(address, network)
+	// Add the vaults
+	err = s.manager.AddStakeWiseVaults(addresses, network)
 	if err != nil {
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return
 	}
-	s.logger.Info("Added new stakewise vault", "address", address.Hex(), "network", network)
+	for _, address := range addresses {
+		s.logger.Info("Added new stakewise vault", "address", address.Hex(), "network", network)
+	}
 	handleSuccess(w, s.logger, "")
 }
@@ -3,6 +3,7 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"slices"
 )
 
 func (s *NodeSetMockServer) addUser(w http.ResponseWriter, r *http.Request) {
@@ -11,20 +12,22 @@ func (s *NodeSetMockServer) addUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Input validation
+	// Input validation - the email can be repeated to add several users at once
 	query := r.URL.Query()
-	email := query.Get("email")
-	if email == "" {
+	emails := query["email"]
+	if len(emails) == 0 || slices.Contains(emails, "") {
 		handleInputError(w, s.logger, fmt.Errorf("missing email query parameter"))
 		return
 	}
 
-	// Create a new deposit data set
-	err := s.manager.AddUser(email)
+	// Add the users
+	err := s.manager.AddUsers(emails)
 	if err != nil {
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return
 	}
-	s.logger.Info("Added new user", "email", email)
+	for _, email := range emails {
+		s.logger.Info("Added new user", "email", email)
+	}
 	handleSuccess(w, s.logger, "")
 }
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+)
+
+func (s *NodeSetMockServer) addWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	query := r.URL.Query()
+	webhook := manager.Webhook{
+		URL:    query.Get("url"),
+		Secret: r.Header.Get(auth.WebhookSecretHeader),
+	}
+	if webhook.URL == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing url query parameter"))
+		return
+	}
+	if query.Has("secret") {
+		handleInputError(w, s.logger, fmt.Errorf("the secret must be sent in the %s header, not as a query parameter", auth.WebhookSecretHeader))
+		return
+	}
+	typesString := query.Get("types")
+	if typesString != "" {
+		for _, eventType := range strings.Split(typesString, ",") {
+			webhook.Types = append(webhook.Types, api.EventType(eventType))
+		}
+	}
+	var err error
+	webhook.RetryPolicy.MaxAttempts, err = getOptionalLimit(query, "max-attempts")
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	webhook.RetryPolicy.InitialBackoff, err = getOptionalDuration(query, "initial-backoff")
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	webhook.RetryPolicy.MaxBackoff, err = getOptionalDuration(query, "max-backoff")
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+
+	// Add the webhook
+	webhook, err = s.manager.AddWebhook(webhook)
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	handleSuccess(w, s.logger, getWebhookData(webhook))
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+)
+
+// The level of access an admin route requires
+type adminAccess int
+
+const (
+	// The route only reads the mock's state
+	adminAccess_ReadOnly adminAccess = iota
+
+	// The route modifies the mock's state
+	adminAccess_ReadWrite
+)
+
+// Wraps an admin route handler so it checks the request's admin token before running
+func (s *NodeSetMockServer) requireAdminAccess(access adminAccess, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Authentication is disabled if no tokens are set
+		readOnlyToken, readWriteToken := s.getAdminTokens()
+		if readOnlyToken == "" && readWriteToken == "" {
+			handler(w, r)
+			return
+		}
+
+		// Get the token
+		token, err := auth.GetAdminTokenFromRequest(r)
+		if err != nil {
+			if errors.Is(err, auth.ErrMissingAuthHeader) {
+				handleMissingAuthHeader(w, s.logger)
+				return
+			}
+			handleAuthHeaderError(w, s.logger, err)
+			return
+		}
+
+		// The read-write token can access everything
+		if tokenMatches(token, readWriteToken) {
+			handler(w, r)
+			return
+		}
+
+		// The read-only token can only access read-only routes
+		if tokenMatches(token, readOnlyToken) {
+			if access == adminAccess_ReadOnly {
+				handler(w, r)
+				return
+			}
+			handleAdminForbidden(w, s.logger)
+			return
+		}
+		handleInvalidAdminToken(w, s.logger)
+	}
+}
+
+// Checks if a provided token matches an expected one, without leaking timing info
+func tokenMatches(provided string, expected string) bool {
+	if expected == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) == 1
+}
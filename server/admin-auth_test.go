@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure admin routes enforce the configured tokens
+func TestAdminTokens(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Enable admin authentication
+	readOnlyToken := "read-only"
+	readWriteToken := "read-write"
+	server.SetAdminTokens(readOnlyToken, readWriteToken)
+	defer server.SetAdminTokens("", "")
+
+	// Read-only route
+	require.Equal(t, http.StatusUnauthorized, runAdminRequest(t, port, api.AdminSnapshotsPath, "", nil))
+	require.Equal(t, http.StatusUnauthorized, runAdminRequest(t, port, api.AdminSnapshotsPath, "wrong", nil))
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminSnapshotsPath, readOnlyToken, nil))
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminSnapshotsPath, readWriteToken, nil))
+	t.Log("Read-only route enforced tokens correctly")
+
+	// Read-write route
+	query := map[string]string{
+		"name": "admin-auth-test",
+	}
+	require.Equal(t, http.StatusUnauthorized, runAdminRequest(t, port, api.AdminSnapshotPath, "", query))
+	require.Equal(t, http.StatusForbidden, runAdminRequest(t, port, api.AdminSnapshotPath, readOnlyToken, query))
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminSnapshotPath, readWriteToken, query))
+	t.Log("Read-write route enforced tokens correctly")
+}
+
+// Make sure the admin routes can be served on their own listener
+func TestAdminListener(t *testing.T) {
+	// Create a separate server with its own admin listener
+	adminServer, err := NewNodeSetMockServer(logger, "localhost", 0)
+	require.NoError(t, err)
+	adminServer.SetAdminListener("localhost", 0)
+	adminWg := &sync.WaitGroup{}
+	err = adminServer.Start(adminWg)
+	require.NoError(t, err)
+	defer func() {
+		_ = adminServer.Stop()
+		adminWg.Wait()
+	}()
+	apiPort := adminServer.GetPort()
+	adminPort := adminServer.GetAdminPort()
+	require.NotEqual(t, apiPort, adminPort)
+	t.Logf("Started server with API port %d and admin port %d", apiPort, adminPort)
+
+	// The admin routes should only be available on the admin port
+	require.Equal(t, http.StatusNotFound, runAdminRequest(t, apiPort, api.AdminSnapshotsPath, "", nil))
+	require.Equal(t, http.StatusOK, runAdminRequest(t, adminPort, api.AdminSnapshotsPath, "", nil))
+	t.Log("Admin routes are only served on the admin port")
+}
+
+// Run an admin request and return the status code
+func runAdminRequest(t *testing.T, port uint16, path string, token string, queryParams map[string]string) int {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/%s", port, path), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	query := request.URL.Query()
+	for name, value := range queryParams {
+		query.Add(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+	if token != "" {
+		auth.AddAdminAuthorizationHeader(request, token)
+	}
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	defer response.Body.Close()
+	return response.StatusCode
+}
+
+// Run an admin request that should succeed, deserializing the response into parsedResponse
+func runAdminDataRequest(t *testing.T, path string, queryParams map[string]string, parsedResponse any) {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/%s", port, path), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	query := request.URL.Query()
+	for name, value := range queryParams {
+		query.Add(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Read the body
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading the response body: %v", err)
+	}
+	err = json.Unmarshal(bytes, parsedResponse)
+	if err != nil {
+		t.Fatalf("error deserializing response: %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (s *NodeSetMockServer) advanceTime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	durationString := r.URL.Query().Get("duration")
+	if durationString == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing duration query parameter"))
+		return
+	}
+	duration, err := time.ParseDuration(durationString)
+	if err != nil {
+		handleInputError(w, s.logger, fmt.Errorf("error parsing duration: %w", err))
+		return
+	}
+
+	err = s.manager.AdvanceTime(duration)
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	handleSuccess(w, s.logger, s.getTimeData())
+}
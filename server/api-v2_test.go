@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the v2 routes return the same data as the v1 routes
+func TestApiV2Routes(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	db := idb.ProvisionFullDatabase(t, logger, true)
+	require.NoError(t, server.manager.SetDatabase(db))
+	session := db.Sessions[0]
+
+	// Get the deposit data from both versions
+	v1Response := runGetDepositDataRequest(t, session)
+	path := fmt.Sprintf("%s/%s/%s/%s/%s/%s", api.V2Path, api.ModulesPath, api.StakeWisePath, test.Network, test.StakeWiseVaultAddressHex, api.DepositDataPath)
+	code, body := runV2GetRequest(t, session, path)
+	require.Equal(t, http.StatusOK, code)
+	var v2Response api.NodeSetResponse[api.DepositDataData]
+	err := json.Unmarshal(body, &v2Response)
+	require.NoError(t, err)
+	require.Equal(t, v1Response.Data, v2Response.Data)
+	t.Log("v2 deposit data matches v1")
+
+	// Get the validators from both versions
+	v1Validators := runGetValidatorsRequest(t, session)
+	path = fmt.Sprintf("%s/%s/%s/%s/%s/%s", api.V2Path, api.ModulesPath, api.StakeWisePath, test.Network, test.StakeWiseVaultAddressHex, api.ValidatorsPath)
+	code, body = runV2GetRequest(t, session, path)
+	require.Equal(t, http.StatusOK, code)
+	var v2Validators api.NodeSetResponse[api.ValidatorsData]
+	err = json.Unmarshal(body, &v2Validators)
+	require.NoError(t, err)
+	require.Equal(t, v1Validators.Data, v2Validators.Data)
+	t.Log("v2 validators match v1")
+}
+
+// Make sure API versions can be toggled
+func TestApiVersionToggle(t *testing.T) {
+	// Disable v1
+	query := map[string]string{
+		"version": api.V1,
+		"enabled": "false",
+	}
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminApiVersionPath, "", query))
+	defer func() {
+		err := server.SetApiVersionEnabled(api.V1, true)
+		if err != nil {
+			t.Fatalf("error re-enabling v1: %v", err)
+		}
+	}()
+	require.False(t, server.IsApiVersionEnabled(api.V1))
+	t.Log("Disabled v1")
+
+	// v1 should be gone but v2 should still work
+	response := runNonceRequest(t)
+	response.Body.Close()
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+	code, _ := runV2GetRequest(t, nil, fmt.Sprintf("%s/%s/%s", api.V2Path, api.CorePath, api.NoncePath))
+	require.Equal(t, http.StatusOK, code)
+	t.Log("v1 returned not found, v2 succeeded")
+}
+
+// Run a GET request against a v2 route and return the status code and body
+func runV2GetRequest(t *testing.T, session *db.Session, path string) (int, []byte) {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/%s", port, path), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	if session != nil {
+		auth.AddAuthorizationHeader(request, session)
+	}
+	t.Logf("Created request")
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	defer response.Body.Close()
+	t.Logf("Sent request")
+
+	// Read the body
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading the response body: %v", err)
+	}
+	return response.StatusCode, bytes
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure state changes are timestamped and recorded in the audit log
+func TestAuditLog(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database and freeze time
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	session := database.Sessions[0]
+	now := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	server.manager.FreezeTime()
+	server.manager.SetTime(now)
+	defer server.manager.ResetTime()
+
+	// Upload deposit data and make sure the validator is timestamped
+	depositData := idb.GenerateDepositData(t, 5, test.StakeWiseVaultAddress)
+	runUploadDepositDataRequest(t, session, []beacon.ExtendedDepositData{depositData})
+	node, _ := server.manager.GetNode(session.NodeAddress)
+	validators := node.Validators[test.Network]
+	validator := validators[len(validators)-1]
+	require.Equal(t, beacon.ValidatorPubkey(depositData.PublicKey), validator.Pubkey)
+	require.True(t, now.Equal(validator.DepositDataUploadedTime))
+	require.True(t, validator.SetIncludedTime.IsZero())
+	t.Log("Validator was timestamped")
+
+	// Add a user, once successfully and once with a missing email
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminAddUserPath, "", map[string]string{"email": "audit@nodeset.io"}))
+	require.Equal(t, http.StatusBadRequest, runAdminRequest(t, port, api.AdminAddUserPath, "", nil))
+	t.Log("Ran admin requests")
+
+	// Check the node's entries
+	entries := runAuditLogRequest(t, map[string]string{"node": session.NodeAddress.Hex()})
+	require.Len(t, entries, 1)
+	require.Equal(t, db.AuditActor_Node, entries[0].Actor)
+	require.Equal(t, "POST /api/"+api.DepositDataPath, entries[0].Route)
+	require.True(t, entries[0].Success)
+	require.True(t, now.Equal(entries[0].Time))
+
+	// Check the admin entries
+	entries = runAuditLogRequest(t, map[string]string{"actor": db.AuditActor_Admin})
+	require.Len(t, entries, 2)
+	require.True(t, entries[0].Success)
+	require.False(t, entries[1].Success)
+	require.Equal(t, http.StatusBadRequest, entries[1].StatusCode)
+	require.Equal(t, "missing email query parameter", entries[1].Error)
+	entries = runAuditLogRequest(t, map[string]string{"actor": db.AuditActor_Admin, "limit": "1"})
+	require.Len(t, entries, 1)
+	require.False(t, entries[0].Success)
+	t.Log("Audit log entries were correct")
+
+	// Make sure the audit log is part of snapshots
+	server.manager.TakeSnapshot("audit")
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminAddUserPath, "", map[string]string{"email": "audit2@nodeset.io"}))
+	require.Len(t, runAuditLogRequest(t, nil), 4)
+	require.NoError(t, server.manager.RevertToSnapshot("audit"))
+	require.Len(t, runAuditLogRequest(t, nil), 3)
+	t.Log("Audit log was reverted with the snapshot")
+}
+
+// Run an admin audit log request
+func runAuditLogRequest(t *testing.T, queryParams map[string]string) []api.AuditEntry {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/%s", port, api.AdminAuditLogPath), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	query := request.URL.Query()
+	for name, value := range queryParams {
+		query.Add(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Read the body
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading the response body: %v", err)
+	}
+	var parsedResponse api.NodeSetResponse[api.AuditLogData]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	if err != nil {
+		t.Fatalf("error deserializing response: %v", err)
+	}
+	return parsedResponse.Data.Entries
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+)
+
+// Context key for the audit entry of the request being handled
+type auditEntryContextKey struct{}
+
+// Captures the response to a request so it can be recorded in the audit log
+type auditResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *auditResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// Records each call to a state-changing route in the audit log, along with its outcome
+func (s *NodeSetMockServer) audit(actor string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entry := &db.AuditEntry{
+			Actor: actor,
+			Route: fmt.Sprintf("%s %s", r.Method, r.URL.Path),
+		}
+		writer := &auditResponseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+		handler(writer, r.WithContext(context.WithValue(r.Context(), auditEntryContextKey{}, entry)))
+
+		// Fall back to the session for the node address
+		if entry.NodeAddress == nil && actor == db.AuditActor_Node {
+			token, err := auth.GetSessionTokenFromRequest(r)
+			if err == nil {
+				session := s.manager.GetSessionByToken(token)
+				if session != nil && session.IsLoggedIn {
+					address := session.NodeAddress
+					entry.NodeAddress = &address
+				}
+			}
+		}
+
+		// Record the outcome
+		entry.StatusCode = writer.statusCode
+		entry.Success = writer.statusCode < http.StatusBadRequest
+		if !entry.Success {
+			var response api.NodeSetResponse[any]
+			err := json.Unmarshal(writer.body.Bytes(), &response)
+			if err == nil && response.Message != "" {
+				entry.Error = response.Message
+			} else {
+				entry.Error = http.StatusText(writer.statusCode)
+			}
+		}
+		s.manager.AddAuditEntry(*entry)
+	}
+}
+
+// Sets the node address on the audit entry for a request, for routes where the node isn't logged in
+func setAuditNodeAddress(r *http.Request, address common.Address) {
+	entry, ok := r.Context().Value(auditEntryContextKey{}).(*db.AuditEntry)
+	if ok {
+		entry.NodeAddress = &address
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/rocket-pool/node-manager-core/beacon/client"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the Beacon API reflects registered validators and processes their exits
+func TestBeaconApi(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Enable the Beacon API with a fast chain that exits validators immediately
+	server.SetBeaconApiEnabled(true)
+	defer server.SetBeaconApiEnabled(false)
+	oldConfig := server.manager.GetBeaconConfig()
+	defer func() {
+		_ = server.manager.SetBeaconConfig(oldConfig)
+	}()
+	config := oldConfig
+	config.GenesisTime = time.Now().Add(-100 * time.Second).Truncate(time.Second)
+	config.SecondsPerSlot = 1
+	config.SlotsPerEpoch = 1
+	config.ExitDelayEpochs = 0
+	config.WithdrawalDelayEpochs = 1000
+	require.NoError(t, server.manager.SetBeaconConfig(config))
+
+	// Provision the database and register the deposit data set
+	database := idb.ProvisionFullDatabase(t, logger, true)
+	require.NoError(t, server.manager.SetDatabase(database))
+	set := database.GetStakeWiseVault(test.StakeWiseVaultAddress, test.Network).LatestDepositDataSet
+	require.NoError(t, server.manager.MarkValidatorsRegistered(test.StakeWiseVaultAddress, test.Network, set))
+	pubkeys := make([]string, len(set))
+	for i, depositData := range set {
+		pubkeys[i] = beacon.ValidatorPubkey(depositData.PublicKey).HexWithPrefix()
+	}
+
+	// Check the genesis and sync status
+	provider := client.NewBeaconHttpProvider(fmt.Sprintf("http://localhost:%d", port), 5*time.Second)
+	genesis, err := provider.Beacon_Genesis(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(config.GenesisTime.Unix()), uint64(genesis.Data.GenesisTime))
+	syncStatus, err := provider.Node_Syncing(context.Background())
+	require.NoError(t, err)
+	require.False(t, syncStatus.Data.IsSyncing)
+	require.GreaterOrEqual(t, uint64(syncStatus.Data.HeadSlot), uint64(100))
+	t.Log("Genesis and sync status are correct")
+
+	// The registered validators should be active with indices
+	validators, err := provider.Beacon_Validators(context.Background(), "head", pubkeys)
+	require.NoError(t, err)
+	require.Len(t, validators.Data, len(set))
+	for i, validator := range validators.Data {
+		require.Equal(t, fmt.Sprint(i), validator.Index)
+		require.Equal(t, string(beacon.ValidatorState_ActiveOngoing), validator.Status)
+		require.Equal(t, []byte(set[i].PublicKey), []byte(validator.Validator.Pubkey))
+	}
+	validators, err = provider.Beacon_Validators(context.Background(), "genesis", pubkeys)
+	require.NoError(t, err)
+	require.Empty(t, validators.Data)
+	t.Log("Registered validators are active")
+
+	// Exits for the future should be rejected
+	exit := client.VoluntaryExitRequest{
+		Message: client.VoluntaryExitMessage{
+			Epoch:          client.Uinteger(server.manager.GetBeaconEpoch() + 100),
+			ValidatorIndex: "0",
+		},
+		Signature: make([]byte, beacon.ValidatorSignatureLength),
+	}
+	require.Error(t, provider.Beacon_VoluntaryExits_Post(context.Background(), exit))
+
+	// Exit the first validator
+	exit.Message.Epoch = 0
+	require.NoError(t, provider.Beacon_VoluntaryExits_Post(context.Background(), exit))
+	validators, err = provider.Beacon_Validators(context.Background(), "head", []string{"0"})
+	require.NoError(t, err)
+	require.Len(t, validators.Data, 1)
+	require.Equal(t, string(beacon.ValidatorState_ExitedUnslashed), validators.Data[0].Status)
+	pubkey := beacon.ValidatorPubkey(set[0].PublicKey)
+	require.Equal(t, api.StakeWiseStatus_Removed, server.manager.GetValidatorStatus(test.Network, pubkey))
+	require.Error(t, provider.Beacon_VoluntaryExits_Post(context.Background(), exit))
+	t.Log("Exited validator was removed")
+
+	// Disabling the Beacon API should hide the routes
+	server.SetBeaconApiEnabled(false)
+	response, err := http.Get(fmt.Sprintf("http://localhost:%d/eth/v1/node/syncing", port))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+}
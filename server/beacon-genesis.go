@@ -0,0 +1,24 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/rocket-pool/node-manager-core/beacon/client"
+	"github.com/rocket-pool/node-manager-core/log"
+)
+
+func (s *NodeSetMockServer) getBeaconGenesis(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+	s.logger.Info("New request", slog.String(log.MethodKey, r.Method), slog.String(log.PathKey, r.URL.Path))
+
+	config := s.manager.GetBeaconConfig()
+	response := client.GenesisResponse{}
+	response.Data.GenesisTime = client.Uinteger(config.GenesisTime.Unix())
+	response.Data.GenesisForkVersion = config.GenesisForkVersion
+	response.Data.GenesisValidatorsRoot = config.GenesisValidatorsRoot.Bytes()
+	handleBeaconSuccess(w, s.logger, response)
+}
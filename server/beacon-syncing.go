@@ -0,0 +1,24 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/rocket-pool/node-manager-core/beacon/client"
+	"github.com/rocket-pool/node-manager-core/log"
+)
+
+func (s *NodeSetMockServer) getBeaconSyncStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+	s.logger.Info("New request", slog.String(log.MethodKey, r.Method), slog.String(log.PathKey, r.URL.Path))
+
+	// The simulated chain is always synced
+	response := client.SyncStatusResponse{}
+	response.Data.IsSyncing = false
+	response.Data.HeadSlot = client.Uinteger(s.manager.GetBeaconSlot())
+	response.Data.SyncDistance = 0
+	handleBeaconSuccess(w, s.logger, response)
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/rocket-pool/node-manager-core/beacon/client"
+	"github.com/rocket-pool/node-manager-core/log"
+)
+
+const (
+	// The balance of each simulated validator, in gwei
+	beaconValidatorBalance uint64 = 32e9
+)
+
+func (s *NodeSetMockServer) getBeaconValidators(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+	s.logger.Info("New request", slog.String(log.MethodKey, r.Method), slog.String(log.PathKey, r.URL.Path))
+	s.logger.Debug("Request params:", slog.String(log.QueryKey, r.URL.RawQuery))
+
+	// Get the epoch of the requested state
+	epoch, err := s.getBeaconStateEpoch(mux.Vars(r)[stateVar])
+	if err != nil {
+		handleBeaconError(w, s.logger, http.StatusBadRequest, err)
+		return
+	}
+
+	// Parse the validator IDs, which can be pubkeys or indices
+	pubkeys := map[beacon.ValidatorPubkey]bool{}
+	indices := map[uint64]bool{}
+	for _, idList := range r.URL.Query()["id"] {
+		for _, id := range strings.Split(idList, ",") {
+			if strings.HasPrefix(id, "0x") {
+				pubkey, err := beacon.HexToValidatorPubkey(id)
+				if err != nil {
+					handleBeaconError(w, s.logger, http.StatusBadRequest, fmt.Errorf("invalid validator ID [%s]: %w", id, err))
+					return
+				}
+				pubkeys[pubkey] = true
+				continue
+			}
+			index, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				handleBeaconError(w, s.logger, http.StatusBadRequest, fmt.Errorf("invalid validator ID [%s]: %w", id, err))
+				return
+			}
+			indices[index] = true
+		}
+	}
+	filter := len(pubkeys) > 0 || len(indices) > 0
+
+	// Build the response from the validators that were active by the requested state
+	response := client.ValidatorsResponse{
+		Data: []client.Validator{},
+	}
+	for _, validator := range s.manager.GetBeaconValidators() {
+		if epoch < validator.ActivationEpoch {
+			continue
+		}
+		if filter && !pubkeys[validator.Pubkey] && !indices[validator.BeaconIndex] {
+			continue
+		}
+		response.Data = append(response.Data, getBeaconValidatorData(validator, epoch))
+	}
+	handleBeaconSuccess(w, s.logger, response)
+}
+
+// Get the epoch for a Beacon API state ID. Block roots aren't tracked by the simulated chain, so only the named states
+// and slot numbers are supported.
+func (s *NodeSetMockServer) getBeaconStateEpoch(stateId string) (uint64, error) {
+	config := s.manager.GetBeaconConfig()
+	switch stateId {
+	case "head", "finalized", "justified":
+		return s.manager.GetBeaconEpoch(), nil
+	case "genesis":
+		return 0, nil
+	}
+
+	slot, err := strconv.ParseUint(stateId, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported state ID [%s]", stateId)
+	}
+	if slot > s.manager.GetBeaconSlot() {
+		return 0, fmt.Errorf("slot %d is in the future", slot)
+	}
+	return slot / config.SlotsPerEpoch, nil
+}
+
+// Convert a validator to its Beacon API representation at the given epoch
+func getBeaconValidatorData(validator *db.Validator, epoch uint64) client.Validator {
+	status := validator.GetBeaconStatus(epoch)
+	data := client.Validator{
+		Index:   strconv.FormatUint(validator.BeaconIndex, 10),
+		Balance: client.Uinteger(beaconValidatorBalance),
+		Status:  string(status),
+	}
+	data.Validator.Pubkey = validator.Pubkey[:]
+	data.Validator.WithdrawalCredentials = client.ByteArray(validator.DepositData.WithdrawalCredentials)
+	data.Validator.EffectiveBalance = client.Uinteger(beaconValidatorBalance)
+	data.Validator.ActivationEligibilityEpoch = client.Uinteger(validator.ActivationEpoch)
+	data.Validator.ActivationEpoch = client.Uinteger(validator.ActivationEpoch)
+	data.Validator.ExitEpoch = client.Uinteger(validator.ExitEpoch)
+	data.Validator.WithdrawableEpoch = client.Uinteger(validator.WithdrawableEpoch)
+	return data
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/rocket-pool/node-manager-core/beacon/client"
+	"github.com/rocket-pool/node-manager-core/log"
+)
+
+func (s *NodeSetMockServer) submitVoluntaryExit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+	s.logger.Info("New request", slog.String(log.MethodKey, r.Method), slog.String(log.PathKey, r.URL.Path))
+
+	// Read the body
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		handleBeaconError(w, s.logger, http.StatusBadRequest, fmt.Errorf("error reading request body: %w", err))
+		return
+	}
+	s.logger.Debug("Request body:", slog.String(log.BodyKey, string(bodyBytes)))
+	var request client.VoluntaryExitRequest
+	err = json.Unmarshal(bodyBytes, &request)
+	if err != nil {
+		handleBeaconError(w, s.logger, http.StatusBadRequest, fmt.Errorf("error deserializing request body: %w", err))
+		return
+	}
+	index, err := strconv.ParseUint(request.Message.ValidatorIndex, 10, 64)
+	if err != nil {
+		handleBeaconError(w, s.logger, http.StatusBadRequest, fmt.Errorf("invalid validator index [%s]: %w", request.Message.ValidatorIndex, err))
+		return
+	}
+
+	// Process the exit
+	err = s.manager.SubmitVoluntaryExit(index, uint64(request.Message.Epoch))
+	if err != nil {
+		handleBeaconError(w, s.logger, http.StatusBadRequest, fmt.Errorf("invalid voluntary exit for validator %d: %w", index, err))
+		return
+	}
+	writeResponse(w, s.logger, http.StatusOK, []byte{})
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/rocket-pool/node-manager-core/utils"
+)
+
+func (s *NodeSetMockServer) constellationWhitelist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Get the requesting node
+	args := s.processApiRequest(w, r, nil)
+	session := s.processAuthHeader(w, r)
+	if session == nil {
+		return
+	}
+	node := s.getNodeForSession(w, session)
+	if node == nil {
+		return
+	}
+
+	// Create the signature
+	deployment, _ := getNetworkAndVault(r, args)
+	signature, err := s.manager.WhitelistNodeForConstellation(node.Address, deployment)
+	if err != nil {
+		handleError(w, s.logger, err)
+		return
+	}
+
+	// Write the response
+	data := api.WhitelistData{
+		Signature: utils.EncodeHexWithPrefix(signature),
+	}
+	handleSuccess(w, s.logger, data)
+	s.logger.Info("Issued Constellation whitelist signature", "deployment", deployment, "address", node.Address.Hex())
+}
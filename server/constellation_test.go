@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure Constellation signatures are issued correctly and minipool limits are enforced
+func TestConstellation(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	db := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(db))
+	session := db.Sessions[0]
+	err := server.manager.SetMinipoolLimit(test.User1Email, 1)
+	require.NoError(t, err)
+	adminAddress := server.manager.GetConstellationAdminAddress()
+
+	// Deposit signatures require a whitelist signature first
+	minipool0 := common.HexToAddress("0x90de000000000000000000000000000000000000")
+	salt0 := big.NewInt(0)
+	code, parsedResponse := runMinipoolDepositSignatureRequest(t, session, minipool0, salt0)
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, missingConstellationWhitelistKey, parsedResponse.Error)
+	t.Log("Deposit signature was rejected before whitelisting")
+
+	// Get a whitelist signature
+	path := fmt.Sprintf("%s/%s/%s/%s/%s", api.V2Path, api.ModulesPath, api.ConstellationPath, test.Network, api.WhitelistPath)
+	code, body := runV2PostRequest(t, session, path, nil)
+	require.Equal(t, http.StatusOK, code)
+	var whitelistResponse api.NodeSetResponse[api.WhitelistData]
+	err = json.Unmarshal(body, &whitelistResponse)
+	require.NoError(t, err)
+	signature, err := utils.DecodeHex(whitelistResponse.Data.Signature)
+	require.NoError(t, err)
+	err = auth.VerifyConstellationWhitelistSignature(session.NodeAddress, signature, adminAddress)
+	require.NoError(t, err)
+	t.Log("Received valid whitelist signature")
+
+	// A body that can't be deserialized shouldn't issue a signature
+	malformedBody := []byte(fmt.Sprintf(`{"minipoolAddress":"%s","salt":123}`, minipool0.Hex()))
+	code, body = runV2PostRequest(t, session, fmt.Sprintf("%s/%s/%s/%s/%s", api.V2Path, api.ModulesPath, api.ConstellationPath, test.Network, api.MinipoolDepositSignaturePath), malformedBody)
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Contains(t, string(body), "error deserializing request body")
+	t.Log("Malformed deposit signature request was rejected")
+
+	// Get a deposit signature
+	code, parsedResponse = runMinipoolDepositSignatureRequest(t, session, minipool0, salt0)
+	require.Equal(t, http.StatusOK, code)
+	signature, err = utils.DecodeHex(parsedResponse.Data.Signature)
+	require.NoError(t, err)
+	err = auth.VerifyConstellationDepositSignature(minipool0, salt0, session.NodeAddress, signature, adminAddress)
+	require.NoError(t, err)
+	t.Log("Received valid deposit signature")
+
+	// The next minipool should hit the limit
+	minipool1 := common.HexToAddress("0x90de000000000000000000000000000000000001")
+	code, parsedResponse = runMinipoolDepositSignatureRequest(t, session, minipool1, big.NewInt(1))
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, minipoolLimitReachedKey, parsedResponse.Error)
+	t.Log("Deposit signature was rejected once the limit was reached")
+}
+
+// Run a Constellation minipool deposit signature request
+func runMinipoolDepositSignatureRequest(t *testing.T, session *db.Session, minipoolAddress common.Address, salt *big.Int) (int, api.NodeSetResponse[api.MinipoolDepositSignatureData]) {
+	request := api.MinipoolDepositSignatureRequest{
+		MinipoolAddress: minipoolAddress.Hex(),
+		Salt:            utils.EncodeHexWithPrefix(salt.Bytes()),
+	}
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	path := fmt.Sprintf("%s/%s/%s/%s/%s", api.V2Path, api.ModulesPath, api.ConstellationPath, test.Network, api.MinipoolDepositSignaturePath)
+	code, responseBody := runV2PostRequest(t, session, path, body)
+
+	var parsedResponse api.NodeSetResponse[api.MinipoolDepositSignatureData]
+	err = json.Unmarshal(responseBody, &parsedResponse)
+	require.NoError(t, err)
+	return code, parsedResponse
+}
+
+// Run a POST request against a v2 route and return the status code and body
+func runV2PostRequest(t *testing.T, session *db.Session, path string, body []byte) (int, []byte) {
+	// Create the request
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/api/%s", port, path), bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	if session != nil {
+		auth.AddAuthorizationHeader(request, session)
+	}
+	t.Logf("Created request")
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	defer response.Body.Close()
+	t.Logf("Sent request")
+
+	// Read the body
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading the response body: %v", err)
+	}
+	return response.StatusCode, bytes
+}
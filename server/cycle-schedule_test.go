@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure scheduled cycles and registrations run when they're due
+func TestCycleSchedule(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Stop the background scheduler so only the test runs the scheduled tasks
+	server.manager.StopScheduler()
+	defer server.manager.StartScheduler(schedulerPollInterval)
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	defer server.manager.ClearCycleSchedule(test.StakeWiseVaultAddress, test.Network)
+
+	// Cycle once 5 validators are pending, and register them right after
+	query := map[string]string{
+		"network":           test.Network,
+		"vault":             test.StakeWiseVaultAddressHex,
+		"pending-threshold": "5",
+		"user-limit":        "1",
+		"register-delay":    "1ns",
+	}
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminCycleSchedulePath, "", query))
+	server.manager.Lock()
+	schedules := server.manager.GetCycleSchedules()
+	server.manager.Unlock()
+	require.Len(t, schedules, 1)
+	require.Equal(t, 5, schedules[0].PendingThreshold)
+	t.Log("Set cycle schedule")
+
+	// Run the scheduler, which should cycle a set
+	server.manager.RunScheduledTasks()
+	server.manager.Lock()
+	vault := server.manager.GetStakeWiseVault(test.StakeWiseVaultAddress, test.Network)
+	version := vault.LatestDepositDataSetIndex
+	set := vault.LatestDepositDataSet
+	pubkey := beacon.ValidatorPubkey(set[0].PublicKey)
+	status := server.manager.GetValidatorStatus(test.Network, pubkey)
+	server.manager.Unlock()
+	require.Equal(t, 1, version)
+	require.Len(t, set, 3)
+	require.Equal(t, api.StakeWiseStatus_Uploaded, status)
+	t.Log("Scheduler cycled a new set")
+
+	// Run it again, which should register the set but not cycle another one since only 2 validators are pending
+	server.manager.RunScheduledTasks()
+	server.manager.Lock()
+	version = server.manager.GetStakeWiseVault(test.StakeWiseVaultAddress, test.Network).LatestDepositDataSetIndex
+	status = server.manager.GetValidatorStatus(test.Network, pubkey)
+	server.manager.Unlock()
+	require.Equal(t, 1, version)
+	require.Equal(t, api.StakeWiseStatus_Registered, status)
+	t.Log("Scheduler registered the set")
+}
@@ -3,9 +3,19 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/rocket-pool/node-manager-core/beacon"
+)
+
+const (
+	// The max number of validators per user in a set cycled without a user-limit, which was always one before the
+	// parameter was optional
+	defaultCycleUserLimit int = 1
 )
 
 func (s *NodeSetMockServer) cycleSet(w http.ResponseWriter, r *http.Request) {
@@ -27,37 +37,68 @@ func (s *NodeSetMockServer) cycleSet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	vaultAddress := common.HexToAddress(vaultAddressString)
-	userLimit := query.Get("user-limit")
-	if userLimit == "" {
-		handleInputError(w, s.logger, fmt.Errorf("missing user-limit query parameter"))
+	policy, err := db.GetSetSelectionPolicy(query.Get("policy"))
+	if err != nil {
+		handleInputError(w, s.logger, err)
 		return
 	}
-	validatorsPerUser, err := strconv.ParseInt(userLimit, 10, 32)
+	var params db.SetSelectionParams
+	params.ValidatorsPerUser, err = getLimit(query, "user-limit", defaultCycleUserLimit)
 	if err != nil {
-		handleInputError(w, s.logger, fmt.Errorf("error parsing user-limit: %w", err))
+		handleInputError(w, s.logger, err)
 		return
 	}
-
-	// Create a new deposit data set
-	set := s.manager.CreateNewDepositDataSet(networkName, int(validatorsPerUser))
-	s.logger.Info("Created new deposit data set", "network", networkName, "user-limit", validatorsPerUser)
-
-	err = s.manager.UploadDepositDataToStakeWise(vaultAddress, networkName, set)
+	params.ValidatorsPerNode, err = getOptionalLimit(query, "node-limit")
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	params.MaxSetSize, err = getOptionalLimit(query, "max-set-size")
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	err = policy.Validate(params)
 	if err != nil {
-		handleServerError(w, s.logger, err)
+		handleInputError(w, s.logger, err)
 		return
 	}
-	s.logger.Info("Uploaded deposit data set", "vault", vaultAddress.Hex())
 
-	err = s.manager.MarkDepositDataSetUploaded(vaultAddress, networkName, set)
+	// Cycle the set
+	set, err := s.manager.CycleDepositDataSet(vaultAddress, networkName, policy, params)
 	if err != nil {
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return
 	}
 
+	// Write the response
+	data := api.CycleSetData{
+		Pubkeys: make([]beacon.ValidatorPubkey, len(set)),
+	}
+	for i, depositData := range set {
+		data.Pubkeys[i] = beacon.ValidatorPubkey(depositData.PublicKey)
+	}
 	vault := s.manager.GetStakeWiseVault(vaultAddress, networkName)
 	if vault != nil {
-		s.logger.Info("Marked deposit data set as uploaded", "version", vault.LatestDepositDataSetIndex)
+		data.Version = vault.LatestDepositDataSetIndex
+	}
+	handleSuccess(w, s.logger, data)
+}
+
+// Parses an optional limit from the query args, returning 0 (no limit) if it's missing
+func getOptionalLimit(query url.Values, name string) (int, error) {
+	return getLimit(query, name, 0)
+}
+
+// Parses a limit from the query args, returning the default if it's missing
+func getLimit(query url.Values, name string, defaultLimit int) (int, error) {
+	limitString := query.Get(name)
+	if limitString == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.ParseInt(limitString, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s: %w", name, err)
 	}
-	handleSuccess(w, s.logger, "")
+	return int(limit), nil
 }
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure cycling a set with a policy returns the selected pubkeys
+func TestCycleSet(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+
+	// Cycle the set
+	query := map[string]string{
+		"network":      test.Network,
+		"vault":        test.StakeWiseVaultAddressHex,
+		"policy":       db.SetSelectionPolicy_RoundRobin,
+		"max-set-size": "2",
+	}
+	parsedResponse := runCycleSetRequest(t, query)
+
+	// Make sure the response is correct
+	expectedPubkeys := []beacon.ValidatorPubkey{
+		beacon.ValidatorPubkey(idb.GenerateDepositData(t, 0, test.StakeWiseVaultAddress).PublicKey),
+		beacon.ValidatorPubkey(idb.GenerateDepositData(t, 1, test.StakeWiseVaultAddress).PublicKey),
+	}
+	require.Equal(t, 1, parsedResponse.Data.Version)
+	require.Equal(t, expectedPubkeys, parsedResponse.Data.Pubkeys)
+	vault := server.manager.GetStakeWiseVault(test.StakeWiseVaultAddress, test.Network)
+	require.Len(t, vault.LatestDepositDataSet, 2)
+	t.Logf("Received correct response - version = %d, pubkeys match", parsedResponse.Data.Version)
+}
+
+// Make sure sets cycled without a user limit take one validator per user, and a limit of 0 takes them all
+func TestCycleSetUserLimit(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	require.Len(t, server.manager.CreateNewDepositDataSet(test.Network, 0), 3)
+
+	// Users 1 and 2 have one validator each in the set, and user 3 has two
+	query := map[string]string{
+		"network": test.Network,
+		"vault":   test.StakeWiseVaultAddressHex,
+	}
+	parsedResponse := runCycleSetRequest(t, query)
+	expectedPubkeys := []beacon.ValidatorPubkey{
+		beacon.ValidatorPubkey(idb.GenerateDepositData(t, 0, test.StakeWiseVaultAddress).PublicKey),
+		beacon.ValidatorPubkey(idb.GenerateDepositData(t, 1, test.StakeWiseVaultAddress).PublicKey),
+		beacon.ValidatorPubkey(idb.GenerateDepositData(t, 3, test.StakeWiseVaultAddress).PublicKey),
+	}
+	require.Equal(t, expectedPubkeys, parsedResponse.Data.Pubkeys)
+	t.Log("Set without a user limit took one validator per user")
+
+	// No limit takes the rest
+	query["user-limit"] = "0"
+	parsedResponse = runCycleSetRequest(t, query)
+	expectedPubkeys = []beacon.ValidatorPubkey{
+		beacon.ValidatorPubkey(idb.GenerateDepositData(t, 2, test.StakeWiseVaultAddress).PublicKey),
+		beacon.ValidatorPubkey(idb.GenerateDepositData(t, 4, test.StakeWiseVaultAddress).PublicKey),
+	}
+	require.Equal(t, expectedPubkeys, parsedResponse.Data.Pubkeys)
+	t.Log("Set with no user limit took the rest")
+}
+
+// Run a cycle-set request and return the parsed response
+func runCycleSetRequest(t *testing.T, queryParams map[string]string) api.NodeSetResponse[api.CycleSetData] {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/%s", port, api.AdminCycleSetPath), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	query := request.URL.Query()
+	for name, value := range queryParams {
+		query.Add(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+	t.Logf("Created request")
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	t.Logf("Sent request")
+
+	// Check the status code
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	t.Logf("Received OK status code")
+
+	// Read the body
+	defer response.Body.Close()
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading the response body: %v", err)
+	}
+	var parsedResponse api.NodeSetResponse[api.CycleSetData]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	if err != nil {
+		t.Fatalf("error deserializing response: %v", err)
+	}
+	return parsedResponse
+}
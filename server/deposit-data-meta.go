@@ -6,6 +6,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
 )
 
 func (s *NodeSetMockServer) depositDataMeta(w http.ResponseWriter, r *http.Request) {
@@ -21,8 +22,8 @@ func (s *NodeSetMockServer) depositDataMeta(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Input validation
-	network := args.Get("network")
-	vaultAddress := common.HexToAddress(args.Get("vault"))
+	network, vaultString := getNetworkAndVault(r, args)
+	vaultAddress := common.HexToAddress(vaultString)
 	vault := s.manager.GetStakeWiseVault(vaultAddress, network)
 	if vault == nil {
 		handleInputError(w, s.logger, fmt.Errorf("vault with address [%s] on network [%s] not found", vaultAddress.Hex(), network))
@@ -31,7 +32,9 @@ func (s *NodeSetMockServer) depositDataMeta(w http.ResponseWriter, r *http.Reque
 
 	// Write the response
 	data := api.DepositDataMetaData{
-		Version: vault.LatestDepositDataSetIndex,
+		Version:    vault.LatestDepositDataSetIndex,
+		MerkleRoot: db.GetDepositDataMerkleRoot(vault.LatestDepositDataSet),
+		Size:       len(vault.LatestDepositDataSet),
 	}
 	handleSuccess(w, s.logger, data)
 }
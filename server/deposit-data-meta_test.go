@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
 	"github.com/nodeset-org/nodeset-svc-mock/auth"
@@ -109,5 +110,7 @@ func TestDepositDataMeta(t *testing.T) {
 
 	// Make sure the response is correct
 	require.Equal(t, depositDataSet, parsedResponse.Data.Version)
+	require.Equal(t, common.Hash{}, parsedResponse.Data.MerkleRoot)
+	require.Equal(t, 0, parsedResponse.Data.Size)
 	t.Logf("Received correct response - version = %d", parsedResponse.Data.Version)
 }
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+)
+
+func (s *NodeSetMockServer) depositDataVersions(w http.ResponseWriter, r *http.Request) {
+	// Get the requesting node
+	args := s.processApiRequest(w, r, nil)
+	session := s.processAuthHeader(w, r)
+	if session == nil {
+		return
+	}
+	node := s.getNodeForSession(w, session)
+	if node == nil {
+		return
+	}
+
+	// Input validation
+	network, vaultString := getNetworkAndVault(r, args)
+	vaultAddress := common.HexToAddress(vaultString)
+	vault := s.manager.GetStakeWiseVault(vaultAddress, network)
+	if vault == nil {
+		handleError(w, s.logger, fmt.Errorf("%w: address [%s] on network [%s]", db.ErrVaultNotFound, vaultAddress.Hex(), network))
+		return
+	}
+
+	// Write the response
+	data := api.DepositDataVersionsData{
+		Versions: []api.DepositDataSetInfo{},
+	}
+	for _, set := range vault.DepositDataSets {
+		data.Versions = append(data.Versions, api.DepositDataSetInfo{
+			Version:     set.Version,
+			CreatedTime: set.CreatedTime,
+			Pubkeys:     set.GetPubkeys(),
+		})
+	}
+	handleSuccess(w, s.logger, data)
+}
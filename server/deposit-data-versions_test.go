@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure every deposit data set uploaded to the vault is listed, oldest first
+func TestDepositDataVersions(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database without a set
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	session := database.Sessions[0]
+	parsedResponse := runDepositDataVersionsRequest(t, session)
+	require.Empty(t, parsedResponse.Data.Versions)
+
+	// Cycle two sets
+	cycleQuery := map[string]string{
+		"network":      test.Network,
+		"vault":        test.StakeWiseVaultAddressHex,
+		"max-set-size": "2",
+	}
+	firstSet := runCycleSetRequest(t, cycleQuery).Data.Pubkeys
+	secondSet := runCycleSetRequest(t, cycleQuery).Data.Pubkeys
+	require.NotEqual(t, firstSet, secondSet)
+
+	// Make sure both are listed
+	parsedResponse = runDepositDataVersionsRequest(t, session)
+	vault := server.manager.GetStakeWiseVault(test.StakeWiseVaultAddress, test.Network)
+	expectedVersions := []api.DepositDataSetInfo{
+		{
+			Version:     1,
+			CreatedTime: vault.DepositDataSets[0].CreatedTime,
+			Pubkeys:     firstSet,
+		},
+		{
+			Version:     2,
+			CreatedTime: vault.DepositDataSets[1].CreatedTime,
+			Pubkeys:     secondSet,
+		},
+	}
+	require.Len(t, parsedResponse.Data.Versions, len(expectedVersions))
+	for i, expected := range expectedVersions {
+		actual := parsedResponse.Data.Versions[i]
+		require.Equal(t, expected.Version, actual.Version)
+		require.True(t, expected.CreatedTime.Equal(actual.CreatedTime))
+		require.Equal(t, expected.Pubkeys, actual.Pubkeys)
+	}
+	require.Equal(t, secondSet, vault.DepositDataSets[1].GetPubkeys())
+	t.Logf("Received correct response - %d versions", len(parsedResponse.Data.Versions))
+
+	// Make sure an unknown vault is reported as one
+	query := map[string]string{
+		"vault":   "0x0000000000000000000000000000000000000001",
+		"network": test.Network,
+	}
+	statusCode, response := runErrorRequest(t, http.MethodGet, api.DepositDataVersionsPath, session, query, nil)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "invalid_vault", response.Error)
+	t.Log("Unknown vault was rejected")
+}
+
+func runDepositDataVersionsRequest(t *testing.T, session *db.Session) api.NodeSetResponse[api.DepositDataVersionsData] {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/%s", port, api.DepositDataVersionsPath), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	query := request.URL.Query()
+	query.Add("vault", test.StakeWiseVaultAddressHex)
+	query.Add("network", test.Network)
+	request.URL.RawQuery = query.Encode()
+	t.Logf("Created request")
+
+	// Add the auth header
+	auth.AddAuthorizationHeader(request, session)
+	t.Logf("Added auth header")
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	t.Logf("Sent request")
+
+	// Check the status code
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	t.Logf("Received OK status code")
+
+	// Read the body
+	defer response.Body.Close()
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading the response body: %v", err)
+	}
+	var parsedResponse api.NodeSetResponse[api.DepositDataVersionsData]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	if err != nil {
+		t.Fatalf("error deserializing response: %v", err)
+	}
+	t.Log("Received response")
+	return parsedResponse
+}
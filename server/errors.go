@@ -0,0 +1,71 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+)
+
+// The status code and error key the server responds with when a domain error is returned
+type errorResponse struct {
+	err        error
+	statusCode int
+	key        string
+}
+
+// Mapping of the domain errors from the manager, database, and auth packages to the responses the real service
+// sends for them. Errors are matched with errors.Is, in order.
+var errorResponses = []errorResponse{
+	// Auth
+	{err: auth.ErrInvalidSignature, statusCode: http.StatusBadRequest, key: invalidSignatureKey},
+	{err: auth.ErrAuthHeader, statusCode: http.StatusUnauthorized, key: ""},
+	{err: auth.ErrMissingAuthHeader, statusCode: http.StatusUnauthorized, key: ""},
+
+	// Sessions
+	{err: manager.ErrInvalidSession, statusCode: http.StatusUnauthorized, key: invalidSessionKey},
+	{err: db.ErrSessionNotFound, statusCode: http.StatusUnauthorized, key: invalidSessionKey},
+	{err: db.ErrSessionAlreadyLoggedIn, statusCode: http.StatusUnauthorized, key: invalidSessionKey},
+	{err: db.ErrSessionExpired, statusCode: http.StatusUnauthorized, key: invalidSessionKey},
+	{err: db.ErrSessionAddressMismatch, statusCode: http.StatusUnauthorized, key: invalidSessionKey},
+	{err: db.ErrTooManyNonces, statusCode: http.StatusTooManyRequests, key: rateLimitExceededKey},
+
+	// Users and nodes
+	{err: db.ErrUserNotFound, statusCode: http.StatusBadRequest, key: userNotFoundKey},
+	{err: db.ErrUserAlreadyExists, statusCode: http.StatusBadRequest, key: userAlreadyExistsKey},
+	{err: db.ErrNotWhitelisted, statusCode: http.StatusBadRequest, key: addressMissingWhitelistKey},
+	{err: db.ErrAlreadyRegistered, statusCode: http.StatusBadRequest, key: addressAlreadyAuthorizedKey},
+	{err: db.ErrUnregisteredNode, statusCode: http.StatusUnauthorized, key: unregisteredAddressKey},
+
+	// StakeWise
+	{err: db.ErrInvalidNetwork, statusCode: http.StatusBadRequest, key: invalidNetworkKey},
+	{err: db.ErrVaultNotFound, statusCode: http.StatusBadRequest, key: invalidVaultKey},
+	{err: db.ErrVaultAlreadyExists, statusCode: http.StatusBadRequest, key: vaultAlreadyExistsKey},
+	{err: db.ErrDepositDataSetNotFound, statusCode: http.StatusBadRequest, key: depositDataSetNotFoundKey},
+	{err: db.ErrInvalidPubkey, statusCode: http.StatusBadRequest, key: invalidPubkeyKey},
+	{err: db.ErrInvalidDepositData, statusCode: http.StatusBadRequest, key: invalidDepositDataKey},
+	{err: db.ErrValidatorNotFound, statusCode: http.StatusBadRequest, key: validatorNotFoundKey},
+	{err: auth.ErrInvalidExitMessage, statusCode: http.StatusBadRequest, key: invalidExitMessageKey},
+	{err: manager.ErrPlaintextExitsDisabled, statusCode: http.StatusBadRequest, key: unencryptedExitMessageKey},
+
+	// Constellation
+	{err: db.ErrNotConstellationWhitelisted, statusCode: http.StatusBadRequest, key: missingConstellationWhitelistKey},
+	{err: db.ErrMinipoolLimitReached, statusCode: http.StatusBadRequest, key: minipoolLimitReachedKey},
+	{err: db.ErrMinipoolAlreadyExists, statusCode: http.StatusBadRequest, key: minipoolAlreadyExistsKey},
+
+	// Admin
+	{err: manager.ErrSnapshotNotFound, statusCode: http.StatusBadRequest, key: snapshotNotFoundKey},
+	{err: manager.ErrWebhookNotFound, statusCode: http.StatusBadRequest, key: webhookNotFoundKey},
+}
+
+// Get the status code and error key for an error, if it wraps one of the known domain errors
+func getErrorResponse(err error) (int, string, bool) {
+	for _, response := range errorResponses {
+		if errors.Is(err, response.err) {
+			return response.statusCode, response.key, true
+		}
+	}
+	return 0, "", false
+}
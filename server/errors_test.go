@@ -0,0 +1,306 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+	"github.com/rocket-pool/node-manager-core/beacon"
+	"github.com/rocket-pool/node-manager-core/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure each domain error maps to the right status code and error key, even when wrapped
+func TestErrorResponses(t *testing.T) {
+	tests := []struct {
+		err        error
+		statusCode int
+		key        string
+	}{
+		{err: auth.ErrInvalidSignature, statusCode: http.StatusBadRequest, key: "invalid_signature"},
+		{err: manager.ErrInvalidSession, statusCode: http.StatusUnauthorized, key: "invalid_session"},
+		{err: db.ErrSessionNotFound, statusCode: http.StatusUnauthorized, key: "invalid_session"},
+		{err: db.ErrSessionAlreadyLoggedIn, statusCode: http.StatusUnauthorized, key: "invalid_session"},
+		{err: db.ErrSessionExpired, statusCode: http.StatusUnauthorized, key: "invalid_session"},
+		{err: db.ErrSessionAddressMismatch, statusCode: http.StatusUnauthorized, key: "invalid_session"},
+		{err: db.ErrTooManyNonces, statusCode: http.StatusTooManyRequests, key: "rate_limit_exceeded"},
+		{err: db.ErrUserNotFound, statusCode: http.StatusBadRequest, key: "user_not_found"},
+		{err: db.ErrUserAlreadyExists, statusCode: http.StatusBadRequest, key: "user_already_exists"},
+		{err: db.ErrNotWhitelisted, statusCode: http.StatusBadRequest, key: "address_missing_whitelist"},
+		{err: db.ErrAlreadyRegistered, statusCode: http.StatusBadRequest, key: "address_already_authorized"},
+		{err: db.ErrUnregisteredNode, statusCode: http.StatusUnauthorized, key: "unregistered_address"},
+		{err: db.ErrInvalidNetwork, statusCode: http.StatusBadRequest, key: "invalid_network"},
+		{err: db.ErrVaultNotFound, statusCode: http.StatusBadRequest, key: "invalid_vault"},
+		{err: db.ErrVaultAlreadyExists, statusCode: http.StatusBadRequest, key: "vault_already_exists"},
+		{err: db.ErrDepositDataSetNotFound, statusCode: http.StatusBadRequest, key: "deposit_data_set_not_found"},
+		{err: db.ErrInvalidPubkey, statusCode: http.StatusBadRequest, key: "invalid_pubkey"},
+		{err: db.ErrInvalidDepositData, statusCode: http.StatusBadRequest, key: "invalid_deposit_data"},
+		{err: db.ErrValidatorNotFound, statusCode: http.StatusBadRequest, key: "validator_not_found"},
+		{err: auth.ErrInvalidExitMessage, statusCode: http.StatusBadRequest, key: "invalid_exit_message"},
+		{err: manager.ErrPlaintextExitsDisabled, statusCode: http.StatusBadRequest, key: "unencrypted_exit_message"},
+		{err: db.ErrNotConstellationWhitelisted, statusCode: http.StatusBadRequest, key: "missing_whitelist"},
+		{err: db.ErrMinipoolLimitReached, statusCode: http.StatusBadRequest, key: "minipool_limit_reached"},
+		{err: db.ErrMinipoolAlreadyExists, statusCode: http.StatusBadRequest, key: "minipool_already_exists"},
+		{err: manager.ErrSnapshotNotFound, statusCode: http.StatusBadRequest, key: "snapshot_not_found"},
+		{err: manager.ErrWebhookNotFound, statusCode: http.StatusBadRequest, key: "webhook_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			statusCode, key, known := getErrorResponse(fmt.Errorf("wrapped: %w", tt.err))
+			require.True(t, known)
+			require.Equal(t, tt.statusCode, statusCode)
+			require.Equal(t, tt.key, key)
+		})
+	}
+
+	// Unknown errors fall through to a server error
+	_, _, known := getErrorResponse(errors.New("something broke"))
+	require.False(t, known)
+}
+
+// Make sure node registration failures are reported with the right status code and error key
+func TestRegisterNodeErrors(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database with a node that's whitelisted but not registered
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	nodeKey, err := test.GetEthPrivateKey(5)
+	require.NoError(t, err)
+	nodeAddress := crypto.PubkeyToAddress(nodeKey.PublicKey)
+	err = server.manager.WhitelistNodeAccount(test.User0Email, nodeAddress)
+	require.NoError(t, err)
+	otherKey, err := test.GetEthPrivateKey(6)
+	require.NoError(t, err)
+	registeredKey, err := test.GetEthPrivateKey(0)
+	require.NoError(t, err)
+	registeredAddress := crypto.PubkeyToAddress(registeredKey.PublicKey)
+
+	tests := []struct {
+		name       string
+		email      string
+		address    common.Address
+		key        *ecdsa.PrivateKey
+		signature  string
+		statusCode int
+		errorKey   string
+	}{
+		{name: "unknown email", email: "unknown@test.com", address: nodeAddress, key: nodeKey, statusCode: http.StatusBadRequest, errorKey: "user_not_found"},
+		{name: "wrong signer", email: test.User0Email, address: nodeAddress, key: otherKey, statusCode: http.StatusBadRequest, errorKey: "invalid_signature"},
+		{name: "malformed signature", email: test.User0Email, address: nodeAddress, key: nodeKey, signature: "0xzz", statusCode: http.StatusBadRequest, errorKey: "invalid_signature"},
+		{name: "not whitelisted", email: test.User2Email, address: nodeAddress, key: nodeKey, statusCode: http.StatusBadRequest, errorKey: "address_missing_whitelist"},
+		{name: "already registered", email: test.User1Email, address: registeredAddress, key: registeredKey, statusCode: http.StatusBadRequest, errorKey: "address_already_authorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := tt.signature
+			if signature == "" {
+				sig, err := auth.GetSignatureForRegistration(tt.email, tt.address, tt.key)
+				require.NoError(t, err)
+				signature = utils.EncodeHexWithPrefix(sig)
+			}
+			body, err := json.Marshal(api.RegisterNodeRequest{
+				Email:       tt.email,
+				NodeAddress: tt.address.Hex(),
+				Signature:   signature,
+			})
+			require.NoError(t, err)
+
+			statusCode, response := runErrorRequest(t, http.MethodPost, api.RegisterPath, nil, nil, body)
+			require.Equal(t, tt.statusCode, statusCode)
+			require.Equal(t, tt.errorKey, response.Error)
+			require.False(t, response.OK)
+		})
+	}
+}
+
+// Make sure deposit data and signed exit upload failures are reported with the right status code and error key
+func TestUploadErrors(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	session := database.Sessions[0]
+
+	// Deposit data for an unknown network and vault
+	unknownNetworkData := idb.GenerateDepositData(t, 5, test.StakeWiseVaultAddress)
+	unknownNetworkData.NetworkName = "unknown"
+	unknownVaultData := idb.GenerateDepositData(t, 5, common.HexToAddress("0x01"))
+	otherNodePubkey := beacon.ValidatorPubkey(idb.GenerateDepositData(t, 1, test.StakeWiseVaultAddress).PublicKey)
+
+	// Signed exits encrypted to the wrong key, and with a malformed signature
+	ownExit := idb.GenerateSignedExit(t, 0)
+	otherIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	wrongKeyExit, err := auth.EncryptExitMessage(ownExit.ExitMessage, otherIdentity.Recipient().String())
+	require.NoError(t, err)
+	malformedExitMessage := ownExit.ExitMessage
+	malformedExitMessage.Signature = "0x1234"
+	malformedExit, err := auth.EncryptExitMessage(malformedExitMessage, server.manager.GetExitEncryptionPublicKey())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		network    string
+		body       any
+		statusCode int
+		errorKey   string
+	}{
+		{
+			name:       "deposit data for unknown network",
+			method:     http.MethodPost,
+			path:       api.DepositDataPath,
+			body:       []beacon.ExtendedDepositData{unknownNetworkData},
+			statusCode: http.StatusBadRequest,
+			errorKey:   "invalid_network",
+		},
+		{
+			name:       "deposit data for unknown vault",
+			method:     http.MethodPost,
+			path:       api.DepositDataPath,
+			body:       []beacon.ExtendedDepositData{unknownVaultData},
+			statusCode: http.StatusBadRequest,
+			errorKey:   "invalid_vault",
+		},
+		{
+			name:       "exit for unknown validator",
+			method:     http.MethodPatch,
+			path:       api.ValidatorsPath,
+			network:    test.Network,
+			body:       []api.EncryptedExitData{encryptSignedExit(t, api.ExitData{Pubkey: otherNodePubkey.Hex(), ExitMessage: ownExit.ExitMessage})},
+			statusCode: http.StatusBadRequest,
+			errorKey:   "validator_not_found",
+		},
+		{
+			name:       "exit for unknown network",
+			method:     http.MethodPatch,
+			path:       api.ValidatorsPath,
+			network:    "unknown",
+			body:       []api.EncryptedExitData{encryptSignedExit(t, api.ExitData{Pubkey: otherNodePubkey.Hex(), ExitMessage: ownExit.ExitMessage})},
+			statusCode: http.StatusBadRequest,
+			errorKey:   "invalid_network",
+		},
+		{
+			name:       "exit with malformed pubkey",
+			method:     http.MethodPatch,
+			path:       api.ValidatorsPath,
+			network:    test.Network,
+			body:       []api.EncryptedExitData{{Pubkey: "0x1234", ExitMessage: wrongKeyExit}},
+			statusCode: http.StatusBadRequest,
+			errorKey:   "invalid_pubkey",
+		},
+		{
+			name:       "plaintext exit",
+			method:     http.MethodPatch,
+			path:       api.ValidatorsPath,
+			network:    test.Network,
+			body:       []api.ExitData{ownExit},
+			statusCode: http.StatusBadRequest,
+			errorKey:   "unencrypted_exit_message",
+		},
+		{
+			name:       "exit encrypted to another key",
+			method:     http.MethodPatch,
+			path:       api.ValidatorsPath,
+			network:    test.Network,
+			body:       []api.EncryptedExitData{{Pubkey: ownExit.Pubkey, ExitMessage: wrongKeyExit}},
+			statusCode: http.StatusBadRequest,
+			errorKey:   "invalid_exit_message",
+		},
+		{
+			name:       "exit that isn't encrypted",
+			method:     http.MethodPatch,
+			path:       api.ValidatorsPath,
+			network:    test.Network,
+			body:       []api.EncryptedExitData{{Pubkey: ownExit.Pubkey, ExitMessage: "0x1234"}},
+			statusCode: http.StatusBadRequest,
+			errorKey:   "invalid_exit_message",
+		},
+		{
+			name:       "exit with malformed signature",
+			method:     http.MethodPatch,
+			path:       api.ValidatorsPath,
+			network:    test.Network,
+			body:       []api.EncryptedExitData{{Pubkey: ownExit.Pubkey, ExitMessage: malformedExit}},
+			statusCode: http.StatusBadRequest,
+			errorKey:   "invalid_exit_message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+			query := map[string]string{}
+			if tt.network != "" {
+				query["network"] = tt.network
+			}
+
+			statusCode, response := runErrorRequest(t, tt.method, tt.path, session, query, body)
+			require.Equal(t, tt.statusCode, statusCode)
+			require.Equal(t, tt.errorKey, response.Error)
+			require.False(t, response.OK)
+		})
+	}
+}
+
+// Run a request against an API route that's expected to fail, returning the status code and parsed error response
+func runErrorRequest(t *testing.T, method string, path string, session *db.Session, queryParams map[string]string, body []byte) (int, api.NodeSetResponse[struct{}]) {
+	// Create the request
+	request, err := http.NewRequest(method, fmt.Sprintf("http://localhost:%d/api/%s", port, path), bytes.NewReader(body))
+	require.NoError(t, err)
+	query := request.URL.Query()
+	for name, value := range queryParams {
+		query.Add(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+	if session != nil {
+		auth.AddAuthorizationHeader(request, session)
+	}
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	// Read the body
+	bytes, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	var parsedResponse api.NodeSetResponse[struct{}]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	require.NoError(t, err)
+	return response.StatusCode, parsedResponse
+}
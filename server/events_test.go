@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the manager's subscription API delivers state change events
+func TestEventSubscription(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+
+	// Subscribe
+	subscription := server.manager.SubscribeToEvents(64)
+	defer subscription.Unsubscribe()
+
+	// Add a user
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminAddUserPath, "", map[string]string{"email": "events@nodeset.io"}))
+	event := waitForEvent(t, subscription.Events, api.EventType_UserAdded)
+	require.Equal(t, api.UserAddedEventData{Email: "events@nodeset.io"}, event.Data)
+	t.Log("Received user added event")
+
+	// Cycle a set
+	runCycleSetRequest(t, map[string]string{
+		"network":      test.Network,
+		"vault":        test.StakeWiseVaultAddressHex,
+		"max-set-size": "1",
+	})
+	event = waitForEvent(t, subscription.Events, api.EventType_SetCycled)
+	setData := event.Data.(api.SetCycledEventData)
+	require.Equal(t, test.Network, setData.Network)
+	require.Len(t, setData.Pubkeys, 1)
+	event = waitForEvent(t, subscription.Events, api.EventType_ValidatorStatusChanged)
+	statusData := event.Data.(api.ValidatorStatusChangedEventData)
+	require.Equal(t, setData.Pubkeys[0], statusData.Pubkey)
+	t.Logf("Received set cycled and status changed events - status = %s", statusData.Status)
+}
+
+// Make sure the admin events route streams state change events
+func TestEventStream(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Open the stream
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/%s", port, api.AdminEventsPath), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	query := request.URL.Query()
+	query.Add("types", string(api.EventType_UserAdded))
+	request.URL.RawQuery = query.Encode()
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	// Wait for the stream to be ready
+	reader := bufio.NewReader(response.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": connected\n", line)
+	t.Log("Opened event stream")
+
+	// Add a user, which should show up on the stream
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminAddUserPath, "", map[string]string{"email": "stream@nodeset.io"}))
+	fields := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(fields) > 0 {
+				break
+			}
+			continue
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		fields[name] = value
+	}
+	require.Equal(t, string(api.EventType_UserAdded), fields["event"])
+	var event struct {
+		ID   uint64                 `json:"id"`
+		Type api.EventType          `json:"type"`
+		Data api.UserAddedEventData `json:"data"`
+	}
+	err = json.Unmarshal([]byte(fields["data"]), &event)
+	require.NoError(t, err)
+	require.Equal(t, fields["id"], fmt.Sprint(event.ID))
+	require.Equal(t, "stream@nodeset.io", event.Data.Email)
+	t.Logf("Received event %d on the stream", event.ID)
+}
+
+// Wait for an event of the provided type, skipping any others
+func waitForEvent(t *testing.T, events <-chan api.Event, eventType api.EventType) api.Event {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("event stream closed while waiting for %s event", eventType)
+			}
+			if event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s event", eventType)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+func (s *NodeSetMockServer) freezeTime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	frozen := true
+	frozenString := r.URL.Query().Get("frozen")
+	if frozenString != "" {
+		var err error
+		frozen, err = strconv.ParseBool(frozenString)
+		if err != nil {
+			handleInputError(w, s.logger, fmt.Errorf("error parsing frozen: %w", err))
+			return
+		}
+	}
+
+	if frozen {
+		s.manager.FreezeTime()
+	} else {
+		s.manager.UnfreezeTime()
+	}
+	handleSuccess(w, s.logger, s.getTimeData())
+}
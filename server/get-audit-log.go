@@ -0,0 +1,84 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+)
+
+func (s *NodeSetMockServer) getAuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	query := r.URL.Query()
+	actor := query.Get("actor")
+	route := query.Get("route")
+	var nodeAddress *common.Address
+	nodeString := query.Get("node")
+	if nodeString != "" {
+		if !common.IsHexAddress(nodeString) {
+			handleInputError(w, s.logger, fmt.Errorf("invalid node address [%s]", nodeString))
+			return
+		}
+		address := common.HexToAddress(nodeString)
+		nodeAddress = &address
+	}
+	limit := 0
+	limitString := query.Get("limit")
+	if limitString != "" {
+		var err error
+		limit, err = strconv.Atoi(limitString)
+		if err != nil || limit < 0 {
+			handleInputError(w, s.logger, fmt.Errorf("invalid limit [%s]", limitString))
+			return
+		}
+	}
+
+	// Filter the log
+	entries := []api.AuditEntry{}
+	for _, entry := range s.manager.GetAuditLog() {
+		if actor != "" && entry.Actor != actor {
+			continue
+		}
+		if route != "" && !strings.Contains(entry.Route, route) {
+			continue
+		}
+		if nodeAddress != nil && (entry.NodeAddress == nil || *entry.NodeAddress != *nodeAddress) {
+			continue
+		}
+		entries = append(entries, getAuditEntryData(entry))
+	}
+
+	// Only keep the most recent entries if there's a limit
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	data := api.AuditLogData{
+		Entries: entries,
+	}
+	handleSuccess(w, s.logger, data)
+}
+
+// Convert an audit entry to its API representation
+func getAuditEntryData(entry *db.AuditEntry) api.AuditEntry {
+	data := api.AuditEntry{
+		Time:       entry.Time,
+		Actor:      entry.Actor,
+		Route:      entry.Route,
+		StatusCode: entry.StatusCode,
+		Success:    entry.Success,
+		Error:      entry.Error,
+	}
+	if entry.NodeAddress != nil {
+		data.NodeAddress = entry.NodeAddress.Hex()
+	}
+	return data
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+func (s *NodeSetMockServer) getCycleSchedules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	data := api.CycleSchedulesData{
+		Schedules: []api.CycleSchedule{},
+	}
+	defaultSchedule := s.manager.GetDefaultCycleSchedule()
+	if defaultSchedule != nil {
+		scheduleData := getCycleScheduleData(*defaultSchedule)
+		data.Default = &scheduleData
+	}
+	for _, schedule := range s.manager.GetCycleSchedules() {
+		scheduleData := getCycleScheduleData(schedule.CycleSchedule)
+		scheduleData.Network = schedule.Network
+		scheduleData.Vault = schedule.VaultAddress.Hex()
+		data.Schedules = append(data.Schedules, scheduleData)
+	}
+	handleSuccess(w, s.logger, data)
+}
@@ -3,9 +3,12 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
 )
 
 func (s *NodeSetMockServer) getDepositData(w http.ResponseWriter, r *http.Request) {
@@ -21,18 +24,64 @@ func (s *NodeSetMockServer) getDepositData(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Input validation
-	network := args.Get("network")
-	vaultAddress := common.HexToAddress(args.Get("vault"))
+	network, vaultString := getNetworkAndVault(r, args)
+	vaultAddress := common.HexToAddress(vaultString)
 	vault := s.manager.GetStakeWiseVault(vaultAddress, network)
 	if vault == nil {
 		handleInputError(w, s.logger, fmt.Errorf("vault with address [%s] on network [%s] not found", vaultAddress.Hex(), network))
 		return
 	}
 
+	// Serve an earlier set if a version was requested, so clients that missed a cycle can catch up
+	version := vault.LatestDepositDataSetIndex
+	depositData := vault.LatestDepositDataSet
+	versionString := args.Get("version")
+	if versionString != "" {
+		var err error
+		version, err = strconv.Atoi(versionString)
+		if err != nil || version < 1 {
+			handleInputError(w, s.logger, fmt.Errorf("invalid version [%s]", versionString))
+			return
+		}
+		set := vault.GetDepositDataSet(version)
+		if set == nil {
+			handleError(w, s.logger, fmt.Errorf("%w: vault [%s] version [%d]", db.ErrDepositDataSetNotFound, vaultAddress.Hex(), version))
+			return
+		}
+		depositData = set.DepositData
+	}
+
+	// Skip the data if the client already has it
+	merkleRoot := db.GetDepositDataMerkleRoot(depositData)
+	etag := getDepositDataETag(version, merkleRoot)
+	w.Header().Set("ETag", etag)
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		handleNotModified(w, s.logger)
+		return
+	}
+
 	// Write the data
 	data := api.DepositDataData{
-		Version:     vault.LatestDepositDataSetIndex,
-		DepositData: vault.LatestDepositDataSet,
+		Version:     version,
+		MerkleRoot:  merkleRoot,
+		Size:        len(depositData),
+		DepositData: depositData,
 	}
 	handleSuccess(w, s.logger, data)
 }
+
+// Gets the ETag for a version of a vault's deposit data. It's strong, since the version and root pin the exact set.
+func getDepositDataETag(version int, merkleRoot common.Hash) string {
+	return fmt.Sprintf("\"%d-%s\"", version, merkleRoot.Hex())
+}
+
+// Checks if an If-None-Match header matches an ETag. The header can list several ETags, or be * to match any.
+func matchesETag(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
 	"github.com/nodeset-org/nodeset-svc-mock/auth"
 	"github.com/nodeset-org/nodeset-svc-mock/db"
@@ -29,21 +31,154 @@ func TestGetDepositData(t *testing.T) {
 	}()
 
 	// Provision the database
-	db := idb.ProvisionFullDatabase(t, logger, true)
-	server.manager.SetDatabase(db)
+	database := idb.ProvisionFullDatabase(t, logger, true)
+	require.NoError(t, server.manager.SetDatabase(database))
 
 	// Run a get deposit data request
-	parsedResponse := runGetDepositDataRequest(t, db.Sessions[0])
+	parsedResponse := runGetDepositDataRequest(t, database.Sessions[0])
 
 	// Make sure the response is correct
-	vault := db.StakeWiseVaults[test.Network][0]
+	vault := database.StakeWiseVaults[test.Network][0]
 	require.Equal(t, vault.LatestDepositDataSetIndex, parsedResponse.Data.Version)
 	require.Equal(t, vault.LatestDepositDataSet, parsedResponse.Data.DepositData)
 	require.Greater(t, len(parsedResponse.Data.DepositData), 0)
+	require.Equal(t, db.GetDepositDataMerkleRoot(vault.LatestDepositDataSet), parsedResponse.Data.MerkleRoot)
+	require.NotEqual(t, common.Hash{}, parsedResponse.Data.MerkleRoot)
+	require.Equal(t, len(vault.LatestDepositDataSet), parsedResponse.Data.Size)
 	t.Logf("Received correct response - version = %d, deposit data matches", parsedResponse.Data.Version)
 }
 
+// Make sure earlier deposit data sets can be retrieved by version after a new one is cycled
+func TestGetDepositDataVersion(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database and cycle a second set
+	database := idb.ProvisionFullDatabase(t, logger, true)
+	require.NoError(t, server.manager.SetDatabase(database))
+	firstSet := database.StakeWiseVaults[test.Network][0].LatestDepositDataSet
+	runCycleSetRequest(t, map[string]string{
+		"network": test.Network,
+		"vault":   test.StakeWiseVaultAddressHex,
+	})
+	vault := server.manager.GetStakeWiseVault(test.StakeWiseVaultAddress, test.Network)
+	require.Equal(t, 2, vault.LatestDepositDataSetIndex)
+	secondSet := vault.LatestDepositDataSet
+	require.NotEqual(t, firstSet, secondSet)
+
+	// The latest set is still served without a version
+	session := database.Sessions[0]
+	parsedResponse := runGetDepositDataRequest(t, session)
+	require.Equal(t, 2, parsedResponse.Data.Version)
+	require.Equal(t, secondSet, parsedResponse.Data.DepositData)
+
+	// Each set can be retrieved by its version
+	parsedResponse = runGetDepositDataVersionRequest(t, session, 1)
+	require.Equal(t, 1, parsedResponse.Data.Version)
+	require.Equal(t, firstSet, parsedResponse.Data.DepositData)
+	parsedResponse = runGetDepositDataVersionRequest(t, session, 2)
+	require.Equal(t, 2, parsedResponse.Data.Version)
+	require.Equal(t, secondSet, parsedResponse.Data.DepositData)
+	t.Log("Received both sets by version")
+
+	// Versions the vault doesn't have are rejected
+	query := map[string]string{
+		"vault":   test.StakeWiseVaultAddressHex,
+		"network": test.Network,
+		"version": "3",
+	}
+	statusCode, response := runErrorRequest(t, http.MethodGet, api.DepositDataPath, session, query, nil)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "deposit_data_set_not_found", response.Error)
+	query["version"] = "latest"
+	statusCode, _ = runErrorRequest(t, http.MethodGet, api.DepositDataPath, session, query, nil)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	t.Log("Missing and malformed versions were rejected")
+}
+
+// Make sure deposit data requests with a matching If-None-Match header are answered without the data
+func TestGetDepositDataETag(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, true)
+	require.NoError(t, server.manager.SetDatabase(database))
+	session := database.Sessions[0]
+
+	// Get the ETag for the current set
+	statusCode, firstETag := runGetDepositDataETagRequest(t, session, map[string]string{}, "")
+	require.Equal(t, http.StatusOK, statusCode)
+	require.NotEmpty(t, firstETag)
+
+	// The data is skipped when the client already has it, including when it's listed with other ETags
+	statusCode, etag := runGetDepositDataETagRequest(t, session, map[string]string{}, firstETag)
+	require.Equal(t, http.StatusNotModified, statusCode)
+	require.Equal(t, firstETag, etag)
+	statusCode, _ = runGetDepositDataETagRequest(t, session, map[string]string{}, `"other", W/`+firstETag)
+	require.Equal(t, http.StatusNotModified, statusCode)
+	t.Log("Matching ETags weren't sent the data")
+
+	// Once a new set is cycled, the old ETag is stale for the latest set but still matches its version
+	runCycleSetRequest(t, map[string]string{
+		"network": test.Network,
+		"vault":   test.StakeWiseVaultAddressHex,
+	})
+	statusCode, etag = runGetDepositDataETagRequest(t, session, map[string]string{}, firstETag)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.NotEqual(t, firstETag, etag)
+	statusCode, _ = runGetDepositDataETagRequest(t, session, map[string]string{"version": "1"}, firstETag)
+	require.Equal(t, http.StatusNotModified, statusCode)
+	t.Log("Stale ETags were sent the new set")
+}
+
 func runGetDepositDataRequest(t *testing.T, session *db.Session) api.NodeSetResponse[api.DepositDataData] {
+	return runGetDepositDataRequestWithQuery(t, session, map[string]string{})
+}
+
+func runGetDepositDataVersionRequest(t *testing.T, session *db.Session, version int) api.NodeSetResponse[api.DepositDataData] {
+	return runGetDepositDataRequestWithQuery(t, session, map[string]string{
+		"version": strconv.Itoa(version),
+	})
+}
+
+// Run a get deposit data request with an If-None-Match header, returning the status code and ETag
+func runGetDepositDataETagRequest(t *testing.T, session *db.Session, queryParams map[string]string, ifNoneMatch string) (int, string) {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/%s", port, api.DepositDataPath), nil)
+	require.NoError(t, err)
+	query := request.URL.Query()
+	query.Add("vault", test.StakeWiseVaultAddressHex)
+	query.Add("network", test.Network)
+	for name, value := range queryParams {
+		query.Add(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+	if ifNoneMatch != "" {
+		request.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	auth.AddAuthorizationHeader(request, session)
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	return response.StatusCode, response.Header.Get("ETag")
+}
+
+func runGetDepositDataRequestWithQuery(t *testing.T, session *db.Session, queryParams map[string]string) api.NodeSetResponse[api.DepositDataData] {
 	// Create the request
 	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/%s", port, api.DepositDataPath), nil)
 	if err != nil {
@@ -52,6 +187,9 @@ func runGetDepositDataRequest(t *testing.T, session *db.Session) api.NodeSetResp
 	query := request.URL.Query()
 	query.Add("vault", utils.RemovePrefix(strings.ToLower(test.StakeWiseVaultAddressHex)))
 	query.Add("network", test.Network)
+	for name, value := range queryParams {
+		query.Add(name, value)
+	}
 	request.URL.RawQuery = query.Encode()
 	t.Logf("Created request")
 
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+func (s *NodeSetMockServer) getEncryptionIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	handleSuccess(w, s.logger, api.EncryptionIdentityData{
+		PublicKey: s.manager.GetExitEncryptionPublicKey(),
+	})
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+func (s *NodeSetMockServer) getHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// The server is healthy if it can respond at all
+	handleSuccess(w, s.logger, api.HealthData{
+		Status: "ok",
+	})
+}
@@ -1,14 +1,31 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
 )
 
 func (s *NodeSetMockServer) getNonce(w http.ResponseWriter, r *http.Request) {
+	// Nonces can optionally be bound to the node that requested them
+	var boundAddress common.Address
+	addressString := r.URL.Query().Get("address")
+	if addressString != "" {
+		if !common.IsHexAddress(addressString) {
+			handleInputError(w, s.logger, fmt.Errorf("invalid address [%s]", addressString))
+			return
+		}
+		boundAddress = common.HexToAddress(addressString)
+	}
+
 	// Create a new session
-	session := s.manager.CreateSession()
+	session, err := s.manager.CreateClientSession(getClientIp(r), boundAddress)
+	if err != nil {
+		handleError(w, s.logger, err)
+		return
+	}
 
 	// Write the response
 	data := api.NonceData{
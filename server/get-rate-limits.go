@@ -0,0 +1,19 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+func (s *NodeSetMockServer) getRateLimits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	data := api.RateLimitsData{
+		Limits: s.GetRateLimits(),
+	}
+	handleSuccess(w, s.logger, data)
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+func (s *NodeSetMockServer) getReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	status := s.GetReadinessStatus()
+	data := api.ReadyData{
+		Ready:  status == api.ReadinessStatus_Ready,
+		Status: status,
+	}
+	if !data.Ready {
+		handleNotReady(w, s.logger, data)
+		return
+	}
+	handleSuccess(w, s.logger, data)
+}
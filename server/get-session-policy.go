@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+)
+
+func (s *NodeSetMockServer) getSessionPolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	handleSuccess(w, s.logger, getSessionPolicyData(s.manager.GetSessionPolicy()))
+}
+
+// Converts a session policy into its API form
+func getSessionPolicyData(policy manager.SessionPolicy) api.SessionPolicyData {
+	return api.SessionPolicyData{
+		NonceLifetime:      policy.NonceLifetime.String(),
+		MaxNoncesPerClient: policy.MaxNoncesPerClient,
+		RevokeOldSessions:  policy.RevokeOldSessions,
+	}
+}
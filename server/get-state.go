@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	"github.com/rocket-pool/node-manager-core/beacon"
+)
+
+func (s *NodeSetMockServer) getState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	data := api.StateData{
+		Time:      s.manager.GetTime(),
+		Users:     []api.StateUser{},
+		Vaults:    []api.StateVault{},
+		Snapshots: s.manager.GetSnapshotNames(),
+	}
+
+	// Add the users and their nodes
+	for _, user := range s.manager.GetUsers() {
+		userData := api.StateUser{
+			Email:         user.Email,
+			MinipoolLimit: user.MinipoolLimit,
+			CreatedTime:   user.CreatedTime,
+			Nodes:         []api.StateNode{},
+		}
+		for _, node := range user.RegisteredNodes {
+			userData.Nodes = append(userData.Nodes, s.getStateNode(node, true))
+		}
+		for _, node := range user.WhitelistedNodes {
+			userData.Nodes = append(userData.Nodes, s.getStateNode(node, false))
+		}
+		data.Users = append(data.Users, userData)
+	}
+
+	// Add the vaults
+	vaults := s.manager.GetStakeWiseVaults()
+	for _, network := range getSortedKeys(vaults) {
+		for _, vault := range vaults[network] {
+			vaultData := api.StateVault{
+				Network:       network,
+				Address:       vault.Address.Hex(),
+				Version:       vault.LatestDepositDataSetIndex,
+				LatestSet:     []beacon.ValidatorPubkey{},
+				UploadedCount: len(vault.UploadedData),
+			}
+			for _, depositData := range vault.LatestDepositDataSet {
+				vaultData.LatestSet = append(vaultData.LatestSet, beacon.ValidatorPubkey(depositData.PublicKey))
+			}
+			data.Vaults = append(data.Vaults, vaultData)
+		}
+	}
+	handleSuccess(w, s.logger, data)
+}
+
+// Converts a node into its state dump form
+func (s *NodeSetMockServer) getStateNode(node *db.Node, registered bool) api.StateNode {
+	nodeData := api.StateNode{
+		Address:     node.Address.Hex(),
+		Registered:  registered,
+		CreatedTime: node.CreatedTime,
+		Validators:  []api.StateValidator{},
+	}
+	if registered {
+		registeredTime := node.RegisteredTime
+		nodeData.RegisteredTime = &registeredTime
+	}
+	for _, network := range getSortedKeys(node.Validators) {
+		for _, validator := range node.Validators[network] {
+			validatorData := api.StateValidator{
+				Network:         network,
+				Pubkey:          validator.Pubkey,
+				Vault:           validator.VaultAddress.Hex(),
+				Status:          s.manager.GetValidatorStatus(network, validator.Pubkey),
+				DepositDataUsed: validator.DepositDataUsed,
+				ExitUploaded:    validator.ExitMessageUploaded,
+			}
+			if validator.HasBeaconIndex {
+				index := validator.BeaconIndex
+				validatorData.BeaconIndex = &index
+			}
+			nodeData.Validators = append(nodeData.Validators, validatorData)
+		}
+	}
+	return nodeData
+}
+
+// Gets the keys of a map keyed by network, in alphabetical order
+func getSortedKeys[ValueType any](networkMap map[string]ValueType) []string {
+	keys := make([]string, 0, len(networkMap))
+	for key := range networkMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+func (s *NodeSetMockServer) getTime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+	handleSuccess(w, s.logger, s.getTimeData())
+}
+
+// Get the current state of the manager's clock
+func (s *NodeSetMockServer) getTimeData() api.TimeData {
+	clock := s.manager.GetClock()
+	return api.TimeData{
+		Time:   clock.Now(),
+		Frozen: clock.IsFrozen(),
+		Slot:   s.manager.GetBeaconSlot(),
+		Epoch:  s.manager.GetBeaconEpoch(),
+	}
+}
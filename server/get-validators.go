@@ -19,7 +19,7 @@ func (s *NodeSetMockServer) getValidators(w http.ResponseWriter, r *http.Request
 	}
 
 	// Get the registered validators
-	network := args.Get("network")
+	network, _ := getNetworkAndVault(r, args)
 	validatorStatuses := []api.ValidatorStatus{}
 	validatorsForNetwork := node.Validators[network]
 
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/version"
+)
+
+func (s *NodeSetMockServer) getVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	handleSuccess(w, s.logger, api.VersionData{
+		Version: version.Version,
+		Commit:  version.GetCommit(),
+	})
+}
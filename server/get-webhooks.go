@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+)
+
+func (s *NodeSetMockServer) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	query := r.URL.Query()
+	var webhookId uint64
+	idString := query.Get("id")
+	if idString != "" {
+		var err error
+		webhookId, err = strconv.ParseUint(idString, 10, 64)
+		if err != nil {
+			handleInputError(w, s.logger, fmt.Errorf("invalid webhook ID [%s]", idString))
+			return
+		}
+	}
+	limit := 0
+	limitString := query.Get("limit")
+	if limitString != "" {
+		var err error
+		limit, err = strconv.Atoi(limitString)
+		if err != nil || limit < 0 {
+			handleInputError(w, s.logger, fmt.Errorf("invalid limit [%s]", limitString))
+			return
+		}
+	}
+
+	data := api.WebhooksData{
+		Webhooks:   []api.Webhook{},
+		Deliveries: []api.WebhookDelivery{},
+	}
+	for _, webhook := range s.manager.GetWebhooks() {
+		data.Webhooks = append(data.Webhooks, getWebhookData(webhook))
+	}
+	for _, delivery := range s.manager.GetWebhookDeliveries() {
+		if webhookId != 0 && delivery.WebhookID != webhookId {
+			continue
+		}
+		data.Deliveries = append(data.Deliveries, api.WebhookDelivery{
+			Time:       delivery.Time,
+			WebhookID:  delivery.WebhookID,
+			URL:        delivery.URL,
+			EventID:    delivery.EventID,
+			EventType:  delivery.EventType,
+			Attempts:   delivery.Attempts,
+			StatusCode: delivery.StatusCode,
+			Success:    delivery.Success,
+			Error:      delivery.Error,
+		})
+	}
+
+	// Only keep the most recent deliveries if there's a limit
+	if limit > 0 && len(data.Deliveries) > limit {
+		data.Deliveries = data.Deliveries[len(data.Deliveries)-limit:]
+	}
+	handleSuccess(w, s.logger, data)
+}
+
+// Converts a webhook into its API form, leaving out the secret
+func getWebhookData(webhook manager.Webhook) api.Webhook {
+	types := webhook.Types
+	if types == nil {
+		types = []api.EventType{}
+	}
+	return api.Webhook{
+		ID:             webhook.ID,
+		URL:            webhook.URL,
+		Signed:         webhook.Secret != "",
+		Types:          types,
+		MaxAttempts:    webhook.RetryPolicy.MaxAttempts,
+		InitialBackoff: webhook.RetryPolicy.InitialBackoff.String(),
+		MaxBackoff:     webhook.RetryPolicy.MaxBackoff.String(),
+	}
+}
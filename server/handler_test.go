@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the mock can be mounted as a handler in an httptest server alongside other handlers
+func TestHttpHandler(t *testing.T) {
+	mock, err := NewNodeSetMockServer(logger, "", 0)
+	require.NoError(t, err)
+	defer mock.Stop()
+
+	// Mount it on a custom mux with another handler
+	mux := http.NewServeMux()
+	mux.Handle("/", mock)
+	mux.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	// Make sure both are served
+	requireNonce(t, testServer.Client(), testServer.URL)
+	response, err := testServer.Client().Get(testServer.URL + "/other")
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusTeapot, response.StatusCode)
+
+	// Make sure the admin routes are served too
+	response, err = testServer.Client().Get(testServer.URL + "/admin/" + api.AdminSnapshotsPath)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	t.Log("Served the mock from an httptest server")
+}
+
+// Make sure the mock can be served on a supplied Unix domain socket
+func TestUnixSocketListener(t *testing.T) {
+	mock, err := NewNodeSetMockServer(logger, "", 0)
+	require.NoError(t, err)
+	socketPath := filepath.Join(t.TempDir(), "mock.sock")
+	socket, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	// Start it
+	wg := &sync.WaitGroup{}
+	err = mock.StartWithListener(wg, socket)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, mock.Stop())
+		wg.Wait()
+	}()
+	require.Equal(t, uint16(0), mock.GetPort())
+
+	// Make a request over the socket
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+	requireNonce(t, client, "http://localhost")
+	t.Log("Served the mock on a Unix socket")
+}
+
+// Make sure the nonce route works on a mock served at the base URL
+func requireNonce(t *testing.T, client *http.Client, baseUrl string) {
+	response, err := client.Get(baseUrl + "/api/" + api.NoncePath)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	bytes, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	var parsedResponse api.NodeSetResponse[api.NonceData]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	require.NoError(t, err)
+	require.NotEmpty(t, parsedResponse.Data.Nonce)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/version"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the health and version routes respond
+func TestHealthAndVersion(t *testing.T) {
+	var health api.NodeSetResponse[api.HealthData]
+	require.Equal(t, http.StatusOK, runServiceRequest(t, api.HealthPath, &health))
+	require.Equal(t, "ok", health.Data.Status)
+
+	var versionResponse api.NodeSetResponse[api.VersionData]
+	require.Equal(t, http.StatusOK, runServiceRequest(t, api.VersionPath, &versionResponse))
+	require.Equal(t, version.Version, versionResponse.Data.Version)
+	require.NotEmpty(t, versionResponse.Data.Commit)
+	t.Logf("Version = %s, commit = %s", versionResponse.Data.Version, versionResponse.Data.Commit)
+}
+
+// Make sure the readiness route reports when seeding is finished
+func TestReadiness(t *testing.T) {
+	defer server.SetReadinessStatus(api.ReadinessStatus_Ready)
+
+	// The mock is ready by default
+	var ready api.NodeSetResponse[api.ReadyData]
+	require.Equal(t, http.StatusOK, runServiceRequest(t, api.ReadyPath, &ready))
+	require.True(t, ready.Data.Ready)
+
+	// Wait for seeding
+	server.SetReadinessStatus(api.ReadinessStatus_Seeding)
+	require.Equal(t, http.StatusServiceUnavailable, runServiceRequest(t, api.ReadyPath, &ready))
+	require.False(t, ready.Data.Ready)
+	require.Equal(t, api.ReadinessStatus_Seeding, ready.Data.Status)
+	require.Equal(t, notReadyKey, ready.Error)
+	t.Log("Mock isn't ready while waiting for seeding")
+
+	// Mark seeding as finished
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminSetReadyPath, "", nil))
+	require.Equal(t, http.StatusOK, runServiceRequest(t, api.ReadyPath, &ready))
+	require.True(t, ready.Data.Ready)
+	require.Equal(t, api.ReadinessStatus_Ready, ready.Data.Status)
+	t.Log("Mock is ready after seeding is finished")
+}
+
+// Run a request to one of the service routes and return the status code
+func runServiceRequest(t *testing.T, path string, parsedResponse any) int {
+	response, err := http.Get(fmt.Sprintf("http://localhost:%d/%s", port, path))
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	defer response.Body.Close()
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading the response body: %v", err)
+	}
+	err = json.Unmarshal(bytes, parsedResponse)
+	if err != nil {
+		t.Fatalf("error deserializing response: %v", err)
+	}
+	return response.StatusCode
+}
@@ -1,14 +1,12 @@
 package server
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
-	"github.com/nodeset-org/nodeset-svc-mock/db"
-	"github.com/rocket-pool/node-manager-core/utils"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
 )
 
 func (s *NodeSetMockServer) login(w http.ResponseWriter, r *http.Request) {
@@ -19,26 +17,28 @@ func (s *NodeSetMockServer) login(w http.ResponseWriter, r *http.Request) {
 
 	// Get the login request
 	var request api.LoginRequest
-	_ = s.processApiRequest(w, r, &request)
+	if s.processApiRequest(w, r, &request) == nil {
+		return
+	}
 	session := s.processAuthHeader(w, r)
 	if session == nil {
 		return
 	}
+	if request.Nonce != session.Nonce {
+		handleInvalidSessionError(w, s.logger, fmt.Errorf("nonce doesn't belong to the session in the auth header"))
+		return
+	}
 
 	// Log it in
 	address := common.HexToAddress(request.Address)
-	signature, err := utils.DecodeHex(request.Signature)
+	signature, err := auth.DecodeSignature(request.Signature)
 	if err != nil {
-		handleInputError(w, s.logger, fmt.Errorf("invalid signature"))
+		handleError(w, s.logger, err)
 		return
 	}
 	err = s.manager.Login(request.Nonce, address, signature)
 	if err != nil {
-		if errors.Is(err, db.ErrUnregisteredNode) {
-			handleUnregisteredNode(w, s.logger, address)
-			return
-		}
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return
 	}
 
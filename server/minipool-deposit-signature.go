@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/rocket-pool/node-manager-core/utils"
+)
+
+func (s *NodeSetMockServer) minipoolDepositSignature(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Get the requesting node
+	var request api.MinipoolDepositSignatureRequest
+	args := s.processApiRequest(w, r, &request)
+	if args == nil {
+		return
+	}
+	session := s.processAuthHeader(w, r)
+	if session == nil {
+		return
+	}
+	node := s.getNodeForSession(w, session)
+	if node == nil {
+		return
+	}
+
+	// Input validation
+	if !common.IsHexAddress(request.MinipoolAddress) {
+		handleInputError(w, s.logger, fmt.Errorf("invalid minipool address"))
+		return
+	}
+	minipoolAddress := common.HexToAddress(request.MinipoolAddress)
+	saltBytes, err := utils.DecodeHex(request.Salt)
+	if err != nil {
+		handleInputError(w, s.logger, fmt.Errorf("invalid salt"))
+		return
+	}
+	salt := new(big.Int).SetBytes(saltBytes)
+
+	// Create the signature
+	deployment, _ := getNetworkAndVault(r, args)
+	signature, err := s.manager.CreateMinipoolDepositSignature(node.Address, deployment, minipoolAddress, salt)
+	if err != nil {
+		handleError(w, s.logger, err)
+		return
+	}
+
+	// Write the response
+	data := api.MinipoolDepositSignatureData{
+		Signature: utils.EncodeHexWithPrefix(signature),
+	}
+	handleSuccess(w, s.logger, data)
+	s.logger.Info("Issued minipool deposit signature", "deployment", deployment, "address", node.Address.Hex(), "minipool", minipoolAddress.Hex())
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+)
+
+// A token bucket for a single client of a rate-limited route
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// Simulates the rate limiting applied by the NodeSet service
+type rateLimiter struct {
+	// The limits for each route, keyed by route name
+	limits map[string]api.RateLimit
+
+	// The token buckets for each client, keyed by route name and then client ID
+	buckets map[string]map[string]*tokenBucket
+
+	// The names of the routes that can be rate limited
+	routes map[string]bool
+
+	lock sync.Mutex
+}
+
+// Creates a new rate limiter with no limits
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limits:  map[string]api.RateLimit{},
+		buckets: map[string]map[string]*tokenBucket{},
+		routes:  map[string]bool{},
+	}
+}
+
+// Sets the rate limit for a route, replacing the existing one if present
+func (s *NodeSetMockServer) SetRateLimit(limit api.RateLimit) error {
+	l := s.rateLimiter
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if !l.routes[limit.Route] {
+		return fmt.Errorf("route [%s] does not exist", limit.Route)
+	}
+	switch limit.Key {
+	case api.RateLimitKey_Node, api.RateLimitKey_Session, api.RateLimitKey_Ip:
+	default:
+		return fmt.Errorf("unknown rate limit key [%s]", limit.Key)
+	}
+	if limit.Rate <= 0 {
+		return fmt.Errorf("rate must be greater than 0")
+	}
+	if limit.Burst < 1 {
+		return fmt.Errorf("burst must be at least 1")
+	}
+
+	l.limits[limit.Route] = limit
+	delete(l.buckets, limit.Route)
+	return nil
+}
+
+// Removes the rate limit for a route
+func (s *NodeSetMockServer) RemoveRateLimit(route string) {
+	l := s.rateLimiter
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.limits, route)
+	delete(l.buckets, route)
+}
+
+// Gets all of the rate limits, sorted by route name
+func (s *NodeSetMockServer) GetRateLimits() []api.RateLimit {
+	l := s.rateLimiter
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	limits := make([]api.RateLimit, 0, len(l.limits))
+	for _, limit := range l.limits {
+		limits = append(limits, limit)
+	}
+	sort.Slice(limits, func(i, j int) bool {
+		return limits[i].Route < limits[j].Route
+	})
+	return limits
+}
+
+// Wraps an API route handler so it enforces the route's rate limit, if one is set
+func (s *NodeSetMockServer) limitRate(route string, handler http.HandlerFunc) http.HandlerFunc {
+	s.rateLimiter.lock.Lock()
+	s.rateLimiter.routes[route] = true
+	s.rateLimiter.lock.Unlock()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		retryAfter, allowed := s.takeToken(route, r)
+		if !allowed {
+			handleRateLimited(w, s.logger, retryAfter)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// Takes a token from the client's bucket for the route. If there aren't any left, returns false and the number of
+// seconds until the next one is available.
+func (s *NodeSetMockServer) takeToken(route string, r *http.Request) (int, bool) {
+	l := s.rateLimiter
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	limit, exists := l.limits[route]
+	if !exists {
+		return 0, true
+	}
+
+	// Get the bucket for the client
+	routeBuckets, exists := l.buckets[route]
+	if !exists {
+		routeBuckets = map[string]*tokenBucket{}
+		l.buckets[route] = routeBuckets
+	}
+	clientId := s.getRateLimitClientId(limit.Key, r)
+	now := s.manager.GetTime()
+	bucket, exists := routeBuckets[clientId]
+	if !exists {
+		bucket = &tokenBucket{
+			tokens:     float64(limit.Burst),
+			lastRefill: now,
+		}
+		routeBuckets[clientId] = bucket
+	}
+
+	// Refill it, ignoring time moving backwards
+	elapsed := math.Max(0, now.Sub(bucket.lastRefill).Seconds())
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+elapsed*limit.Rate)
+	bucket.lastRefill = now
+
+	// Take a token
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+	retryAfter := int(math.Ceil((1 - bucket.tokens) / limit.Rate))
+	return retryAfter, false
+}
+
+// Gets the ID of the client making a request, based on what the rate limit is keyed by.
+// Requests without a known session fall back to the client's IP address.
+func (s *NodeSetMockServer) getRateLimitClientId(key api.RateLimitKey, r *http.Request) string {
+	if key == api.RateLimitKey_Node || key == api.RateLimitKey_Session {
+		token, err := auth.GetSessionTokenFromRequest(r)
+		if err == nil {
+			session := s.manager.GetSessionByToken(token)
+			if session != nil {
+				if key == api.RateLimitKey_Session {
+					return session.Token
+				}
+				if session.IsLoggedIn {
+					return session.NodeAddress.Hex()
+				}
+			}
+		}
+	}
+
+	return getClientIp(r)
+}
+
+// Gets the IP address of the client making a request
+func getClientIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
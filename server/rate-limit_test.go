@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure rate limits are enforced and can be changed at runtime
+func TestRateLimit(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+	defer server.RemoveRateLimit(api.NoncePath)
+
+	// Set a limit on the nonce route
+	query := map[string]string{
+		"route": api.NoncePath,
+		"rate":  "0.01",
+		"burst": "2",
+		"key":   string(api.RateLimitKey_Ip),
+	}
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminRateLimitPath, "", query))
+	require.Equal(t, []api.RateLimit{
+		{
+			Route: api.NoncePath,
+			Key:   api.RateLimitKey_Ip,
+			Rate:  0.01,
+			Burst: 2,
+		},
+	}, server.GetRateLimits())
+	t.Log("Set rate limit")
+
+	// Use up the burst
+	for i := 0; i < 2; i++ {
+		response := runNonceRequest(t)
+		response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	}
+	t.Log("Used up the burst")
+
+	// The next request should be throttled
+	response := runNonceRequest(t)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	require.NoError(t, err)
+	require.Greater(t, retryAfter, 0)
+	bytes, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	var parsedResponse api.NodeSetResponse[struct{}]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	require.NoError(t, err)
+	require.Equal(t, rateLimitExceededKey, parsedResponse.Error)
+	t.Logf("Request was throttled, retry after %d seconds", retryAfter)
+
+	// Remove the limit
+	query = map[string]string{
+		"route": api.NoncePath,
+	}
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminRateLimitPath, "", query))
+	require.Empty(t, server.GetRateLimits())
+	response = runNonceRequest(t)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	t.Log("Request succeeded after the limit was removed")
+}
+
+// Run a nonce request and return the response
+func runNonceRequest(t *testing.T) *http.Response {
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/%s", port, api.NoncePath), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	return response
+}
@@ -1,12 +1,11 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
-	"github.com/rocket-pool/node-manager-core/utils"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
 )
 
 func (s *NodeSetMockServer) registerNode(w http.ResponseWriter, r *http.Request) {
@@ -17,10 +16,13 @@ func (s *NodeSetMockServer) registerNode(w http.ResponseWriter, r *http.Request)
 
 	// Get the requesting node
 	var request api.RegisterNodeRequest
-	_ = s.processApiRequest(w, r, &request)
+	if s.processApiRequest(w, r, &request) == nil {
+		return
+	}
 
 	// Get the node
 	address := common.HexToAddress(request.NodeAddress)
+	setAuditNodeAddress(r, address)
 	node, isRegistered := s.manager.GetNode(address)
 	if node == nil {
 		handleNodeNotInWhitelist(w, s.logger, address)
@@ -32,14 +34,14 @@ func (s *NodeSetMockServer) registerNode(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Register the node
-	sig, err := utils.DecodeHex(request.Signature)
+	sig, err := auth.DecodeSignature(request.Signature)
 	if err != nil {
-		handleInputError(w, s.logger, fmt.Errorf("invalid signature"))
+		handleError(w, s.logger, err)
 		return
 	}
 	err = s.manager.RegisterNodeAccount(request.Email, address, sig)
 	if err != nil {
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return
 	}
 	s.logger.Info("Registered new node account", "email", request.Email, "address", address.Hex())
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+func (s *NodeSetMockServer) removeWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	query := r.URL.Query()
+	idString := query.Get("id")
+	if idString == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing id query parameter"))
+		return
+	}
+	id, err := strconv.ParseUint(idString, 10, 64)
+	if err != nil {
+		handleInputError(w, s.logger, fmt.Errorf("invalid webhook ID [%s]", idString))
+		return
+	}
+
+	// Remove the webhook
+	err = s.manager.RemoveWebhook(id)
+	if err != nil {
+		handleError(w, s.logger, err)
+		return
+	}
+	handleSuccess(w, s.logger, "")
+}
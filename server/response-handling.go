@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/goccy/go-json"
@@ -23,6 +24,69 @@ const (
 
 	// The node address hasn't been whitelisted on the provided NodeSet account
 	addressMissingWhitelistKey string = "address_missing_whitelist"
+
+	// The admin token provided in the auth header is not valid
+	invalidAdminTokenKey string = "invalid_admin_token"
+
+	// The admin token provided in the auth header doesn't have access to the route
+	insufficientAdminAccessKey string = "insufficient_admin_access"
+
+	// The client has exceeded the route's rate limit
+	rateLimitExceededKey string = "rate_limit_exceeded"
+
+	// The node hasn't been whitelisted for Constellation on the deployment
+	missingConstellationWhitelistKey string = "missing_whitelist"
+
+	// The user has reached their Constellation minipool limit
+	minipoolLimitReachedKey string = "minipool_limit_reached"
+
+	// A deposit signature has already been issued for the minipool
+	minipoolAlreadyExistsKey string = "minipool_already_exists"
+
+	// The mock isn't ready to serve requests yet
+	notReadyKey string = "not_ready"
+
+	// The signature provided in the request is malformed or wasn't made by the expected address
+	invalidSignatureKey string = "invalid_signature"
+
+	// No user exists with the provided email
+	userNotFoundKey string = "user_not_found"
+
+	// A user with the provided email already exists
+	userAlreadyExistsKey string = "user_already_exists"
+
+	// The network isn't known to the server, or isn't used by the node
+	invalidNetworkKey string = "invalid_network"
+
+	// No StakeWise vault exists with the provided address
+	invalidVaultKey string = "invalid_vault"
+
+	// A StakeWise vault with the provided address already exists
+	vaultAlreadyExistsKey string = "vault_already_exists"
+
+	// The StakeWise vault doesn't have a deposit data set with the provided version
+	depositDataSetNotFoundKey string = "deposit_data_set_not_found"
+
+	// The validator pubkey couldn't be parsed
+	invalidPubkeyKey string = "invalid_pubkey"
+
+	// The deposit data's signature or withdrawal credentials are the wrong length
+	invalidDepositDataKey string = "invalid_deposit_data"
+
+	// The node doesn't have a validator with the provided pubkey
+	validatorNotFoundKey string = "validator_not_found"
+
+	// The signed exit message couldn't be decrypted or isn't well-formed
+	invalidExitMessageKey string = "invalid_exit_message"
+
+	// The signed exit message wasn't encrypted
+	unencryptedExitMessageKey string = "unencrypted_exit_message"
+
+	// No snapshot exists with the provided name
+	snapshotNotFoundKey string = "snapshot_not_found"
+
+	// No webhook exists with the provided ID
+	webhookNotFoundKey string = "webhook_not_found"
 )
 
 // Handle routes called with an invalid method
@@ -30,6 +94,11 @@ func handleInvalidMethod(w http.ResponseWriter, logger *slog.Logger) {
 	writeResponse(w, logger, http.StatusMethodNotAllowed, []byte{})
 }
 
+// Write a response telling the client the copy it already has is up to date
+func handleNotModified(w http.ResponseWriter, logger *slog.Logger) {
+	writeResponse(w, logger, http.StatusNotModified, []byte{})
+}
+
 // Handles an error related to parsing the input parameters of a request
 func handleInputError(w http.ResponseWriter, logger *slog.Logger, err error) {
 	msg := err.Error()
@@ -58,6 +127,20 @@ func handleInvalidSessionError(w http.ResponseWriter, logger *slog.Logger, err e
 	writeResponse(w, logger, http.StatusUnauthorized, bytes)
 }
 
+// Write an error if the admin token provided in the auth header is not valid
+func handleInvalidAdminToken(w http.ResponseWriter, logger *slog.Logger) {
+	msg := "Invalid admin token"
+	bytes := formatError(msg, invalidAdminTokenKey)
+	writeResponse(w, logger, http.StatusUnauthorized, bytes)
+}
+
+// Write an error if the admin token provided in the auth header is read-only but the route modifies the mock's state
+func handleAdminForbidden(w http.ResponseWriter, logger *slog.Logger) {
+	msg := "Admin token does not have write access"
+	bytes := formatError(msg, insufficientAdminAccessKey)
+	writeResponse(w, logger, http.StatusForbidden, bytes)
+}
+
 // Write an error if the node providing the request isn't registered
 func handleUnregisteredNode(w http.ResponseWriter, logger *slog.Logger, address common.Address) {
 	msg := fmt.Sprintf("No user found with authorized address %s", address.Hex())
@@ -79,7 +162,26 @@ func handleAlreadyRegisteredNode(w http.ResponseWriter, logger *slog.Logger, add
 	writeResponse(w, logger, http.StatusBadRequest, bytes)
 }
 
-// Write an error if the auth header couldn't be decoded
+// Write an error if the client has exceeded the route's rate limit
+func handleRateLimited(w http.ResponseWriter, logger *slog.Logger, retryAfter int) {
+	msg := fmt.Sprintf("Rate limit exceeded, retry after %d seconds", retryAfter)
+	bytes := formatError(msg, rateLimitExceededKey)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeResponse(w, logger, http.StatusTooManyRequests, bytes)
+}
+
+// Write an error returned by the manager, using the status code and error key of its domain error if it has one
+func handleError(w http.ResponseWriter, logger *slog.Logger, err error) {
+	statusCode, key, known := getErrorResponse(err)
+	if !known {
+		handleServerError(w, logger, err)
+		return
+	}
+	bytes := formatError(err.Error(), key)
+	writeResponse(w, logger, statusCode, bytes)
+}
+
+// Write an error for an unexpected failure on the server side
 func handleServerError(w http.ResponseWriter, logger *slog.Logger, err error) {
 	msg := err.Error()
 	bytes := formatError(msg, "")
@@ -105,6 +207,42 @@ func handleSuccess[DataType any](w http.ResponseWriter, logger *slog.Logger, dat
 	writeResponse(w, logger, http.StatusOK, bytes)
 }
 
+// Write an error from one of the Beacon API routes, in the Beacon API's format
+func handleBeaconError(w http.ResponseWriter, logger *slog.Logger, statusCode int, err error) {
+	response := beaconErrorResponse{
+		Code:    statusCode,
+		Message: err.Error(),
+	}
+	bytes, _ := json.Marshal(response)
+	writeResponse(w, logger, statusCode, bytes)
+}
+
+// A Beacon API request completed successfully
+func handleBeaconSuccess(w http.ResponseWriter, logger *slog.Logger, response any) {
+	// Serialize the response
+	bytes, err := json.Marshal(response)
+	if err != nil {
+		handleBeaconError(w, logger, http.StatusInternalServerError, fmt.Errorf("error serializing response: %w", err))
+		return
+	}
+
+	// Write it
+	logger.Debug("Response body", slog.String(log.BodyKey, string(bytes)))
+	writeResponse(w, logger, http.StatusOK, bytes)
+}
+
+// Write an error if the mock isn't ready yet, including its readiness status
+func handleNotReady(w http.ResponseWriter, logger *slog.Logger, data api.ReadyData) {
+	response := api.NodeSetResponse[api.ReadyData]{
+		OK:      false,
+		Message: fmt.Sprintf("Mock is not ready (%s)", data.Status),
+		Error:   notReadyKey,
+		Data:    data,
+	}
+	bytes, _ := json.Marshal(response)
+	writeResponse(w, logger, http.StatusServiceUnavailable, bytes)
+}
+
 // Writes a response to an HTTP request back to the client and logs it
 func writeResponse(w http.ResponseWriter, logger *slog.Logger, statusCode int, message []byte) {
 	// Prep the log attributes
@@ -116,7 +254,7 @@ func writeResponse(w http.ResponseWriter, logger *slog.Logger, statusCode int, m
 	// Log the response
 	logMsg := "Responded with:"
 	switch statusCode {
-	case http.StatusOK:
+	case http.StatusOK, http.StatusNotModified:
 		logger.Info(logMsg, attrs...)
 	case http.StatusInternalServerError:
 		logger.Error(logMsg, attrs...)
@@ -145,3 +283,9 @@ func formatError(message string, errorKey string) []byte {
 	bytes, _ := json.Marshal(msg)
 	return bytes
 }
+
+// The body of an error response from the Beacon API
+type beaconErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
@@ -19,7 +19,7 @@ func (s *NodeSetMockServer) revert(w http.ResponseWriter, r *http.Request) {
 
 	err := s.manager.RevertToSnapshot(snapshotName)
 	if err != nil {
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return
 	}
 	handleSuccess(w, s.logger, "")
@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
@@ -20,6 +22,20 @@ import (
 	"github.com/rocket-pool/node-manager-core/log"
 )
 
+const (
+	// How often the manager's cycle schedules are checked
+	schedulerPollInterval time.Duration = time.Second
+
+	// Path variable for the deployment (network) of a v2 module route
+	deploymentVar string = "deployment"
+
+	// Path variable for the vault address of a v2 StakeWise route
+	vaultVar string = "vault"
+
+	// Path variable for the state ID of a Beacon API route
+	stateVar string = "state"
+)
+
 type NodeSetMockServer struct {
 	logger  *slog.Logger
 	ip      string
@@ -28,11 +44,41 @@ type NodeSetMockServer struct {
 	server  http.Server
 	router  *mux.Router
 	manager *manager.NodeSetMockManager
+
+	// Simulated rate limits
+	rateLimiter *rateLimiter
+
+	// Enabled API versions
+	apiVersions     map[string]bool
+	apiVersionsLock sync.RWMutex
+
+	// Simulated Beacon API
+	beaconApiEnabled atomic.Bool
+
+	// How far the mock is through getting ready, reported by the readiness route
+	readinessStatus api.ReadinessStatus
+	readinessLock   sync.RWMutex
+
+	// Closed when the server stops, to end long-lived requests like event streams
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+
+	// Admin API
+	adminRouter         *mux.Router
+	adminIp             string
+	adminPort           uint16
+	adminSocket         net.Listener
+	adminServer         http.Server
+	useAdminListener    bool
+	adminReadOnlyToken  string
+	adminReadWriteToken string
+	adminTokensLock     sync.RWMutex
 }
 
 func NewNodeSetMockServer(logger *slog.Logger, ip string, port uint16) (*NodeSetMockServer, error) {
-	// Create the router
+	// Create the routers
 	router := mux.NewRouter()
+	adminRouter := mux.NewRouter()
 
 	// Create the manager
 	server := &NodeSetMockServer{
@@ -43,29 +89,158 @@ func NewNodeSetMockServer(logger *slog.Logger, ip string, port uint16) (*NodeSet
 		server: http.Server{
 			Handler: router,
 		},
-		manager: manager.NewNodeSetMockManager(logger),
+		manager:     manager.NewNodeSetMockManager(logger),
+		rateLimiter: newRateLimiter(),
+		apiVersions: map[string]bool{
+			api.V1: true,
+			api.V2: true,
+		},
+		adminRouter: adminRouter,
+		adminServer: http.Server{
+			Handler: adminRouter,
+		},
+		readinessStatus: api.ReadinessStatus_Ready,
+		shutdown:        make(chan struct{}),
 	}
 
 	// Register each route
 	apiRouter := router.PathPrefix("/api").Subrouter()
-	server.registerApiRoutes(apiRouter)
-	adminRouter := router.PathPrefix("/admin").Subrouter()
-	server.registerAdminRoutes(adminRouter)
+	v2Router := apiRouter.PathPrefix("/" + api.V2Path).Subrouter()
+	v2Router.Use(server.requireApiVersion(api.V2))
+	v1Router := apiRouter.NewRoute().Subrouter()
+	v1Router.Use(server.requireApiVersion(api.V1))
+	apiRouter.Use(server.lockManager)
+	server.registerApiRoutes(v1Router, v2Router)
+	adminRouter.HandleFunc("/admin/"+api.AdminEventsPath, server.requireAdminAccess(adminAccess_ReadOnly, server.streamEvents)) // Streams don't hold the manager lock
+	adminSubrouter := adminRouter.PathPrefix("/admin").Subrouter()
+	adminSubrouter.Use(server.lockManager)
+	server.registerAdminRoutes(adminSubrouter)
+	beaconRouter := router.PathPrefix("/eth").Subrouter()
+	beaconRouter.Use(server.requireBeaconApi, server.lockManager)
+	server.registerBeaconRoutes(beaconRouter)
+
+	// Service routes, which don't need the manager
+	router.HandleFunc("/"+api.HealthPath, server.getHealth)
+	router.HandleFunc("/"+api.ReadyPath, server.getReady)
+	router.HandleFunc("/"+api.VersionPath, server.getVersion)
+
+	// Serve the admin routes from the main router unless they get their own listener
+	router.PathPrefix("/admin").HandlerFunc(server.serveSharedAdminRoutes)
 	return server, nil
 }
 
-// Starts listening for incoming HTTP requests
+// Enables or disables one of the API versions (api.V1 or api.V2). Requests to routes of a disabled version
+// return 404, so clients can be tested against a service that only supports one of them.
+func (s *NodeSetMockServer) SetApiVersionEnabled(version string, enabled bool) error {
+	s.apiVersionsLock.Lock()
+	defer s.apiVersionsLock.Unlock()
+
+	if _, exists := s.apiVersions[version]; !exists {
+		return fmt.Errorf("unknown API version [%s]", version)
+	}
+	s.apiVersions[version] = enabled
+	return nil
+}
+
+// Checks if one of the API versions is enabled
+func (s *NodeSetMockServer) IsApiVersionEnabled(version string) bool {
+	s.apiVersionsLock.RLock()
+	defer s.apiVersionsLock.RUnlock()
+
+	return s.apiVersions[version]
+}
+
+// Enables or disables the simulated Beacon API, which is served under /eth alongside the NodeSet API routes.
+// It's disabled by default.
+func (s *NodeSetMockServer) SetBeaconApiEnabled(enabled bool) {
+	s.beaconApiEnabled.Store(enabled)
+}
+
+// Sets how far the mock is through getting ready, which is reported by the readiness route. The mock is ready by
+// default; set this to api.ReadinessStatus_Loading or api.ReadinessStatus_Seeding while its state is being prepared
+// so orchestrators wait for it.
+func (s *NodeSetMockServer) SetReadinessStatus(status api.ReadinessStatus) {
+	s.readinessLock.Lock()
+	defer s.readinessLock.Unlock()
+	s.readinessStatus = status
+}
+
+// Gets how far the mock is through getting ready
+func (s *NodeSetMockServer) GetReadinessStatus() api.ReadinessStatus {
+	s.readinessLock.RLock()
+	defer s.readinessLock.RUnlock()
+	return s.readinessStatus
+}
+
+// Serve the admin routes on a separate IP address and port instead of alongside the API routes.
+// Must be called before Start().
+func (s *NodeSetMockServer) SetAdminListener(ip string, port uint16) {
+	s.adminIp = ip
+	s.adminPort = port
+	s.useAdminListener = true
+}
+
+// Serve the admin routes on the provided listener, such as a Unix domain socket, instead of alongside the API routes.
+// Must be called before Start(). The server closes the listener when it stops.
+func (s *NodeSetMockServer) SetAdminNetListener(listener net.Listener) {
+	s.adminSocket = listener
+	s.useAdminListener = true
+}
+
+// Set the tokens required to access the admin routes. The read-only token grants access to routes that don't modify
+// the mock's state, and the read-write token grants access to all of them. Leaving both empty disables admin
+// authentication. Setting only the read-only token leaves the routes that modify the mock's state unreachable.
+func (s *NodeSetMockServer) SetAdminTokens(readOnlyToken string, readWriteToken string) {
+	s.adminTokensLock.Lock()
+	defer s.adminTokensLock.Unlock()
+	s.adminReadOnlyToken = readOnlyToken
+	s.adminReadWriteToken = readWriteToken
+
+	if readOnlyToken != "" && readWriteToken == "" {
+		s.logger.Warn("Only a read-only admin token is set, so the admin routes that modify the mock's state are unreachable")
+	}
+}
+
+// Gets the tokens required to access the admin routes
+func (s *NodeSetMockServer) getAdminTokens() (string, string) {
+	s.adminTokensLock.RLock()
+	defer s.adminTokensLock.RUnlock()
+	return s.adminReadOnlyToken, s.adminReadWriteToken
+}
+
+// Starts listening for incoming HTTP requests on the IP address and port the server was created with
 func (s *NodeSetMockServer) Start(wg *sync.WaitGroup) error {
 	// Create the socket
 	socket, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.ip, s.port))
 	if err != nil {
 		return fmt.Errorf("error creating socket: %w", err)
 	}
+	return s.StartWithListener(wg, socket)
+}
+
+// Starts serving incoming HTTP requests on the provided listener, such as a Unix domain socket, instead of creating
+// one. The server closes the listener when it stops.
+func (s *NodeSetMockServer) StartWithListener(wg *sync.WaitGroup, socket net.Listener) error {
 	s.socket = socket
 
-	// Get the port if random
-	if s.port == 0 {
-		s.port = uint16(socket.Addr().(*net.TCPAddr).Port)
+	// Get the port if it's a TCP listener
+	if tcpAddr, isTcp := socket.Addr().(*net.TCPAddr); isTcp {
+		s.port = uint16(tcpAddr.Port)
+	}
+
+	// Create the admin socket if requested and one wasn't provided
+	if s.useAdminListener && s.adminSocket == nil {
+		adminSocket, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.adminIp, s.adminPort))
+		if err != nil {
+			socket.Close()
+			return fmt.Errorf("error creating admin socket: %w", err)
+		}
+		s.adminSocket = adminSocket
+	}
+	if s.adminSocket != nil {
+		if tcpAddr, isTcp := s.adminSocket.Addr().(*net.TCPAddr); isTcp {
+			s.adminPort = uint16(tcpAddr.Port)
+		}
 	}
 
 	// Start listening
@@ -77,98 +252,268 @@ func (s *NodeSetMockServer) Start(wg *sync.WaitGroup) error {
 		}
 		wg.Done()
 	}()
+	s.manager.StartScheduler(schedulerPollInterval)
+	if s.adminSocket != nil {
+		wg.Add(1)
+		go func() {
+			err := s.adminServer.Serve(s.adminSocket)
+			if !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("error while listening for admin HTTP requests", log.Err(err))
+			}
+			wg.Done()
+		}()
+	}
 
 	return nil
 }
 
 // Stops the HTTP listener
 func (s *NodeSetMockServer) Stop() error {
+	s.manager.StopScheduler()
+	s.manager.StopWebhooks()
+	s.shutdownOnce.Do(func() {
+		close(s.shutdown)
+	})
 	err := s.server.Shutdown(context.Background())
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("error stopping listener: %w", err)
 	}
+	if s.adminSocket != nil {
+		err = s.adminServer.Shutdown(context.Background())
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("error stopping admin listener: %w", err)
+		}
+	}
 	return nil
 }
 
-// Get the port the server is listening on
+// Get the port the server is listening on, or 0 if it isn't listening on a TCP port
 func (s *NodeSetMockServer) GetPort() uint16 {
 	return s.port
 }
 
+// Get the port the admin routes are served on. This is the same as the API port unless SetAdminListener() was used.
+func (s *NodeSetMockServer) GetAdminPort() uint16 {
+	if s.useAdminListener {
+		return s.adminPort
+	}
+	return s.port
+}
+
+// Serves a request with the mock's routes, so the mock can be mounted in an httptest.Server or combined with other
+// handlers on one listener instead of calling Start(). The admin routes are included unless they were given their
+// own listener. The cycle scheduler only runs after Start(), but it can be started through the manager instead.
+func (s *NodeSetMockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// Get a handler for just the admin routes, for mounting them separately from the API routes. The server still serves
+// them alongside the API routes too unless they were given their own listener.
+func (s *NodeSetMockServer) GetAdminHandler() http.Handler {
+	return s.adminRouter
+}
+
 // Get the mock manager for direct access
 func (s *NodeSetMockServer) GetManager() *manager.NodeSetMockManager {
 	return s.manager
 }
 
-// API routes
-func (s *NodeSetMockServer) registerApiRoutes(apiRouter *mux.Router) {
+// API routes. v1 routes carry the network and vault in query args, v2 routes carry them in the path.
+func (s *NodeSetMockServer) registerApiRoutes(v1Router *mux.Router, v2Router *mux.Router) {
+	coreRouter := v2Router.PathPrefix("/" + api.CorePath).Subrouter()
+	stakeWisePath := fmt.Sprintf("/%s/%s/{%s}/{%s}", api.ModulesPath, api.StakeWisePath, deploymentVar, vaultVar)
+	stakeWiseRouter := v2Router.PathPrefix(stakeWisePath).Subrouter()
+	constellationPath := fmt.Sprintf("/%s/%s/{%s}", api.ModulesPath, api.ConstellationPath, deploymentVar)
+	constellationRouter := v2Router.PathPrefix(constellationPath).Subrouter()
+
 	// deposit-data/meta
-	depositDataMeta := func(w http.ResponseWriter, r *http.Request) {
+	depositDataMeta := s.limitRate(api.DepositDataMetaPath, func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			s.depositDataMeta(w, r)
 		default:
 			handleInvalidMethod(w, s.logger)
 		}
-	}
-	apiRouter.HandleFunc("/"+api.DepositDataMetaPath, depositDataMeta)
-	apiRouter.HandleFunc("/"+api.DevPath+"/"+api.DepositDataMetaPath, depositDataMeta)
+	})
+	v1Router.HandleFunc("/"+api.DepositDataMetaPath, depositDataMeta)
+	v1Router.HandleFunc("/"+api.DevPath+"/"+api.DepositDataMetaPath, depositDataMeta)
+	stakeWiseRouter.HandleFunc("/"+api.DepositDataMetaPath, depositDataMeta)
+
+	// deposit-data/versions
+	depositDataVersions := s.limitRate(api.DepositDataVersionsPath, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			s.depositDataVersions(w, r)
+		default:
+			handleInvalidMethod(w, s.logger)
+		}
+	})
+	v1Router.HandleFunc("/"+api.DepositDataVersionsPath, depositDataVersions)
+	v1Router.HandleFunc("/"+api.DevPath+"/"+api.DepositDataVersionsPath, depositDataVersions)
+	stakeWiseRouter.HandleFunc("/"+api.DepositDataVersionsPath, depositDataVersions)
 
 	// deposit-data
-	depositData := func(w http.ResponseWriter, r *http.Request) {
+	uploadDepositData := s.audit(db.AuditActor_Node, s.uploadDepositData)
+	depositData := s.limitRate(api.DepositDataPath, func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			s.getDepositData(w, r)
 		case http.MethodPost:
-			s.uploadDepositData(w, r)
+			uploadDepositData(w, r)
 		default:
 			handleInvalidMethod(w, s.logger)
 		}
-	}
-	apiRouter.HandleFunc("/"+api.DepositDataPath, depositData)
-	apiRouter.HandleFunc("/"+api.DevPath+"/"+api.DepositDataPath, depositData)
+	})
+	v1Router.HandleFunc("/"+api.DepositDataPath, depositData)
+	v1Router.HandleFunc("/"+api.DevPath+"/"+api.DepositDataPath, depositData)
+	stakeWiseRouter.HandleFunc("/"+api.DepositDataPath, depositData)
 
 	// validators
-	validators := func(w http.ResponseWriter, r *http.Request) {
+	uploadSignedExits := s.audit(db.AuditActor_Node, s.uploadSignedExits)
+	validators := s.limitRate(api.ValidatorsPath, func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			s.getValidators(w, r)
 		case http.MethodPatch:
-			s.uploadSignedExits(w, r)
+			uploadSignedExits(w, r)
 		default:
 			handleInvalidMethod(w, s.logger)
 		}
-	}
-	apiRouter.HandleFunc("/"+api.ValidatorsPath, validators)
-	apiRouter.HandleFunc("/"+api.DevPath+"/"+api.ValidatorsPath, validators)
+	})
+	v1Router.HandleFunc("/"+api.ValidatorsPath, validators)
+	v1Router.HandleFunc("/"+api.DevPath+"/"+api.ValidatorsPath, validators)
+	stakeWiseRouter.HandleFunc("/"+api.ValidatorsPath, validators)
 
 	// node-address
-	apiRouter.HandleFunc("/"+api.RegisterPath, s.registerNode)
-	apiRouter.HandleFunc("/"+api.DevPath+"/"+api.RegisterPath, s.registerNode)
+	registerNode := s.limitRate(api.RegisterPath, s.audit(db.AuditActor_Node, s.registerNode))
+	v1Router.HandleFunc("/"+api.RegisterPath, registerNode)
+	v1Router.HandleFunc("/"+api.DevPath+"/"+api.RegisterPath, registerNode)
+	coreRouter.HandleFunc("/"+api.RegisterPath, registerNode)
 
 	// nonce
-	apiRouter.HandleFunc("/"+api.NoncePath, s.getNonce)
-	apiRouter.HandleFunc("/"+api.DevPath+"/"+api.NoncePath, s.getNonce)
+	getNonce := s.limitRate(api.NoncePath, s.getNonce)
+	v1Router.HandleFunc("/"+api.NoncePath, getNonce)
+	v1Router.HandleFunc("/"+api.DevPath+"/"+api.NoncePath, getNonce)
+	coreRouter.HandleFunc("/"+api.NoncePath, getNonce)
 
 	// login
-	apiRouter.HandleFunc("/"+api.LoginPath, s.login)
-	apiRouter.HandleFunc("/"+api.DevPath+"/"+api.LoginPath, s.login)
+	login := s.limitRate(api.LoginPath, s.audit(db.AuditActor_Node, s.login))
+	v1Router.HandleFunc("/"+api.LoginPath, login)
+	v1Router.HandleFunc("/"+api.DevPath+"/"+api.LoginPath, login)
+	coreRouter.HandleFunc("/"+api.LoginPath, login)
+
+	// secret/encryption-identity
+	getEncryptionIdentity := s.limitRate(api.EncryptionIdentityPath, s.getEncryptionIdentity)
+	v1Router.HandleFunc("/"+api.EncryptionIdentityPath, getEncryptionIdentity)
+	v1Router.HandleFunc("/"+api.DevPath+"/"+api.EncryptionIdentityPath, getEncryptionIdentity)
+	coreRouter.HandleFunc("/"+api.EncryptionIdentityPath, getEncryptionIdentity)
+
+	// Constellation (v2 only)
+	constellationRouter.HandleFunc("/"+api.WhitelistPath, s.limitRate(api.WhitelistPath, s.audit(db.AuditActor_Node, s.constellationWhitelist)))
+	constellationRouter.HandleFunc("/"+api.MinipoolDepositSignaturePath, s.limitRate(api.MinipoolDepositSignaturePath, s.audit(db.AuditActor_Node, s.minipoolDepositSignature)))
 }
 
 // Admin routes
 func (s *NodeSetMockServer) registerAdminRoutes(adminRouter *mux.Router) {
-	adminRouter.HandleFunc("/"+api.AdminSnapshotPath, s.snapshot)
-	adminRouter.HandleFunc("/"+api.AdminRevertPath, s.revert)
-	adminRouter.HandleFunc("/"+api.AdminCycleSetPath, s.cycleSet)
-	adminRouter.HandleFunc("/"+api.AdminAddUserPath, s.addUser)
-	adminRouter.HandleFunc("/"+api.AdminWhitelistNodePath, s.whitelistNode)
-	adminRouter.HandleFunc("/"+api.AdminAddVaultPath, s.addStakeWiseVault)
+	// Read-only routes
+	adminRouter.HandleFunc("/"+api.AdminSnapshotsPath, s.requireAdminAccess(adminAccess_ReadOnly, s.getSnapshots))
+	adminRouter.HandleFunc("/"+api.AdminRateLimitsPath, s.requireAdminAccess(adminAccess_ReadOnly, s.getRateLimits))
+	adminRouter.HandleFunc("/"+api.AdminCycleSchedulesPath, s.requireAdminAccess(adminAccess_ReadOnly, s.getCycleSchedules))
+	adminRouter.HandleFunc("/"+api.AdminTimePath, s.requireAdminAccess(adminAccess_ReadOnly, s.getTime))
+	adminRouter.HandleFunc("/"+api.AdminAuditLogPath, s.requireAdminAccess(adminAccess_ReadOnly, s.getAuditLog))
+	adminRouter.HandleFunc("/"+api.AdminStatePath, s.requireAdminAccess(adminAccess_ReadOnly, s.getState))
+	adminRouter.HandleFunc("/"+api.AdminWebhooksPath, s.requireAdminAccess(adminAccess_ReadOnly, s.getWebhooks))
+	adminRouter.HandleFunc("/"+api.AdminSessionPolicyPath, s.requireAdminAccess(adminAccess_ReadOnly, s.getSessionPolicy))
+
+	// Read-write routes, which are recorded in the audit log
+	adminRouter.HandleFunc("/"+api.AdminSnapshotPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.snapshot)))
+	adminRouter.HandleFunc("/"+api.AdminRevertPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.revert)))
+	adminRouter.HandleFunc("/"+api.AdminCycleSetPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.cycleSet)))
+	adminRouter.HandleFunc("/"+api.AdminAddUserPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.addUser)))
+	adminRouter.HandleFunc("/"+api.AdminWhitelistNodePath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.whitelistNode)))
+	adminRouter.HandleFunc("/"+api.AdminAddVaultPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.addStakeWiseVault)))
+	adminRouter.HandleFunc("/"+api.AdminRateLimitPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.setRateLimit)))
+	adminRouter.HandleFunc("/"+api.AdminApiVersionPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.setApiVersion)))
+	adminRouter.HandleFunc("/"+api.AdminMinipoolLimitPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.setMinipoolLimit)))
+	adminRouter.HandleFunc("/"+api.AdminCycleSchedulePath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.setCycleSchedule)))
+	adminRouter.HandleFunc("/"+api.AdminFreezeTimePath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.freezeTime)))
+	adminRouter.HandleFunc("/"+api.AdminSetTimePath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.setTime)))
+	adminRouter.HandleFunc("/"+api.AdminSetReadyPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.setReady)))
+	adminRouter.HandleFunc("/"+api.AdminAdvanceTimePath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.advanceTime)))
+	adminRouter.HandleFunc("/"+api.AdminAddWebhookPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.addWebhook)))
+	adminRouter.HandleFunc("/"+api.AdminRemoveWebhookPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.removeWebhook)))
+	adminRouter.HandleFunc("/"+api.AdminSetSessionPolicyPath, s.audit(db.AuditActor_Admin, s.requireAdminAccess(adminAccess_ReadWrite, s.setSessionPolicy)))
+}
+
+// Beacon API routes
+func (s *NodeSetMockServer) registerBeaconRoutes(beaconRouter *mux.Router) {
+	beaconRouter.HandleFunc("/v1/beacon/genesis", s.getBeaconGenesis)
+	beaconRouter.HandleFunc(fmt.Sprintf("/v1/beacon/states/{%s}/validators", stateVar), s.getBeaconValidators)
+	beaconRouter.HandleFunc("/v1/beacon/pool/voluntary_exits", s.submitVoluntaryExit)
+	beaconRouter.HandleFunc("/v1/node/syncing", s.getBeaconSyncStatus)
+}
+
+// Serves the admin routes from the main router if they don't have their own listener
+func (s *NodeSetMockServer) serveSharedAdminRoutes(w http.ResponseWriter, r *http.Request) {
+	if s.useAdminListener {
+		http.NotFound(w, r)
+		return
+	}
+	s.adminRouter.ServeHTTP(w, r)
 }
 
 // =============
 // === Utils ===
 // =============
 
+// Middleware that holds the manager's lock while a request is handled
+func (s *NodeSetMockServer) lockManager(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.manager.Lock()
+		defer s.manager.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Middleware that returns 404 for requests to an API version that has been disabled
+func (s *NodeSetMockServer) requireApiVersion(version string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.IsApiVersionEnabled(version) {
+				http.NotFound(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Middleware that returns 404 for Beacon API requests unless it has been enabled
+func (s *NodeSetMockServer) requireBeaconApi(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.beaconApiEnabled.Load() {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Gets the network and vault address for a StakeWise request. v2 routes carry them in the path, and v1 routes carry
+// them in the query args.
+func getNetworkAndVault(r *http.Request, args url.Values) (string, string) {
+	vars := mux.Vars(r)
+	network, exists := vars[deploymentVar]
+	if !exists {
+		network = args.Get("network")
+	}
+	vault, exists := vars[vaultVar]
+	if !exists {
+		vault = args.Get("vault")
+	}
+	return network, vault
+}
+
 func (s *NodeSetMockServer) processApiRequest(w http.ResponseWriter, r *http.Request, requestBody any) url.Values {
 	args := r.URL.Query()
 	s.logger.Info("New request", slog.String(log.MethodKey, r.Method), slog.String(log.PathKey, r.URL.Path))
@@ -212,7 +557,7 @@ func (s *NodeSetMockServer) processAuthHeader(w http.ResponseWriter, r *http.Req
 		}
 
 		// Catch-all
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return nil
 	}
 
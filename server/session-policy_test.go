@@ -0,0 +1,189 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+	"github.com/rocket-pool/node-manager-core/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure nonces are bound, single-use, limited, and expire according to the session policy
+func TestSessionPolicy(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		server.manager.ResetTime()
+		_ = server.manager.SetSessionPolicy(manager.SessionPolicy{})
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	node0Key, err := test.GetEthPrivateKey(0)
+	require.NoError(t, err)
+	node1Key, err := test.GetEthPrivateKey(1)
+	require.NoError(t, err)
+	node1Address := crypto.PubkeyToAddress(node1Key.PublicKey)
+
+	// Set the policy
+	var policy api.NodeSetResponse[api.SessionPolicyData]
+	runAdminDataRequest(t, api.AdminSetSessionPolicyPath, map[string]string{
+		"nonce-lifetime": "1m",
+		"max-nonces":     "2",
+	}, &policy)
+	require.Equal(t, api.SessionPolicyData{NonceLifetime: "1m0s", MaxNoncesPerClient: 2}, policy.Data)
+	t.Log("Set session policy")
+
+	// A nonce bound to node 1 can't be used by node 0
+	code, nonce := runGetNonceRequest(t, node1Address)
+	require.Equal(t, http.StatusOK, code)
+	code, response := runLoginRequest(t, nonce, node0Key)
+	require.Equal(t, http.StatusUnauthorized, code)
+	require.Equal(t, invalidSessionKey, response.Error)
+	t.Log("Bound nonce was rejected for another node")
+
+	// The limit on outstanding nonces is enforced
+	code, _ = runGetNonceRequest(t, common.Address{})
+	require.Equal(t, http.StatusOK, code)
+	code, _ = runGetNonceRequest(t, common.Address{})
+	require.Equal(t, http.StatusTooManyRequests, code)
+	t.Log("Outstanding nonce limit was enforced")
+
+	// Node 1 can log in with its nonce, but only once
+	code, response = runLoginRequest(t, nonce, node1Key)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, nonce.Token, response.Data.Token)
+	code, response = runLoginRequest(t, nonce, node1Key)
+	require.Equal(t, http.StatusUnauthorized, code)
+	require.Equal(t, invalidSessionKey, response.Error)
+	t.Log("Nonce could only be used once")
+
+	// Nonces expire
+	server.manager.FreezeTime()
+	code, nonce = runGetNonceRequest(t, common.Address{})
+	require.Equal(t, http.StatusOK, code)
+	require.NoError(t, server.manager.AdvanceTime(time.Minute))
+	code, response = runLoginRequest(t, nonce, node0Key)
+	require.Equal(t, http.StatusUnauthorized, code)
+	require.Equal(t, invalidSessionKey, response.Error)
+	t.Log("Expired nonce was rejected")
+
+	// Expired nonces don't count towards the limit
+	code, _ = runGetNonceRequest(t, common.Address{})
+	require.Equal(t, http.StatusOK, code)
+	code, _ = runGetNonceRequest(t, common.Address{})
+	require.Equal(t, http.StatusOK, code)
+	t.Log("Expired nonces were pruned")
+}
+
+// Make sure logging in revokes a node's older sessions if the policy says to
+func TestRevokeOldSessions(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		_ = server.manager.SetSessionPolicy(manager.SessionPolicy{})
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	node0Key, err := test.GetEthPrivateKey(0)
+	require.NoError(t, err)
+
+	// Log in without revocation
+	_, first := runGetNonceRequest(t, common.Address{})
+	code, _ := runLoginRequest(t, first, node0Key)
+	require.Equal(t, http.StatusOK, code)
+	_, second := runGetNonceRequest(t, common.Address{})
+	code, _ = runLoginRequest(t, second, node0Key)
+	require.Equal(t, http.StatusOK, code)
+	require.NotNil(t, server.manager.GetSessionByToken(first.Token))
+	t.Log("Old session was kept without revocation")
+
+	// Log in with revocation
+	runAdminDataRequest(t, api.AdminSetSessionPolicyPath, map[string]string{
+		"revoke-old-sessions": "true",
+	}, &api.NodeSetResponse[api.SessionPolicyData]{})
+	_, third := runGetNonceRequest(t, common.Address{})
+	code, _ = runLoginRequest(t, third, node0Key)
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, server.manager.GetSessionByToken(first.Token))
+	require.Nil(t, server.manager.GetSessionByToken(second.Token))
+	require.NotNil(t, server.manager.GetSessionByToken(third.Token))
+
+	// Other nodes' sessions are kept
+	require.NotNil(t, server.manager.GetSessionByToken(database.Sessions[0].Token))
+	t.Log("Old sessions were revoked")
+
+	// The revoked session is rejected
+	code, parsedResponse := runErrorRequest(t, http.MethodGet, api.ValidatorsPath, &db.Session{Token: first.Token}, map[string]string{"network": test.Network}, nil)
+	require.Equal(t, http.StatusUnauthorized, code)
+	require.Equal(t, invalidSessionKey, parsedResponse.Error)
+}
+
+// Run a nonce request, optionally binding it to a node address, and return the status code and nonce
+func runGetNonceRequest(t *testing.T, address common.Address) (int, api.NonceData) {
+	path := fmt.Sprintf("http://localhost:%d/api/%s", port, api.NoncePath)
+	if address != (common.Address{}) {
+		path += "?address=" + address.Hex()
+	}
+	response, err := http.Get(path)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	bytes, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	var parsedResponse api.NodeSetResponse[api.NonceData]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	require.NoError(t, err)
+	return response.StatusCode, parsedResponse.Data
+}
+
+// Run a login request for a nonce and return the status code and parsed response
+func runLoginRequest(t *testing.T, nonce api.NonceData, key *ecdsa.PrivateKey) (int, api.NodeSetResponse[api.LoginData]) {
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	signature, err := auth.GetSignatureForLogin(nonce.Nonce, address, key)
+	require.NoError(t, err)
+	body, err := json.Marshal(api.LoginRequest{
+		Nonce:     nonce.Nonce,
+		Address:   address.Hex(),
+		Signature: utils.EncodeHexWithPrefix(signature),
+	})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/api/%s", port, api.LoginPath), bytes.NewReader(body))
+	require.NoError(t, err)
+	auth.AddSessionTokenAuthorizationHeader(request, nonce.Token)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	var parsedResponse api.NodeSetResponse[api.LoginData]
+	err = json.Unmarshal(responseBody, &parsedResponse)
+	require.NoError(t, err)
+	return response.StatusCode, parsedResponse
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+func (s *NodeSetMockServer) setApiVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	query := r.URL.Query()
+	version := query.Get("version")
+	if version == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing version query parameter"))
+		return
+	}
+	enabledString := query.Get("enabled")
+	if enabledString == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing enabled query parameter"))
+		return
+	}
+	enabled, err := strconv.ParseBool(enabledString)
+	if err != nil {
+		handleInputError(w, s.logger, fmt.Errorf("error parsing enabled: %w", err))
+		return
+	}
+
+	// Toggle the version
+	err = s.SetApiVersionEnabled(version, enabled)
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	s.logger.Info("Set API version status", "version", version, "enabled", enabled)
+	handleSuccess(w, s.logger, "")
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+)
+
+func (s *NodeSetMockServer) setCycleSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	query := r.URL.Query()
+	networkName := query.Get("network")
+	if networkName == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing network query parameter"))
+		return
+	}
+	vaultAddressString := query.Get("vault")
+	if vaultAddressString == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing vault query parameter"))
+		return
+	}
+	vaultAddress := common.HexToAddress(vaultAddressString)
+
+	// Clear the schedule if requested
+	if query.Get("clear") == "true" {
+		s.manager.ClearCycleSchedule(vaultAddress, networkName)
+		s.logger.Info("Cleared cycle schedule", "network", networkName, "vault", vaultAddress.Hex())
+		handleSuccess(w, s.logger, "")
+		return
+	}
+
+	// Set the schedule
+	schedule, err := parseCycleSchedule(query)
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	err = s.manager.SetCycleSchedule(vaultAddress, networkName, schedule)
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	s.logger.Info("Set cycle schedule", "network", networkName, "vault", vaultAddress.Hex(), "interval", schedule.Interval, "pendingThreshold", schedule.PendingThreshold)
+	handleSuccess(w, s.logger, getCycleScheduleData(schedule))
+}
+
+// Parses a cycle schedule from the query args
+func parseCycleSchedule(query url.Values) (manager.CycleSchedule, error) {
+	var schedule manager.CycleSchedule
+	var err error
+	schedule.Interval, err = getOptionalDuration(query, "interval")
+	if err != nil {
+		return schedule, err
+	}
+	schedule.PendingThreshold, err = getOptionalLimit(query, "pending-threshold")
+	if err != nil {
+		return schedule, err
+	}
+	schedule.Policy = query.Get("policy")
+	schedule.Params.ValidatorsPerUser, err = getOptionalLimit(query, "user-limit")
+	if err != nil {
+		return schedule, err
+	}
+	schedule.Params.ValidatorsPerNode, err = getOptionalLimit(query, "node-limit")
+	if err != nil {
+		return schedule, err
+	}
+	schedule.Params.MaxSetSize, err = getOptionalLimit(query, "max-set-size")
+	if err != nil {
+		return schedule, err
+	}
+	schedule.RegisterDelay, err = getOptionalDuration(query, "register-delay")
+	if err != nil {
+		return schedule, err
+	}
+	return schedule, nil
+}
+
+// Converts a cycle schedule into its API form
+func getCycleScheduleData(schedule manager.CycleSchedule) api.CycleSchedule {
+	return api.CycleSchedule{
+		Interval:         schedule.Interval.String(),
+		PendingThreshold: schedule.PendingThreshold,
+		Policy:           schedule.Policy,
+		UserLimit:        schedule.Params.ValidatorsPerUser,
+		NodeLimit:        schedule.Params.ValidatorsPerNode,
+		MaxSetSize:       schedule.Params.MaxSetSize,
+		RegisterDelay:    schedule.RegisterDelay.String(),
+	}
+}
+
+// Parses an optional duration (e.g. "30s") from the query args, returning 0 if it's missing
+func getOptionalDuration(query url.Values, name string) (time.Duration, error) {
+	durationString := query.Get(name)
+	if durationString == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(durationString)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s: %w", name, err)
+	}
+	return duration, nil
+}
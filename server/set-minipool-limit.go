@@ -0,0 +1,41 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+func (s *NodeSetMockServer) setMinipoolLimit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	query := r.URL.Query()
+	email := query.Get("email")
+	if email == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing email query parameter"))
+		return
+	}
+	limitString := query.Get("limit")
+	if limitString == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing limit query parameter"))
+		return
+	}
+	limit, err := strconv.ParseInt(limitString, 10, 32)
+	if err != nil {
+		handleInputError(w, s.logger, fmt.Errorf("error parsing limit: %w", err))
+		return
+	}
+
+	// Set the limit
+	err = s.manager.SetMinipoolLimit(email, int(limit))
+	if err != nil {
+		handleError(w, s.logger, err)
+		return
+	}
+	s.logger.Info("Set minipool limit", "email", email, "limit", limit)
+	handleSuccess(w, s.logger, "")
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+func (s *NodeSetMockServer) setRateLimit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	query := r.URL.Query()
+	route := query.Get("route")
+	if route == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing route query parameter"))
+		return
+	}
+	rate := float64(0)
+	rateString := query.Get("rate")
+	if rateString != "" {
+		var err error
+		rate, err = strconv.ParseFloat(rateString, 64)
+		if err != nil {
+			handleInputError(w, s.logger, fmt.Errorf("error parsing rate: %w", err))
+			return
+		}
+	}
+
+	// A missing or zero rate removes the limit
+	if rate == 0 {
+		s.RemoveRateLimit(route)
+		s.logger.Info("Removed rate limit", "route", route)
+		handleSuccess(w, s.logger, api.RateLimitsData{
+			Limits: s.GetRateLimits(),
+		})
+		return
+	}
+
+	// Burst defaults to 1 second's worth of requests
+	burst := int(math.Max(1, math.Ceil(rate)))
+	burstString := query.Get("burst")
+	if burstString != "" {
+		parsedBurst, err := strconv.ParseInt(burstString, 10, 32)
+		if err != nil {
+			handleInputError(w, s.logger, fmt.Errorf("error parsing burst: %w", err))
+			return
+		}
+		burst = int(parsedBurst)
+	}
+
+	// Key defaults to the node address
+	key := api.RateLimitKey_Node
+	keyString := query.Get("key")
+	if keyString != "" {
+		key = api.RateLimitKey(keyString)
+	}
+
+	// Set the limit
+	err := s.SetRateLimit(api.RateLimit{
+		Route: route,
+		Key:   key,
+		Rate:  rate,
+		Burst: burst,
+	})
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	s.logger.Info("Set rate limit", "route", route, "key", key, "rate", rate, "burst", burst)
+	handleSuccess(w, s.logger, api.RateLimitsData{
+		Limits: s.GetRateLimits(),
+	})
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+func (s *NodeSetMockServer) setReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	ready := true
+	readyString := r.URL.Query().Get("ready")
+	if readyString != "" {
+		var err error
+		ready, err = strconv.ParseBool(readyString)
+		if err != nil {
+			handleInputError(w, s.logger, fmt.Errorf("invalid ready value [%s]", readyString))
+			return
+		}
+	}
+
+	// Mark the seeding as finished, or go back to waiting for it
+	status := api.ReadinessStatus_Ready
+	if !ready {
+		status = api.ReadinessStatus_Seeding
+	}
+	s.SetReadinessStatus(status)
+	s.logger.Info("Set readiness status", "status", status)
+	handleSuccess(w, s.logger, api.ReadyData{
+		Ready:  ready,
+		Status: status,
+	})
+}
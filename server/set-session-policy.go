@@ -0,0 +1,48 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+)
+
+func (s *NodeSetMockServer) setSessionPolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	query := r.URL.Query()
+	var policy manager.SessionPolicy
+	var err error
+	policy.NonceLifetime, err = getOptionalDuration(query, "nonce-lifetime")
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	policy.MaxNoncesPerClient, err = getOptionalLimit(query, "max-nonces")
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	revokeString := query.Get("revoke-old-sessions")
+	if revokeString != "" {
+		policy.RevokeOldSessions, err = strconv.ParseBool(revokeString)
+		if err != nil {
+			handleInputError(w, s.logger, fmt.Errorf("invalid revoke-old-sessions value [%s]", revokeString))
+			return
+		}
+	}
+
+	// Set the policy
+	err = s.manager.SetSessionPolicy(policy)
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+	s.logger.Info("Set session policy", "nonceLifetime", policy.NonceLifetime, "maxNoncesPerClient", policy.MaxNoncesPerClient, "revokeOldSessions", policy.RevokeOldSessions)
+	handleSuccess(w, s.logger, getSessionPolicyData(policy))
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (s *NodeSetMockServer) setTime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+
+	// Input validation
+	timeString := r.URL.Query().Get("time")
+	if timeString == "" {
+		handleInputError(w, s.logger, fmt.Errorf("missing time query parameter"))
+		return
+	}
+	now, err := parseTime(timeString)
+	if err != nil {
+		handleInputError(w, s.logger, err)
+		return
+	}
+
+	s.manager.SetTime(now)
+	handleSuccess(w, s.logger, s.getTimeData())
+}
+
+// Parse a time that's either a Unix timestamp in seconds or an RFC 3339 string
+func parseTime(value string) (time.Time, error) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time must be a Unix timestamp or an RFC 3339 string")
+	}
+	return parsed, nil
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/rocket-pool/node-manager-core/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure arbitrary registration signatures never crash the handler or produce a server error
+func FuzzRegisterNode(f *testing.F) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("fuzz")
+	f.Cleanup(func() {
+		err := server.manager.RevertToSnapshot("fuzz")
+		if err != nil {
+			f.Fatalf("error reverting to snapshot: %v", err)
+		}
+	})
+
+	// Provision the database with a node that's whitelisted but not registered
+	database := idb.ProvisionFullDatabase(f, logger, false)
+	require.NoError(f, server.manager.SetDatabase(database))
+	nodeKey, err := test.GetEthPrivateKey(5)
+	require.NoError(f, err)
+	nodeAddress := crypto.PubkeyToAddress(nodeKey.PublicKey)
+	err = server.manager.WhitelistNodeAccount(test.User0Email, nodeAddress)
+	require.NoError(f, err)
+
+	// Seed with valid and malformed signatures
+	signature, err := auth.GetSignatureForRegistration(test.User0Email, nodeAddress, nodeKey)
+	require.NoError(f, err)
+	addSignatureSeeds(f, test.User0Email, signature)
+
+	f.Fuzz(func(t *testing.T, email string, signature string) {
+		body, err := json.Marshal(api.RegisterNodeRequest{
+			Email:       email,
+			NodeAddress: nodeAddress.Hex(),
+			Signature:   signature,
+		})
+		require.NoError(t, err)
+		request := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/%s", api.RegisterPath), bytes.NewReader(body))
+		requireNoServerError(t, request)
+	})
+}
+
+// Make sure arbitrary login signatures never crash the handler or produce a server error
+func FuzzLogin(f *testing.F) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("fuzz")
+	f.Cleanup(func() {
+		err := server.manager.RevertToSnapshot("fuzz")
+		if err != nil {
+			f.Fatalf("error reverting to snapshot: %v", err)
+		}
+	})
+
+	// Provision the database and create a session
+	database := idb.ProvisionFullDatabase(f, logger, false)
+	require.NoError(f, server.manager.SetDatabase(database))
+	nodeKey, err := test.GetEthPrivateKey(0)
+	require.NoError(f, err)
+	nodeAddress := crypto.PubkeyToAddress(nodeKey.PublicKey)
+	session := server.manager.CreateSession()
+
+	// Seed with valid and malformed signatures
+	signature, err := auth.GetSignatureForLogin(session.Nonce, nodeAddress, nodeKey)
+	require.NoError(f, err)
+	addSignatureSeeds(f, session.Nonce, signature)
+
+	f.Fuzz(func(t *testing.T, nonce string, signature string) {
+		body, err := json.Marshal(api.LoginRequest{
+			Nonce:     nonce,
+			Address:   nodeAddress.Hex(),
+			Signature: signature,
+		})
+		require.NoError(t, err)
+		request := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/%s", api.LoginPath), bytes.NewReader(body))
+		auth.AddAuthorizationHeader(request, session)
+		requireNoServerError(t, request)
+	})
+}
+
+// Add seeds for a fuzz test, built from a valid signature for the provided message field
+func addSignatureSeeds(f *testing.F, field string, signature []byte) {
+	encoded := utils.EncodeHexWithPrefix(signature)
+	f.Add(field, encoded)
+	f.Add(field, utils.RemovePrefix(encoded))
+	f.Add(field, encoded[:len(encoded)-2])
+	f.Add(field, encoded+"00")
+	f.Add(field, encoded[:len(encoded)-2]+"00")
+	f.Add(field, encoded[:len(encoded)-2]+"1d")
+	f.Add(field, "0x")
+	f.Add(field, "")
+	f.Add(field, "0xzz")
+	f.Add("", encoded)
+}
+
+// Run a request through the server's handler and make sure it's rejected cleanly if it fails
+func requireNoServerError(t *testing.T, request *http.Request) {
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	require.Contains(t, []int{http.StatusOK, http.StatusBadRequest, http.StatusUnauthorized}, recorder.Code, "body: %s", recorder.Body.String())
+
+	var response api.NodeSetResponse[json.RawMessage]
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err, "body: %s", recorder.Body.String())
+	require.Equal(t, recorder.Code == http.StatusOK, response.OK)
+}
@@ -16,6 +16,10 @@ func (s *NodeSetMockServer) snapshot(w http.ResponseWriter, r *http.Request) {
 		handleInputError(w, s.logger, fmt.Errorf("missing snapshot name"))
 		return
 	}
-	s.manager.TakeSnapshot(snapshotName)
+	err := s.manager.TakeSnapshot(snapshotName)
+	if err != nil {
+		handleError(w, s.logger, err)
+		return
+	}
 	handleSuccess(w, s.logger, "")
 }
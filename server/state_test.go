@@ -0,0 +1,53 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the admin state route dumps the users, nodes, validators, and vaults
+func TestStateDump(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, true)
+	require.NoError(t, server.manager.SetDatabase(database))
+
+	// Get the state
+	var parsedResponse api.NodeSetResponse[api.StateData]
+	runAdminDataRequest(t, api.AdminStatePath, nil, &parsedResponse)
+	data := parsedResponse.Data
+
+	// Make sure it matches the database
+	require.Len(t, data.Users, len(database.Users))
+	validatorCount := 0
+	for i, user := range data.Users {
+		dbUser := database.Users[i]
+		require.Equal(t, dbUser.Email, user.Email)
+		require.Len(t, user.Nodes, len(dbUser.RegisteredNodes)+len(dbUser.WhitelistedNodes))
+		for _, node := range user.Nodes {
+			require.Equal(t, node.Registered, node.RegisteredTime != nil)
+			validatorCount += len(node.Validators)
+		}
+	}
+	require.NotZero(t, validatorCount)
+	require.Len(t, data.Vaults, 1)
+	vault := data.Vaults[0]
+	require.Equal(t, test.Network, vault.Network)
+	require.Equal(t, test.StakeWiseVaultAddress.Hex(), vault.Address)
+	require.Equal(t, 1, vault.Version)
+	require.NotEmpty(t, vault.LatestSet)
+	require.Contains(t, data.Snapshots, "test")
+	t.Logf("State dump matches - %d users, %d validators", len(data.Users), validatorCount)
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+)
+
+const (
+	// The number of events buffered for each stream before they start getting dropped
+	eventStreamBufferSize int = 256
+)
+
+func (s *NodeSetMockServer) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleInvalidMethod(w, s.logger)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		handleServerError(w, s.logger, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	// Get the event types to send, which defaults to all of them
+	types := map[api.EventType]bool{}
+	typesString := r.URL.Query().Get("types")
+	if typesString != "" {
+		for _, eventType := range strings.Split(typesString, ",") {
+			types[api.EventType(eventType)] = true
+		}
+	}
+
+	// Subscribe before responding so the client doesn't miss anything once it sees the stream open
+	subscription := s.manager.SubscribeToEvents(eventStreamBufferSize)
+	defer subscription.Unsubscribe()
+	s.logger.Info("Started event stream", "remote", r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			s.logger.Info("Client closed event stream", "remote", r.RemoteAddr)
+			return
+		case <-s.shutdown:
+			return
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			if len(types) > 0 && !types[event.Type] {
+				continue
+			}
+			bytes, err := json.Marshal(event)
+			if err != nil {
+				s.logger.Error("Error serializing event", "id", event.ID, "error", err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, bytes)
+			flusher.Flush()
+		}
+	}
+}
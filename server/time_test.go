@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/manager"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure the admin time routes control the manager's clock
+func TestTimeTravel(t *testing.T) {
+	defer server.manager.ResetTime()
+
+	// Freeze time
+	data := runTimeRequest(t, api.AdminFreezeTimePath, nil)
+	require.True(t, data.Frozen)
+	frozenTime := data.Time
+	time.Sleep(10 * time.Millisecond)
+	data = runTimeRequest(t, api.AdminTimePath, nil)
+	require.True(t, frozenTime.Equal(data.Time))
+	t.Log("Froze time")
+
+	// Set it to 2 epochs after genesis
+	config := server.manager.GetBeaconConfig()
+	slotDuration := time.Duration(config.SecondsPerSlot) * time.Second
+	target := config.GenesisTime.Add(2 * time.Duration(config.SlotsPerEpoch) * slotDuration)
+	data = runTimeRequest(t, api.AdminSetTimePath, map[string]string{
+		"time": target.Format(time.RFC3339Nano),
+	})
+	require.True(t, target.Equal(data.Time))
+	require.Equal(t, 2*config.SlotsPerEpoch, data.Slot)
+	require.Equal(t, uint64(2), data.Epoch)
+	require.Equal(t, uint64(2), server.manager.GetBeaconEpoch())
+	t.Log("Set time")
+
+	// Advance it by a slot
+	data = runTimeRequest(t, api.AdminAdvanceTimePath, map[string]string{
+		"duration": slotDuration.String(),
+	})
+	require.True(t, target.Add(slotDuration).Equal(data.Time))
+	require.Equal(t, 2*config.SlotsPerEpoch+1, data.Slot)
+	require.Equal(t, http.StatusBadRequest, runAdminRequest(t, port, api.AdminAdvanceTimePath, "", map[string]string{"duration": "-1s"}))
+	t.Log("Advanced time")
+
+	// Unfreeze it
+	data = runTimeRequest(t, api.AdminFreezeTimePath, map[string]string{
+		"frozen": "false",
+	})
+	require.False(t, data.Frozen)
+	require.WithinDuration(t, target.Add(slotDuration), data.Time, time.Second)
+	t.Log("Unfroze time")
+}
+
+// Make sure Beacon Chain slots and epochs are derived from the Beacon Chain config and the manager's clock
+func TestBeaconTiming(t *testing.T) {
+	genesis := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	config := manager.DefaultBeaconConfig(genesis)
+	testCases := []struct {
+		name  string
+		time  time.Time
+		slot  uint64
+		epoch uint64
+	}{
+		{"before genesis", genesis.Add(-time.Hour), 0, 0},
+		{"at genesis", genesis, 0, 0},
+		{"mid slot", genesis.Add(18 * time.Second), 1, 0},
+		{"first slot of epoch 1", genesis.Add(32 * 12 * time.Second), 32, 1},
+		{"last slot of epoch 1", genesis.Add(63 * 12 * time.Second), 63, 1},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.slot, config.GetSlotAt(testCase.time))
+			require.Equal(t, testCase.epoch, config.GetEpochAt(testCase.time))
+		})
+	}
+
+	// Configs without slot timing are rejected
+	invalidConfig := config
+	invalidConfig.SecondsPerSlot = 0
+	require.Error(t, server.manager.SetBeaconConfig(invalidConfig))
+	invalidConfig = config
+	invalidConfig.SlotsPerEpoch = 0
+	require.Error(t, server.manager.SetBeaconConfig(invalidConfig))
+}
+
+// Run a request to one of the admin time routes
+func runTimeRequest(t *testing.T, path string, queryParams map[string]string) api.TimeData {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/%s", port, path), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	query := request.URL.Query()
+	for name, value := range queryParams {
+		query.Add(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Read the body
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading the response body: %v", err)
+	}
+	var parsedResponse api.NodeSetResponse[api.TimeData]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	if err != nil {
+		t.Fatalf("error deserializing response: %v", err)
+	}
+	return parsedResponse.Data
+}
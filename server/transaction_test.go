@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/db"
+	idb "github.com/nodeset-org/nodeset-svc-mock/internal/db"
+	"github.com/nodeset-org/nodeset-svc-mock/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure failed transactions roll back their changes and drop their events, and successful ones keep both
+func TestTransactions(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	userCount := len(server.manager.GetUsers())
+	subscription := server.manager.SubscribeToEvents(64)
+	defer subscription.Unsubscribe()
+
+	// Run a batch that fails partway through
+	errBatch := errors.New("batch failed")
+	err := server.manager.RunTransaction(func() error {
+		err := server.manager.AddUser("rollback@nodeset.io")
+		require.NoError(t, err)
+		_, err = server.manager.CycleDepositDataSet(test.StakeWiseVaultAddress, test.Network, db.DefaultSetSelectionPolicy{}, db.SetSelectionParams{})
+		require.NoError(t, err)
+		return errBatch
+	})
+	require.ErrorIs(t, err, errBatch)
+
+	// None of it should have been kept or announced
+	require.Len(t, server.manager.GetUsers(), userCount)
+	vault := server.manager.GetStakeWiseVault(test.StakeWiseVaultAddress, test.Network)
+	require.Equal(t, 0, vault.LatestDepositDataSetIndex)
+	require.Empty(t, vault.UploadedData)
+	require.Len(t, subscription.Events, 0)
+	t.Log("Failed transaction was rolled back")
+
+	// A failed inner transaction should only roll back its own changes
+	err = server.manager.RunTransaction(func() error {
+		err := server.manager.AddUser("kept@nodeset.io")
+		require.NoError(t, err)
+		err = server.manager.RunTransaction(func() error {
+			err := server.manager.AddUser("dropped@nodeset.io")
+			require.NoError(t, err)
+			return errBatch
+		})
+		require.ErrorIs(t, err, errBatch)
+		return nil
+	})
+	require.NoError(t, err)
+	users := server.manager.GetUsers()
+	require.Len(t, users, userCount+1)
+	require.Equal(t, "kept@nodeset.io", users[userCount].Email)
+	event := waitForEvent(t, subscription.Events, api.EventType_UserAdded)
+	require.Equal(t, api.UserAddedEventData{Email: "kept@nodeset.io"}, event.Data)
+	require.Len(t, subscription.Events, 0)
+	t.Log("Successful transaction kept its changes and published its events")
+
+	// A transaction that panics should be rolled back and let the panic through
+	require.PanicsWithValue(t, "batch panicked", func() {
+		_ = server.manager.RunTransaction(func() error {
+			err := server.manager.AddUser("panicked@nodeset.io")
+			require.NoError(t, err)
+			panic("batch panicked")
+		})
+	})
+	require.Len(t, server.manager.GetUsers(), userCount+1)
+	require.Len(t, subscription.Events, 0)
+
+	// Events shouldn't be held back anymore
+	err = server.manager.AddUser("after-panic@nodeset.io")
+	require.NoError(t, err)
+	event = waitForEvent(t, subscription.Events, api.EventType_UserAdded)
+	require.Equal(t, api.UserAddedEventData{Email: "after-panic@nodeset.io"}, event.Data)
+	t.Log("Panicking transaction was rolled back")
+}
+
+// Make sure admin routes that take several entries at once add all of them or none of them
+func TestAdminBatchTransactions(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	userCount := len(server.manager.GetUsers())
+
+	// A batch with an existing user should add none of them
+	statusCode, response := runAdminBatchRequest(t, api.AdminAddUserPath, url.Values{
+		"email": {"batch_0@nodeset.io", test.User0Email, "batch_1@nodeset.io"},
+	})
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "user_already_exists", response.Error)
+	require.Contains(t, response.Message, "entry 1: user already exists")
+	require.Len(t, server.manager.GetUsers(), userCount)
+	t.Log("Failed user batch was rolled back")
+
+	// A valid batch should add all of them
+	statusCode, _ = runAdminBatchRequest(t, api.AdminAddUserPath, url.Values{
+		"email": {"batch_0@nodeset.io", "batch_1@nodeset.io"},
+	})
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Len(t, server.manager.GetUsers(), userCount+2)
+	t.Log("User batch was added")
+
+	// Same for vaults
+	statusCode, response = runAdminBatchRequest(t, api.AdminAddVaultPath, url.Values{
+		"network": {test.Network},
+		"address": {"0x01", test.StakeWiseVaultAddressHex},
+	})
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "vault_already_exists", response.Error)
+	require.Nil(t, server.manager.GetStakeWiseVault(common.HexToAddress("0x01"), test.Network))
+	statusCode, _ = runAdminBatchRequest(t, api.AdminAddVaultPath, url.Values{
+		"network": {test.Network},
+		"address": {"0x01", "0x02"},
+	})
+	require.Equal(t, http.StatusOK, statusCode)
+	require.NotNil(t, server.manager.GetStakeWiseVault(common.HexToAddress("0x01"), test.Network))
+	require.NotNil(t, server.manager.GetStakeWiseVault(common.HexToAddress("0x02"), test.Network))
+	t.Log("Vault batches were handled")
+
+	// And for whitelisted nodes
+	statusCode, response = runAdminBatchRequest(t, api.AdminWhitelistNodePath, url.Values{
+		"email":   {"missing@nodeset.io"},
+		"address": {"0x03", "0x04"},
+	})
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "user_not_found", response.Error)
+	statusCode, _ = runAdminBatchRequest(t, api.AdminWhitelistNodePath, url.Values{
+		"email":   {"batch_0@nodeset.io"},
+		"address": {"0x03", "0x04"},
+	})
+	require.Equal(t, http.StatusOK, statusCode)
+	users := server.manager.GetUsers()
+	require.Len(t, users[userCount].WhitelistedNodes, 2)
+	t.Log("Node batches were handled")
+}
+
+// Run an admin request with query args that can be repeated, returning the status code and parsed response
+func runAdminBatchRequest(t *testing.T, path string, query url.Values) (int, api.NodeSetResponse[struct{}]) {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/%s", port, path), nil)
+	require.NoError(t, err)
+	request.URL.RawQuery = query.Encode()
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	// Read the body
+	bytes, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	var parsedResponse api.NodeSetResponse[struct{}]
+	err = json.Unmarshal(bytes, &parsedResponse)
+	require.NoError(t, err)
+	return response.StatusCode, parsedResponse
+}
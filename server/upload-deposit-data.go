@@ -9,7 +9,9 @@ import (
 func (s *NodeSetMockServer) uploadDepositData(w http.ResponseWriter, r *http.Request) {
 	// Get the requesting node
 	var depositData []beacon.ExtendedDepositData
-	_ = s.processApiRequest(w, r, &depositData)
+	if s.processApiRequest(w, r, &depositData) == nil {
+		return
+	}
 	session := s.processAuthHeader(w, r)
 	if session == nil {
 		return
@@ -22,7 +24,7 @@ func (s *NodeSetMockServer) uploadDepositData(w http.ResponseWriter, r *http.Req
 	// Handle the upload
 	err := s.manager.HandleDepositDataUpload(node.Address, depositData)
 	if err != nil {
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return
 	}
 	handleSuccess(w, s.logger, struct{}{})
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/nodeset-org/nodeset-svc-mock/api"
 	"github.com/nodeset-org/nodeset-svc-mock/auth"
 	"github.com/nodeset-org/nodeset-svc-mock/db"
@@ -29,7 +30,7 @@ func TestUploadDepositData(t *testing.T) {
 
 	// Provision the database
 	db := idb.ProvisionFullDatabase(t, logger, false)
-	server.manager.SetDatabase(db)
+	require.NoError(t, server.manager.SetDatabase(db))
 	session := db.Sessions[0]
 
 	// Run a get deposit data request to make sure it's empty
@@ -71,6 +72,85 @@ func TestUploadDepositData(t *testing.T) {
 	t.Logf("Received matching response")
 }
 
+// Make sure deposit data uploads are all-or-nothing, and every invalid deposit data is reported
+func TestUploadDepositDataAtomic(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	session := database.Sessions[0]
+	validatorsBefore := runGetValidatorsRequest(t, session).Data.Validators
+
+	// Upload a batch with valid deposit data around ones for an unknown network and vault
+	unknownNetworkData := idb.GenerateDepositData(t, 6, test.StakeWiseVaultAddress)
+	unknownNetworkData.NetworkName = "unknown"
+	depositData := []beacon.ExtendedDepositData{
+		idb.GenerateDepositData(t, 5, test.StakeWiseVaultAddress),
+		unknownNetworkData,
+		idb.GenerateDepositData(t, 7, common.HexToAddress("0x01")),
+		idb.GenerateDepositData(t, 8, test.StakeWiseVaultAddress),
+	}
+	body, err := json.Marshal(depositData)
+	require.NoError(t, err)
+	statusCode, response := runErrorRequest(t, http.MethodPost, api.DepositDataPath, session, map[string]string{}, body)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "invalid_network", response.Error)
+	t.Logf("Upload was rejected: %s", response.Message)
+
+	// Both invalid entries should be reported, and none of the valid ones should have been added
+	require.Contains(t, response.Message, "entry 1: unknown network")
+	require.Contains(t, response.Message, "entry 2: StakeWise vault not found")
+	require.NotContains(t, response.Message, "entry 0")
+	require.NotContains(t, response.Message, "entry 3")
+	require.Equal(t, validatorsBefore, runGetValidatorsRequest(t, session).Data.Validators)
+}
+
+// Make sure deposit data with fields of the wrong length is rejected instead of being stored
+func TestUploadDepositDataMalformed(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	session := database.Sessions[0]
+	validatorsBefore := runGetValidatorsRequest(t, session).Data.Validators
+
+	// Upload a batch with a short pubkey, signature, and withdrawal credentials
+	shortPubkey := idb.GenerateDepositData(t, 5, test.StakeWiseVaultAddress)
+	shortPubkey.PublicKey = shortPubkey.PublicKey[:47]
+	shortSignature := idb.GenerateDepositData(t, 6, test.StakeWiseVaultAddress)
+	shortSignature.Signature = shortSignature.Signature[:95]
+	shortCredentials := idb.GenerateDepositData(t, 7, test.StakeWiseVaultAddress)
+	shortCredentials.WithdrawalCredentials = shortCredentials.WithdrawalCredentials[:20]
+	body, err := json.Marshal([]beacon.ExtendedDepositData{shortPubkey, shortSignature, shortCredentials})
+	require.NoError(t, err)
+	statusCode, response := runErrorRequest(t, http.MethodPost, api.DepositDataPath, session, map[string]string{}, body)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "invalid_pubkey", response.Error)
+	t.Logf("Upload was rejected: %s", response.Message)
+
+	// Each malformed entry should be reported, and nothing should have been added
+	require.Contains(t, response.Message, "entry 0: invalid validator pubkey: pubkey must be 48 bytes but was 47")
+	require.Contains(t, response.Message, "entry 1: invalid deposit data: signature must be 96 bytes but was 95")
+	require.Contains(t, response.Message, "entry 2: invalid deposit data: withdrawal credentials must be 32 bytes but were 20")
+	require.Equal(t, validatorsBefore, runGetValidatorsRequest(t, session).Data.Validators)
+}
+
 func runUploadDepositDataRequest(t *testing.T, session *db.Session, depositData []beacon.ExtendedDepositData) {
 	// Marshal the deposit data
 	body, err := json.Marshal(depositData)
@@ -100,3 +180,40 @@ func runUploadDepositDataRequest(t *testing.T, session *db.Session, depositData
 	require.Equal(t, http.StatusOK, response.StatusCode)
 	t.Logf("Received an OK status code")
 }
+
+// Make sure a body that only partly deserializes is rejected without storing any of it
+func TestUploadDepositDataMalformedBody(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	session := database.Sessions[0]
+	validatorsBefore := runGetValidatorsRequest(t, session).Data.Validators
+
+	// Upload two deposit data entries where one has an amount of the wrong type
+	body, err := json.Marshal([]beacon.ExtendedDepositData{
+		idb.GenerateDepositData(t, 5, test.StakeWiseVaultAddress),
+		idb.GenerateDepositData(t, 6, test.StakeWiseVaultAddress),
+	})
+	require.NoError(t, err)
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal(body, &entries))
+	entries[1]["amount"] = "oops"
+	body, err = json.Marshal(entries)
+	require.NoError(t, err)
+	statusCode, response := runErrorRequest(t, http.MethodPost, api.DepositDataPath, session, map[string]string{}, body)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Contains(t, response.Message, "error deserializing request body")
+	t.Logf("Upload was rejected: %s", response.Message)
+
+	// Nothing should have been stored
+	require.Equal(t, validatorsBefore, runGetValidatorsRequest(t, session).Data.Validators)
+}
@@ -1,15 +1,26 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/nodeset-org/nodeset-svc-mock/api"
 )
 
+// A signed exit in an upload, which can be in either the encrypted format or the plaintext one
+type signedExitUpload struct {
+	Pubkey               string           `json:"pubkey"`
+	EncryptedExitMessage *string          `json:"exitMessage"`
+	ExitMessage          *api.ExitMessage `json:"exit_message"`
+}
+
 func (s *NodeSetMockServer) uploadSignedExits(w http.ResponseWriter, r *http.Request) {
 	// Get the requesting node
-	var exitData []api.ExitData
-	args := s.processApiRequest(w, r, &exitData)
+	var uploads []signedExitUpload
+	args := s.processApiRequest(w, r, &uploads)
+	if args == nil {
+		return
+	}
 	session := s.processAuthHeader(w, r)
 	if session == nil {
 		return
@@ -19,11 +30,43 @@ func (s *NodeSetMockServer) uploadSignedExits(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Uploads have to be entirely in one format
+	encryptedCount := 0
+	for _, upload := range uploads {
+		if upload.EncryptedExitMessage != nil {
+			encryptedCount++
+		}
+	}
+	if encryptedCount > 0 && encryptedCount < len(uploads) {
+		handleInputError(w, s.logger, fmt.Errorf("exits must either all be encrypted or all be plaintext, but %d of %d were encrypted", encryptedCount, len(uploads)))
+		return
+	}
+	plaintext := len(uploads) > 0 && encryptedCount == 0
+
 	// Handle the upload
-	network := args.Get("network")
-	err := s.manager.HandleSignedExitUpload(node.Address, network, exitData)
+	network, _ := getNetworkAndVault(r, args)
+	var err error
+	if plaintext {
+		exitData := make([]api.ExitData, len(uploads))
+		for i, upload := range uploads {
+			exitData[i].Pubkey = upload.Pubkey
+			if upload.ExitMessage != nil {
+				exitData[i].ExitMessage = *upload.ExitMessage
+			}
+		}
+		err = s.manager.HandleSignedExitUpload(node.Address, network, exitData)
+	} else {
+		encryptedExitData := make([]api.EncryptedExitData, len(uploads))
+		for i, upload := range uploads {
+			encryptedExitData[i] = api.EncryptedExitData{
+				Pubkey:      upload.Pubkey,
+				ExitMessage: *upload.EncryptedExitMessage,
+			}
+		}
+		err = s.manager.HandleEncryptedSignedExitUpload(node.Address, network, encryptedExitData)
+	}
 	if err != nil {
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return
 	}
 	handleSuccess(w, s.logger, struct{}{})
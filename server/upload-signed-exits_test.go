@@ -29,7 +29,7 @@ func TestUploadSignedExits(t *testing.T) {
 
 	// Provision the database
 	db := idb.ProvisionFullDatabase(t, logger, false)
-	server.manager.SetDatabase(db)
+	require.NoError(t, server.manager.SetDatabase(db))
 	session := db.Sessions[0]
 
 	// Run a get deposit data request to make sure it's empty
@@ -64,12 +64,21 @@ func TestUploadSignedExits(t *testing.T) {
 	require.Equal(t, expectedData, validatorsResponse.Data.Validators)
 	t.Logf("Received matching response")
 
-	// Generate a signed exit for validator 1
+	// Generate a signed exit for validator 1 and encrypt it to the mock's public key
+	publicKey := runGetEncryptionIdentityRequest(t).Data.PublicKey
+	require.Equal(t, server.manager.GetExitEncryptionPublicKey(), publicKey)
 	signedExit1 := idb.GenerateSignedExit(t, 1)
-	t.Log("Generated signed exit")
+	encryptedExit, err := auth.EncryptExitMessage(signedExit1.ExitMessage, publicKey)
+	require.NoError(t, err)
+	t.Log("Generated encrypted signed exit")
 
 	// Upload it
-	runUploadSignedExitsRequest(t, session, []api.ExitData{signedExit1})
+	runUploadSignedExitsRequest(t, session, []api.EncryptedExitData{
+		{
+			Pubkey:      signedExit1.Pubkey,
+			ExitMessage: encryptedExit,
+		},
+	})
 	t.Logf("Uploaded signed exit")
 
 	// Get the validator status again
@@ -88,9 +97,138 @@ func TestUploadSignedExits(t *testing.T) {
 	}
 	require.Equal(t, expectedData, validatorsResponse.Data.Validators)
 	t.Logf("Received matching response")
+
+	// Make sure the decrypted exit was stored on the node's validator
+	node, _ := server.manager.GetNode(session.NodeAddress)
+	validators := node.Validators[test.Network]
+	require.Equal(t, beacon.ValidatorPubkey(depositData[1].PublicKey), validators[1].Pubkey)
+	require.Equal(t, signedExit1.ExitMessage, validators[1].SignedExit)
+}
+
+// Make sure plaintext signed exits are only accepted when the compatibility setting is enabled
+func TestUploadPlaintextSignedExits(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		server.manager.SetPlaintextExitsAllowed(false)
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database
+	db := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(db))
+	session := db.Sessions[0]
+	body := []api.ExitData{idb.GenerateSignedExit(t, 0)}
+	bodyBytes, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	// They're rejected by default
+	query := map[string]string{"network": test.Network}
+	statusCode, response := runErrorRequest(t, http.MethodPatch, api.ValidatorsPath, session, query, bodyBytes)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "unencrypted_exit_message", response.Error)
+	t.Log("Plaintext exit was rejected")
+
+	// Once they're allowed, malformed ones are still rejected
+	server.manager.SetPlaintextExitsAllowed(true)
+	malformedExit := idb.GenerateSignedExit(t, 0)
+	malformedExit.ExitMessage.Signature = "0x1234"
+	malformedBytes, err := json.Marshal([]api.ExitData{malformedExit})
+	require.NoError(t, err)
+	statusCode, response = runErrorRequest(t, http.MethodPatch, api.ValidatorsPath, session, query, malformedBytes)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "invalid_exit_message", response.Error)
+	t.Log("Malformed plaintext exit was rejected")
+
+	// So are uploads that mix the encrypted and plaintext formats
+	mixedBytes, err := json.Marshal([]any{body[0], encryptSignedExit(t, body[0])})
+	require.NoError(t, err)
+	statusCode, response = runErrorRequest(t, http.MethodPatch, api.ValidatorsPath, session, query, mixedBytes)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Contains(t, response.Message, "all be encrypted or all be plaintext")
+	t.Log("Mixed upload was rejected")
+
+	// Valid ones are accepted
+	runUploadSignedExitsRequest(t, session, body)
+	validatorsResponse := runGetValidatorsRequest(t, session)
+	require.True(t, validatorsResponse.Data.Validators[0].ExitMessageUploaded)
+	t.Log("Plaintext exit was accepted")
+}
+
+// Make sure signed exit uploads are all-or-nothing, and every invalid exit is reported
+func TestUploadSignedExitsAtomic(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database and upload deposit data for the node's validator
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	session := database.Sessions[0]
+	runUploadDepositDataRequest(t, session, []beacon.ExtendedDepositData{
+		idb.GenerateDepositData(t, 0, test.StakeWiseVaultAddress),
+	})
+
+	// Upload a valid exit along with one for another node's validator, one that can't be decrypted, and one with a
+	// malformed pubkey
+	ownExit := idb.GenerateSignedExit(t, 0)
+	otherExit := idb.GenerateSignedExit(t, 1)
+	body, err := json.Marshal([]api.EncryptedExitData{
+		encryptSignedExit(t, ownExit),
+		encryptSignedExit(t, otherExit),
+		{Pubkey: ownExit.Pubkey, ExitMessage: "0x1234"},
+		{Pubkey: "0x1234", ExitMessage: "0x1234"},
+	})
+	require.NoError(t, err)
+	query := map[string]string{"network": test.Network}
+	statusCode, response := runErrorRequest(t, http.MethodPatch, api.ValidatorsPath, session, query, body)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Equal(t, "invalid_pubkey", response.Error)
+	t.Logf("Upload was rejected: %s", response.Message)
+
+	// Every invalid exit should be reported, and the valid one shouldn't have been stored
+	require.Contains(t, response.Message, "entry 1: node doesn't have the validator")
+	require.Contains(t, response.Message, "entry 2: error with exit for")
+	require.Contains(t, response.Message, "entry 3: invalid validator pubkey")
+	require.NotContains(t, response.Message, "entry 0")
+	validatorsResponse := runGetValidatorsRequest(t, session)
+	require.Equal(t, beacon.ValidatorPubkey(idb.GenerateDepositData(t, 0, test.StakeWiseVaultAddress).PublicKey), validatorsResponse.Data.Validators[0].Pubkey)
+	require.False(t, validatorsResponse.Data.Validators[0].ExitMessageUploaded)
+}
+
+// Encrypt a signed exit to the mock's public key
+func encryptSignedExit(t *testing.T, signedExit api.ExitData) api.EncryptedExitData {
+	encryptedExit, err := auth.EncryptExitMessage(signedExit.ExitMessage, server.manager.GetExitEncryptionPublicKey())
+	require.NoError(t, err)
+	return api.EncryptedExitData{
+		Pubkey:      signedExit.Pubkey,
+		ExitMessage: encryptedExit,
+	}
+}
+
+func runGetEncryptionIdentityRequest(t *testing.T) api.NodeSetResponse[api.EncryptionIdentityData] {
+	// Send the request
+	response, err := http.Get(fmt.Sprintf("http://localhost:%d/api/%s", port, api.EncryptionIdentityPath))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Read the body
+	var parsedResponse api.NodeSetResponse[api.EncryptionIdentityData]
+	err = json.NewDecoder(response.Body).Decode(&parsedResponse)
+	require.NoError(t, err)
+	return parsedResponse
 }
 
-func runUploadSignedExitsRequest(t *testing.T, session *db.Session, signedExits []api.ExitData) {
+func runUploadSignedExitsRequest(t *testing.T, session *db.Session, signedExits any) {
 	// Marshal the deposit data
 	body, err := json.Marshal(signedExits)
 	if err != nil {
@@ -122,3 +260,39 @@ func runUploadSignedExitsRequest(t *testing.T, session *db.Session, signedExits
 	require.Equal(t, http.StatusOK, response.StatusCode)
 	t.Logf("Received an OK status code")
 }
+
+// Make sure a body that only partly deserializes is rejected without storing any of it
+func TestUploadSignedExitsMalformedBody(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Provision the database and upload deposit data for the node's validator
+	database := idb.ProvisionFullDatabase(t, logger, false)
+	require.NoError(t, server.manager.SetDatabase(database))
+	session := database.Sessions[0]
+	runUploadDepositDataRequest(t, session, []beacon.ExtendedDepositData{
+		idb.GenerateDepositData(t, 0, test.StakeWiseVaultAddress),
+	})
+
+	// Upload a valid exit followed by one with a pubkey of the wrong type
+	body, err := json.Marshal([]any{
+		encryptSignedExit(t, idb.GenerateSignedExit(t, 0)),
+		map[string]any{"pubkey": 1234, "exitMessage": "0x1234"},
+	})
+	require.NoError(t, err)
+	query := map[string]string{"network": test.Network}
+	statusCode, response := runErrorRequest(t, http.MethodPatch, api.ValidatorsPath, session, query, body)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.Contains(t, response.Message, "error deserializing request body")
+	t.Logf("Upload was rejected: %s", response.Message)
+
+	// The valid exit shouldn't have been stored
+	validatorsResponse := runGetValidatorsRequest(t, session)
+	require.False(t, validatorsResponse.Data.Validators[0].ExitMessageUploaded)
+}
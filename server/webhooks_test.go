@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/nodeset-org/nodeset-svc-mock/auth"
+	"github.com/stretchr/testify/require"
+)
+
+// A webhook delivery received by the test receiver
+type receivedDelivery struct {
+	eventType string
+	eventId   string
+	body      []byte
+	signature string
+}
+
+// Make sure events are signed, retried, and logged when they're delivered to a webhook
+func TestWebhooks(t *testing.T) {
+	// Take a snapshot
+	server.manager.TakeSnapshot("test")
+	defer func() {
+		err := server.manager.RevertToSnapshot("test")
+		if err != nil {
+			t.Fatalf("error reverting to snapshot: %v", err)
+		}
+	}()
+
+	// Start a receiver that fails the first request
+	secret := "webhook-secret"
+	received := make(chan receivedDelivery, 10)
+	requests := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedDelivery{
+			eventType: r.Header.Get(auth.WebhookEventHeader),
+			eventId:   r.Header.Get(auth.WebhookDeliveryHeader),
+			body:      body,
+			signature: r.Header.Get(auth.WebhookSignatureHeader),
+		}
+	}))
+	defer receiver.Close()
+
+	// Add the webhook
+	webhook := runAddWebhookRequest(t, secret, map[string]string{
+		"url":             receiver.URL,
+		"types":           string(api.EventType_UserAdded),
+		"initial-backoff": "10ms",
+	})
+	require.True(t, webhook.Signed)
+	require.Equal(t, []api.EventType{api.EventType_UserAdded}, webhook.Types)
+	defer func() {
+		require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminRemoveWebhookPath, "", map[string]string{"id": strconv.FormatUint(webhook.ID, 10)}))
+	}()
+	require.Equal(t, http.StatusBadRequest, runAdminRequest(t, port, api.AdminAddWebhookPath, "", map[string]string{"url": "not a url"}))
+	require.Equal(t, http.StatusBadRequest, runAdminRequest(t, port, api.AdminAddWebhookPath, "", map[string]string{"url": receiver.URL, "secret": secret}))
+	t.Logf("Added webhook %d", webhook.ID)
+
+	// Add a user, which should be delivered on the second attempt
+	require.Equal(t, http.StatusOK, runAdminRequest(t, port, api.AdminAddUserPath, "", map[string]string{"email": "webhook@nodeset.io"}))
+	var delivery receivedDelivery
+	select {
+	case delivery = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for webhook delivery")
+	}
+	require.Equal(t, string(api.EventType_UserAdded), delivery.eventType)
+	require.NoError(t, auth.VerifyWebhookSignature(delivery.body, secret, delivery.signature))
+	require.ErrorIs(t, auth.VerifyWebhookSignature(delivery.body, "wrong-secret", delivery.signature), auth.ErrInvalidWebhookSignature)
+	var event struct {
+		ID   uint64                 `json:"id"`
+		Data api.UserAddedEventData `json:"data"`
+	}
+	err := json.Unmarshal(delivery.body, &event)
+	require.NoError(t, err)
+	require.Equal(t, delivery.eventId, strconv.FormatUint(event.ID, 10))
+	require.Equal(t, "webhook@nodeset.io", event.Data.Email)
+	t.Log("Received signed delivery")
+
+	// Make sure the delivery was logged
+	var data api.WebhooksData
+	require.Eventually(t, func() bool {
+		data = runGetWebhooksRequest(t, map[string]string{"id": strconv.FormatUint(webhook.ID, 10)})
+		return len(data.Deliveries) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, data.Webhooks, 1)
+	logged := data.Deliveries[0]
+	require.True(t, logged.Success)
+	require.Equal(t, 2, logged.Attempts)
+	require.Equal(t, http.StatusOK, logged.StatusCode)
+	require.Equal(t, event.ID, logged.EventID)
+	t.Logf("Delivery was logged after %d attempts", logged.Attempts)
+}
+
+// Run an add-webhook request and return the added webhook
+func runAddWebhookRequest(t *testing.T, secret string, queryParams map[string]string) api.Webhook {
+	// Create the request
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/%s", port, api.AdminAddWebhookPath), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	query := request.URL.Query()
+	for name, value := range queryParams {
+		query.Add(name, value)
+	}
+	request.URL.RawQuery = query.Encode()
+	request.Header.Set(auth.WebhookSecretHeader, secret)
+
+	// Send the request
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("error sending request: %v", err)
+	}
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Read the body
+	var parsedResponse api.NodeSetResponse[api.Webhook]
+	bytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("error reading the response body: %v", err)
+	}
+	err = json.Unmarshal(bytes, &parsedResponse)
+	if err != nil {
+		t.Fatalf("error deserializing response: %v", err)
+	}
+	return parsedResponse.Data
+}
+
+// Run a webhooks request and return the webhooks and deliveries
+func runGetWebhooksRequest(t *testing.T, queryParams map[string]string) api.WebhooksData {
+	var parsedResponse api.NodeSetResponse[api.WebhooksData]
+	runAdminDataRequest(t, api.AdminWebhooksPath, queryParams, &parsedResponse)
+	return parsedResponse.Data
+}
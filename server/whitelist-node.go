@@ -3,6 +3,7 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -20,19 +21,36 @@ func (s *NodeSetMockServer) whitelistNode(w http.ResponseWriter, r *http.Request
 		handleInputError(w, s.logger, fmt.Errorf("missing email query parameter"))
 		return
 	}
-	addressString := query.Get("address")
-	if addressString == "" {
-		handleInputError(w, s.logger, fmt.Errorf("missing address query parameter"))
+	addresses, err := getAddresses(query, "address")
+	if err != nil {
+		handleInputError(w, s.logger, err)
 		return
 	}
-	address := common.HexToAddress(addressString)
 
-	// Whitelist the node
-	err := s.manager.WhitelistNodeAccount(email, address)
+	// Whitelist the nodes
+	err = s.manager.WhitelistNodeAccounts(email, addresses)
 	if err != nil {
-		handleServerError(w, s.logger, err)
+		handleError(w, s.logger, err)
 		return
 	}
-	s.logger.Info("Whitelisted new node account", "email", email, "address", address.Hex())
+	for _, address := range addresses {
+		s.logger.Info("Whitelisted new node account", "email", email, "address", address.Hex())
+	}
 	handleSuccess(w, s.logger, "")
 }
+
+// Parses the addresses from a query arg that can be repeated to pass several at once
+func getAddresses(query url.Values, name string) ([]common.Address, error) {
+	addressStrings := query[name]
+	if len(addressStrings) == 0 {
+		return nil, fmt.Errorf("missing %s query parameter", name)
+	}
+	addresses := make([]common.Address, len(addressStrings))
+	for i, addressString := range addressStrings {
+		if addressString == "" {
+			return nil, fmt.Errorf("missing %s query parameter", name)
+		}
+		addresses[i] = common.HexToAddress(addressString)
+	}
+	return addresses, nil
+}
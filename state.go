@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nodeset-org/nodeset-svc-mock/api"
+	"github.com/urfave/cli/v2"
+)
+
+// Creates the state command, which inspects a running mock's state
+func createStateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "state",
+		Usage: "Inspect the state of a running mock",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "dump",
+				Usage: "Print the users, nodes, validators, vaults, and snapshots as JSON",
+				Flags: clientFlags,
+				Action: func(c *cli.Context) error {
+					var data api.StateData
+					err := newAdminClient(c).request(api.AdminStatePath, nil, &data)
+					if err != nil {
+						return fmt.Errorf("error getting state: %w", err)
+					}
+					return printJson(data)
+				},
+			},
+		},
+	}
+}
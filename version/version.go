@@ -0,0 +1,31 @@
+package version
+
+import (
+	"runtime/debug"
+)
+
+const (
+	// The version of the mock
+	Version string = "0.1.0"
+)
+
+// The commit the mock was built from. Set this at build time with
+// -ldflags "-X github.com/nodeset-org/nodeset-svc-mock/version.Commit=<commit>", otherwise it comes from the build's
+// VCS info if there is any.
+var Commit string = ""
+
+// Gets the commit the mock was built from, or "unknown" if it isn't known
+func GetCommit() string {
+	if Commit != "" {
+		return Commit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}